@@ -42,8 +42,8 @@ func (n *nowProvider) GraphQLTypeInfo() schema.GraphQLTypeInfo {
 		Name:        "nowProvider",
 		Description: "example root field provider",
 		Fields: map[string]*schema.GraphQLFieldSpec{
-			"now":    {"now", "Provides the current server time", n.now, []graphql.Argument{}, true},
-			"uptime": {"uptime", "Provides the current server uptime", n.uptime, []graphql.Argument{}, true},
+			"now":    {Name: "now", Description: "Provides the current server time", Func: n.now, Arguments: []graphql.Argument{}, IsRoot: true},
+			"uptime": {Name: "uptime", Description: "Provides the current server uptime", Func: n.uptime, Arguments: []graphql.Argument{}, IsRoot: true},
 		},
 	}
 }
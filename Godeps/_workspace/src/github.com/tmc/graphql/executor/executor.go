@@ -5,32 +5,77 @@ import (
 	"log"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/tracer"
 	"github.com/tmc/graphql/schema"
 	"golang.org/x/net/context"
 )
 
 type Executor struct {
+	mu     sync.RWMutex
 	schema *schema.Schema
 }
 
+// goroutine counters shared by all executors, see GoroutineStats.
+var activeGoroutines, startedGoroutines int64
+
+// GoroutineStats reports the number of field resolution goroutines currently running and
+// the number started since the process began.
+func GoroutineStats() (active, started int64) {
+	return atomic.LoadInt64(&activeGoroutines), atomic.LoadInt64(&startedGoroutines)
+}
+
+// trackGoroutine records the start of a resolution goroutine. The returned function must
+// be called when it finishes.
+func trackGoroutine() func() {
+	atomic.AddInt64(&startedGoroutines, 1)
+	atomic.AddInt64(&activeGoroutines, 1)
+	return func() { atomic.AddInt64(&activeGoroutines, -1) }
+}
+
 func New(schema *schema.Schema) *Executor {
 	return &Executor{
 		schema: schema,
 	}
 }
 
+// SetSchema replaces the schema operations are executed against. Operations in progress
+// complete against the schema they started with.
+func (e *Executor) SetSchema(s *schema.Schema) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.schema = s
+}
+
+// rootFields returns the root fields of the current schema.
+func (e *Executor) rootFields() map[string]*schema.GraphQLFieldSpec {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.schema.RootFields()
+}
+
 func (e *Executor) HandleOperation(ctx context.Context, o *graphql.Operation) (interface{}, error) {
+	if o.Type == graphql.OperationSubscription {
+		return nil, fmt.Errorf("Subscription operations must be executed with Subscribe")
+	}
 	rootSelections := o.SelectionSet
-	rootFields := e.schema.RootFields()
+	rootFields := e.rootFields()
 	result := make([]interface{}, 0)
 
+	t, traced := tracer.FromContext(ctx)
 	for _, selection := range rootSelections {
 		rootFieldHandler, ok := rootFields[selection.Field.Name]
 		if !ok {
 			return nil, fmt.Errorf("Root field '%s' is not registered", selection.Field.Name)
 		}
+		// root fields resolving to scalars, such as counts, query too
+		if traced {
+			if err := t.CheckQueries(); err != nil {
+				return nil, err
+			}
+		}
 		partial, err := rootFieldHandler.Func(ctx, e, selection.Field)
 		if err != nil {
 			return nil, err
@@ -44,6 +89,68 @@ func (e *Executor) HandleOperation(ctx context.Context, o *graphql.Operation) (i
 	return result, nil
 }
 
+// SubscriptionResult is a single result delivered by a subscription.
+type SubscriptionResult struct {
+	Data interface{}
+	Err  error
+}
+
+// Subscribe executes a subscription operation. The root field handler of a subscription
+// must return a receive-only channel (<-chan interface{}) of partial values. Every value
+// received is resolved against the root field's selection set and delivered on the
+// returned channel. A received error is delivered as the Err of a result.
+//
+// The returned channel is closed when the source channel is closed or ctx is done.
+func (e *Executor) Subscribe(ctx context.Context, o *graphql.Operation) (<-chan SubscriptionResult, error) {
+	if o.Type != graphql.OperationSubscription {
+		return nil, fmt.Errorf("Operation of type '%s' is not a subscription", o.Type)
+	}
+	if len(o.SelectionSet) != 1 || o.SelectionSet[0].Field == nil {
+		return nil, fmt.Errorf("Subscriptions must select exactly one root field")
+	}
+	field := o.SelectionSet[0].Field
+	rootFieldHandler, ok := e.rootFields()[field.Name]
+	if !ok {
+		return nil, fmt.Errorf("Root field '%s' is not registered", field.Name)
+	}
+	partial, err := rootFieldHandler.Func(ctx, e, field)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := partial.(<-chan interface{})
+	if !ok {
+		return nil, fmt.Errorf("Root field '%s' does not support subscriptions", field.Name)
+	}
+
+	results := make(chan SubscriptionResult)
+	go func() {
+		defer close(results)
+		for {
+			var result SubscriptionResult
+			select {
+			case <-ctx.Done():
+				return
+			case partial, ok := <-source:
+				if !ok {
+					return
+				}
+				if err, isErr := partial.(error); isErr {
+					result.Err = err
+				} else {
+					resolved, err := e.Resolve(ctx, partial, field)
+					result = SubscriptionResult{Data: []interface{}{resolved}, Err: err}
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case results <- result:
+			}
+		}
+	}()
+	return results, nil
+}
+
 func isSlice(value interface{}) bool {
 	if value == nil {
 		return false
@@ -72,6 +179,13 @@ func (e *Executor) Resolve(ctx context.Context, partial interface{}, field *grap
 		return nil, fmt.Errorf("Cannot return a '%T' as a leaf", graphQLValue)
 	}
 
+	// stop fanning out once the operation has made more backend queries than allowed
+	if t, ok := tracer.FromContext(ctx); ok {
+		if err := t.CheckQueries(); err != nil {
+			return nil, err
+		}
+	}
+
 	result := map[string]interface{}{}
 	typeInfo := schema.WithIntrospectionField(graphQLValue.GraphQLTypeInfo())
 	results := make(chan fieldResult)
@@ -85,6 +199,7 @@ func (e *Executor) Resolve(ctx context.Context, partial interface{}, field *grap
 		}
 		wg.Add(1)
 		go func(selection graphql.Selection) {
+			defer trackGoroutine()()
 			defer wg.Done()
 			partial, err := fieldHandler.Func(ctx, e, selection.Field)
 			if err != nil {
@@ -108,37 +223,42 @@ func (e *Executor) Resolve(ctx context.Context, partial interface{}, field *grap
 		wg.Wait()
 		close(results)
 	}()
+	// drain every result so that no goroutine is left blocked on an error
+	var err error
 	for r := range results {
 		if r.Err != nil {
-			return nil, r.Err
+			if err == nil {
+				err = r.Err
+			}
+			continue
 		}
 		result[r.FieldName] = r.Value
 	}
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 func (e *Executor) resolveSlice(ctx context.Context, partials interface{}, field *graphql.Field) (interface{}, error) {
 	v := reflect.ValueOf(partials)
-	results := make([]interface{}, 0, v.Len())
-	resChan := make(chan fieldResult)
+	// each element is resolved into its own slot, keeping the order of partials
+	results := make([]interface{}, v.Len())
+	errs := make([]error, v.Len())
 	wg := sync.WaitGroup{}
 	for i := 0; i < v.Len(); i++ {
 		wg.Add(1)
 		go func(i int) {
+			defer trackGoroutine()()
 			defer wg.Done()
-			result, err := e.Resolve(ctx, v.Index(i).Interface(), field)
-			resChan <- fieldResult{Value: result, Err: err}
+			results[i], errs[i] = e.Resolve(ctx, v.Index(i).Interface(), field)
 		}(i)
 	}
-	go func() {
-		wg.Wait()
-		close(resChan)
-	}()
-	for result := range resChan {
-		if result.Err != nil {
-			return nil, result.Err
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
-		results = append(results, result.Value)
 	}
 	return results, nil
 }
@@ -2,6 +2,7 @@
 package tracer
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -18,10 +19,16 @@ type Tracer struct {
 	DurationMillis     int64
 	Extra              map[string]interface{} `json:",omitempty"`
 
-	mu      sync.Mutex
-	Queries int
+	mu         sync.Mutex
+	Queries    int
+	Retries    int `json:",omitempty"`
+	queryLimit int
 }
 
+// ErrQueryLimit is returned by CheckQueries once a tracer has counted more queries than
+// its limit.
+var ErrQueryLimit = errors.New("backend query limit exceeded")
+
 func New(id uint64) *Tracer {
 	return &Tracer{
 		ID:        id,
@@ -48,6 +55,32 @@ func (t *Tracer) IncQueries(n int) int {
 	return t.Queries
 }
 
+// SetQueryLimit sets the number of queries past which CheckQueries fails; zero removes the
+// limit.
+func (t *Tracer) SetQueryLimit(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queryLimit = n
+}
+
+// CheckQueries returns ErrQueryLimit if the queries counted exceed the limit of t.
+func (t *Tracer) CheckQueries() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.queryLimit > 0 && t.Queries > t.queryLimit {
+		return ErrQueryLimit
+	}
+	return nil
+}
+
+// IncRetries records n retried backend requests and returns the total.
+func (t *Tracer) IncRetries(n int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Retries += n
+	return t.Retries
+}
+
 func (t *Tracer) WithLock(fn func(*Tracer)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -55,6 +88,8 @@ func (t *Tracer) WithLock(fn func(*Tracer)) {
 }
 
 func (t *Tracer) Done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.endTime = time.Now()
 	t.Duration = t.endTime.Sub(t.startTime)
 	t.DurationMillis = t.Duration.Nanoseconds() / 1000000
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCORSHeaders are the request headers cross-origin requests may send when a CORS
+// policy does not list any.
+var DefaultCORSHeaders = []string{"Content-Type", "Authorization", "X-Trace-ID"}
+
+// DefaultCORSMethods are the methods cross-origin requests may use when a CORS policy
+// does not list any.
+var DefaultCORSMethods = []string{"GET", "POST"}
+
+// CORS is the cross-origin resource sharing policy of an ExecutorHandler. Requests whose
+// Origin header is neither the handler's own host nor allowed are rejected, preflight
+// requests included, and so are preflight requests asking for methods or headers that
+// are not allowed.
+type CORS struct {
+	// AllowedOrigins are the origins allowed to make cross-origin requests: exact origins
+	// such as "https://app.example.com", origins with a wildcard such as
+	// "https://*.example.com", which matches any subdomain, or "*" for any origin.
+	AllowedOrigins []string
+	// AllowedHeaders are the request headers cross-origin requests may send, compared
+	// case-insensitively; DefaultCORSHeaders if empty. "*" allows any header.
+	AllowedHeaders []string
+	// AllowedMethods are the methods cross-origin requests may use; DefaultCORSMethods
+	// if empty.
+	AllowedMethods []string
+	// AllowCredentials lets browsers send cookies and HTTP authentication along with the
+	// cross-origin requests of origins matched by anything but "*".
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the result of a preflight request; browsers
+	// pick their own default if zero.
+	MaxAge time.Duration
+}
+
+// allowedOrigin reports whether origin may make cross-origin requests, and whether it
+// was only allowed by the "*" wildcard.
+func (c *CORS) allowedOrigin(origin string) (ok, any bool) {
+	if c == nil {
+		return false, false
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			any = true
+			continue
+		}
+		if i := strings.Index(allowed, "*"); i >= 0 {
+			prefix, suffix := allowed[:i], allowed[i+1:]
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+				!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+				return true, false
+			}
+		} else if strings.EqualFold(allowed, origin) {
+			return true, false
+		}
+	}
+	return any, any
+}
+
+func (c *CORS) allowedMethod(method string) bool {
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	for _, allowed := range methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) allowedHeader(header string) bool {
+	headers := c.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	for _, allowed := range headers {
+		if allowed == "*" || strings.EqualFold(allowed, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin reports whether origin is the origin of r: the scheme r was received with,
+// and its host. Requests received over plain HTTP from a proxy terminating TLS are thus
+// cross-origin for browsers on the https origin.
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return strings.EqualFold(u.Scheme, scheme) && strings.EqualFold(u.Host, r.Host)
+}
+
+// checkCORS applies h.CORS to r, setting the CORS headers of the response of allowed
+// cross-origin requests. It reports whether r may proceed; if not, a 403 response has been
+// written. Preflight requests are answered and do not proceed.
+func (h *ExecutorHandler) checkCORS(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != ""
+	// responses differ by origin, which caches must not serve to one another
+	w.Header().Add("Vary", "Origin")
+	if origin == "" || sameOrigin(r, origin) {
+		if preflight {
+			w.WriteHeader(http.StatusOK)
+			return false
+		}
+		return true
+	}
+	ok, any := h.CORS.allowedOrigin(origin)
+	if !ok {
+		return h.corsForbidden(w, "origin %s is not allowed", origin)
+	}
+	if preflight {
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		method := r.Header.Get("Access-Control-Request-Method")
+		if !h.CORS.allowedMethod(method) {
+			return h.corsForbidden(w, "method %s is not allowed", method)
+		}
+		var headers []string
+		for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+			if header = strings.TrimSpace(header); header == "" {
+				continue
+			}
+			if !h.CORS.allowedHeader(header) {
+				return h.corsForbidden(w, "header %s is not allowed", header)
+			}
+			headers = append(headers, header)
+		}
+		w.Header().Set("Access-Control-Allow-Methods", method)
+		if len(headers) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+		if h.CORS.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(h.CORS.MaxAge/time.Second)))
+		}
+	}
+	if any {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if h.CORS.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+	if preflight {
+		w.WriteHeader(http.StatusOK)
+		return false
+	}
+	return true
+}
+
+func (h *ExecutorHandler) corsForbidden(w http.ResponseWriter, format string, args ...interface{}) bool {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	writeErr(w, fmt.Errorf("CORS: "+format, args...))
+	return false
+}
@@ -2,19 +2,26 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 
+	"github.com/tmc/graphql"
 	"github.com/tmc/graphql/executor"
 	"github.com/tmc/graphql/executor/tracer"
 	"github.com/tmc/graphql/parser"
+	"github.com/tmc/graphql/websocket"
 	"golang.org/x/net/context"
 )
 
 // Error represents an error the occured while parsing a graphql query or while generating a response.
 type Error struct {
-	Message string `json:"message"`
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // Result represents a graphql query result.
@@ -24,14 +31,80 @@ type Result struct {
 	Error *Error      `json:"error,omitempty"`
 }
 
+// Authenticator authenticates the requests of an ExecutorHandler.
+type Authenticator interface {
+	// Authenticate returns ctx carrying the identity of the caller of r, for field
+	// functions to act on behalf of. Requests failing authentication are answered with
+	// a 401 and the error.
+	Authenticate(ctx context.Context, r *http.Request) (context.Context, error)
+}
+
 // ExecutorHandler makes a executor.Executor querable via HTTP
 type ExecutorHandler struct {
 	executor *executor.Executor
+
+	// Queries, if set, enables persisted queries: requests may name a query by the
+	// SHA-256 hash of its text in the 'persistedQuery' extension, and queries sent along
+	// with an unknown hash are registered (the automatic persisted query flow).
+	Queries QueryStore
+	// StrictQueries rejects every query that is not already present in Queries.
+	StrictQueries bool
+
+	// Observe, if set, is called with the tracer that measured every executed operation,
+	// subscriptions included once they end. Requests are then traced whether or not they
+	// carry an X-Trace-ID header, but trace information is only returned to clients that
+	// asked for it.
+	Observe func(operation *graphql.Operation, t *tracer.Tracer, err error)
+
+	// ErrorExtensions, if set, describes errors returned while executing operations as
+	// the 'extensions' of the errors reported to clients.
+	ErrorExtensions func(err error) map[string]interface{}
+
+	// MaxUploadSize bounds the size of multipart requests uploading files, in bytes. If
+	// zero, DefaultMaxUploadSize is used.
+	MaxUploadSize int64
+
+	// MaxWebSocketOperations bounds the operations, subscriptions included, running at
+	// once on a graphql-ws connection. If zero, DefaultMaxWebSocketOperations is used.
+	MaxWebSocketOperations int
+	// WebSocketInitHeaders are the headers graphql-ws clients may set in their
+	// connection_init payload. If nil, DefaultWebSocketInitHeaders is used.
+	WebSocketInitHeaders []string
+
+	// Authenticator, if set, authenticates every request before it is executed.
+	Authenticator Authenticator
+
+	// Limiter, if set, rate limits the operations of authenticated requests. Rejected
+	// requests are answered with a 429 and a Retry-After header.
+	Limiter Limiter
+
+	// CORS, if set, is the policy of cross-origin requests. Without one, requests from
+	// other origins are rejected.
+	CORS *CORS
+
+	closeOnce sync.Once
+	closed    chan struct{} // closed by CloseStreams
 }
 
 // New constructs a ExecutorHandler from a executor.
 func New(executor *executor.Executor) *ExecutorHandler {
-	return &ExecutorHandler{executor: executor}
+	return &ExecutorHandler{executor: executor, closed: make(chan struct{})}
+}
+
+// CloseStreams ends the subscription streams and graphql-ws connections served by h,
+// which would otherwise hold up a graceful server shutdown, and those served later.
+// Other requests in progress are left to complete.
+func (h *ExecutorHandler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+// newError converts an execution error into an Error.
+func (h *ExecutorHandler) newError(err error) *Error {
+	e := &Error{Message: err.Error()}
+	if h.ErrorExtensions != nil {
+		e.Extensions = h.ErrorExtensions(err)
+	}
+	return e
 }
 
 func writeErr(w io.Writer, err error) {
@@ -58,49 +131,179 @@ func writeJSONIndent(w io.Writer, data interface{}, indentString string) {
 }
 
 // ServeHTTP provides an entrypoint into a graphql executor. It pulls the query from
-// the 'q' GET parameter.
+// the 'q' GET parameter or the 'query' member of a POST request's JSON body. Request
+// extensions are read from the 'extensions' GET parameter or body member.
+//
+// Subscription operations are delivered as Server-Sent Events to requests accepting
+// 'text/event-stream'. WebSocket upgrade requests are served with the graphql-ws protocol.
+// Cross-origin requests, WebSocket upgrades included, are subject to h.CORS.
 func (h *ExecutorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.checkCORS(w, r) {
+		return
+	}
+	if websocket.IsUpgrade(r) {
+		h.serveWebSocket(w, r)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(200)
 		return
 	}
 
-	//TODO(tmc): reject non-GET/OPTIONS requests
-	q := r.URL.Query().Get("q")
-	log.Println("query:", q)
+	if r.Method != "GET" && r.Method != "POST" {
+		w.WriteHeader(405)
+		writeErr(w, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	req, err := h.readRequest(w, r)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		w.WriteHeader(400)
+		writeErr(w, err)
+		return
+	}
+	q, register, err := h.resolveQuery(req.Query, req.Extensions.PersistedQuery)
+	if err != nil {
+		// clients answer a miss by resending the full query, it is not a bad request
+		if err != ErrPersistedQueryNotFound {
+			w.WriteHeader(400)
+		}
+		writeErr(w, err)
+		return
+	}
+	// queries may carry user data, only their hash is logged
+	log.Println("query:", QueryHash(q))
 	operation, err := parser.ParseOperation([]byte(q))
 	if err != nil {
 		log.Println("error parsing:", err)
 		writeErr(w, err)
 		return
 	}
+	if err := bindVariables(operation, req.Variables); err != nil {
+		w.WriteHeader(400)
+		writeErr(w, err)
+		return
+	}
 	// if err := h.validator.Validate(operation); err != nil { writeErr(w, err); return }
-	ctx := context.Background()
-	if r.Header.Get("X-Trace-ID") != "" {
-		t, err := tracer.FromRequest(r)
-		if err == nil {
-			ctx = tracer.NewContext(ctx, t)
-		}
+	ctx, err := h.authenticate(newRequestContext(r.Context(), r), r)
+	if err != nil {
+		w.WriteHeader(401)
+		writeJSON(w, Result{Error: h.newError(err)})
+		return
+	}
+	_, traceRequested := tracer.FromContext(ctx)
+	if !traceRequested && h.Observe != nil {
+		ctx = tracer.NewContext(ctx, tracer.New(0))
 	}
-	ctx = context.WithValue(ctx, "http_request", r)
 	if r.Header.Get("X-GraphQL-Only-Parse") == "1" {
 		writeJSONIndent(w, operation, " ")
 		return
 	}
+	ctx, allowed, retryAfter := h.limit(ctx, operation)
+	if !allowed {
+		setRetryAfter(w, retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+		writeJSON(w, Result{Error: h.newError(ErrRateLimited)})
+		return
+	}
+	if register {
+		h.registerQuery(req.Extensions.PersistedQuery.SHA256Hash, q)
+	}
+	if operation.Type == graphql.OperationSubscription {
+		h.serveEventStream(ctx, w, r, operation)
+		return
+	}
 
 	data, err := h.executor.HandleOperation(ctx, operation)
 	result := Result{Data: data}
-	if err != nil {
+	retryAfter = h.limitDone(ctx, operation)
+	switch {
+	case errors.Is(err, tracer.ErrQueryLimit):
+		setRetryAfter(w, retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+		result.Error = h.newError(err)
+	case err != nil:
 		w.WriteHeader(400)
-		result.Error = &Error{Message: err.Error()}
+		result.Error = h.newError(err)
 	}
 	if t, ok := tracer.FromContext(ctx); ok {
 		t.Done()
-		result.Trace = t
+		if traceRequested {
+			result.Trace = t
+		}
+		if h.Observe != nil {
+			h.Observe(operation, t, err)
+		}
 	}
 
 	writeJSONIndent(w, result, "  ")
 }
+
+// maxRequestBody bounds the size of POST request bodies.
+const maxRequestBody = 1 << 20
+
+// request is a graphql request as sent in the body of a POST request.
+type request struct {
+	Query      string                 `json:"query"`
+	Variables  map[string]interface{} `json:"variables"`
+	Extensions struct {
+		PersistedQuery *persistedQueryExtension `json:"persistedQuery,omitempty"`
+	} `json:"extensions"`
+}
+
+func (h *ExecutorHandler) readRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+	req := &request{}
+	if r.Method == "POST" && strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return h.readMultipartRequest(w, r)
+	}
+	if r.Method == "POST" {
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBody)).Decode(req); err != nil {
+			return nil, fmt.Errorf("invalid request body: %v", err)
+		}
+		return req, nil
+	}
+	req.Query = r.URL.Query().Get("q")
+	if vars := r.URL.Query().Get("variables"); vars != "" {
+		if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+			return nil, fmt.Errorf("invalid 'variables' parameter: %v", err)
+		}
+	}
+	if ext := r.URL.Query().Get("extensions"); ext != "" {
+		if err := json.Unmarshal([]byte(ext), &req.Extensions); err != nil {
+			return nil, fmt.Errorf("invalid 'extensions' parameter: %v", err)
+		}
+	}
+	return req, nil
+}
+
+type contextKey int
+
+const requestKey contextKey = 0
+
+// newRequestContext attaches the tracer requested by r, if any, and r itself to ctx.
+func newRequestContext(ctx context.Context, r *http.Request) context.Context {
+	if r.Header.Get("X-Trace-ID") != "" {
+		t, err := tracer.FromRequest(r)
+		if err == nil {
+			ctx = tracer.NewContext(ctx, t)
+		}
+	}
+	return context.WithValue(ctx, requestKey, r)
+}
+
+// RequestFromContext returns the http request a handler is executing an operation for.
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(requestKey).(*http.Request)
+	return r, ok
+}
+
+// authenticate authenticates r with h.Authenticator, if set.
+func (h *ExecutorHandler) authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	if h.Authenticator == nil {
+		return ctx, nil
+	}
+	return h.Authenticator.Authenticate(ctx, r)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/tracer"
+	"golang.org/x/net/context"
+)
+
+// ErrRateLimited is the error of operations rejected by the Limiter of a handler.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Limiter rate limits the operations of an ExecutorHandler.
+type Limiter interface {
+	// Allow is called before the operation of the request in ctx is executed, with the
+	// tracer counting its backend queries. It reports whether the operation may be
+	// executed and, if not, how long the client should wait before retrying. It may bound
+	// the backend queries of the operation with t.SetQueryLimit.
+	Allow(ctx context.Context, operation *graphql.Operation, t *tracer.Tracer) (ok bool, retryAfter time.Duration)
+	// Done is called once an allowed query or mutation has been executed, with the tracer
+	// that counted its backend queries. It returns how long the client should wait before
+	// retrying, for operations that exceeded their query limit.
+	Done(ctx context.Context, operation *graphql.Operation, t *tracer.Tracer) (retryAfter time.Duration)
+}
+
+// limit asks h.Limiter, if set, whether operation may be executed with ctx, which is
+// returned carrying the tracer of the operation.
+func (h *ExecutorHandler) limit(ctx context.Context, operation *graphql.Operation) (context.Context, bool, time.Duration) {
+	if h.Limiter == nil {
+		return ctx, true, 0
+	}
+	t, ok := tracer.FromContext(ctx)
+	if !ok {
+		t = tracer.New(0)
+		ctx = tracer.NewContext(ctx, t)
+	}
+	ok, retryAfter := h.Limiter.Allow(ctx, operation, t)
+	return ctx, ok, retryAfter
+}
+
+// limitDone reports the execution of operation to h.Limiter, if set, and returns how long
+// the client should wait before retrying should the operation have exceeded its query
+// limit.
+func (h *ExecutorHandler) limitDone(ctx context.Context, operation *graphql.Operation) time.Duration {
+	if h.Limiter == nil {
+		return 0
+	}
+	t, ok := tracer.FromContext(ctx)
+	if !ok {
+		return 0
+	}
+	return h.Limiter.Done(ctx, operation, t)
+}
+
+// setRetryAfter sets the Retry-After header of a rate limited response, in whole seconds.
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
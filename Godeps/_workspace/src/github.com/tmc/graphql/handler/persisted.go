@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrPersistedQueryNotFound is returned when a query is requested by a hash that has not
+	// been registered. Automatic persisted query clients retry with the full query text.
+	ErrPersistedQueryNotFound = errors.New("PersistedQueryNotFound")
+	// ErrPersistedQueryNotSupported is returned for persisted query requests to a handler
+	// without a QueryStore.
+	ErrPersistedQueryNotSupported = errors.New("PersistedQueryNotSupported")
+	// ErrPersistedQueryHashMismatch is returned when a query does not match the hash sent with it.
+	ErrPersistedQueryHashMismatch = errors.New("provided sha256Hash does not match query")
+	// ErrQueryNotAllowed is returned in strict mode for queries missing from the QueryStore.
+	ErrQueryNotAllowed = errors.New("query is not in the persisted query manifest")
+	// ErrReadOnlyQueryStore is returned when registering a query with a manifest, or with a
+	// FileQueryStore that is not Writable.
+	ErrReadOnlyQueryStore = errors.New("persisted query store is read-only")
+	// ErrQueryStoreFull is returned when registering a query with a FileQueryStore holding
+	// MaxQueries queries.
+	ErrQueryStoreFull = errors.New("persisted query store is full")
+	// ErrPersistedQueryTooLarge is returned when registering a query larger than the
+	// MaxQuerySize of a store.
+	ErrPersistedQueryTooLarge = errors.New("query is too large to be persisted")
+	// ErrInvalidQueryHash is returned for hashes that are not hex encoded SHA-256 sums.
+	ErrInvalidQueryHash = errors.New("invalid persisted query hash")
+)
+
+// QueryStore looks up and registers queries by the hex encoded SHA-256 hash of their text.
+type QueryStore interface {
+	Get(hash string) (query string, ok bool)
+	Put(hash, query string) error
+}
+
+// QueryHash returns the hex encoded SHA-256 hash identifying query.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Default bounds of the queries registered with MemoryQueryStore and FileQueryStore.
+const (
+	DefaultMaxPersistedQueries   = 10000
+	DefaultMaxPersistedQuerySize = 16 << 10
+)
+
+// checkQuerySize returns ErrPersistedQueryTooLarge if query is larger than max, or than
+// DefaultMaxPersistedQuerySize if max is zero.
+func checkQuerySize(query string, max int) error {
+	if max == 0 {
+		max = DefaultMaxPersistedQuerySize
+	}
+	if len(query) > max {
+		return ErrPersistedQueryTooLarge
+	}
+	return nil
+}
+
+func validQueryHash(hash string) bool {
+	b, err := hex.DecodeString(hash)
+	return err == nil && len(b) == sha256.Size
+}
+
+// MemoryQueryStore is a QueryStore held in memory. Once it holds MaxQueries queries,
+// registering another forgets the least recently used one.
+type MemoryQueryStore struct {
+	// MaxQueries bounds the number of queries registered; DefaultMaxPersistedQueries if
+	// zero.
+	MaxQueries int
+	// MaxQuerySize bounds the size of the queries registered, in bytes;
+	// DefaultMaxPersistedQuerySize if zero.
+	MaxQuerySize int
+
+	mu       sync.Mutex
+	queries  map[string]*list.Element // of *storedQuery
+	lru      *list.List               // most recently used first
+	readOnly bool
+}
+
+type storedQuery struct {
+	hash, query string
+}
+
+// NewMemoryQueryStore returns an empty, writable MemoryQueryStore.
+func NewMemoryQueryStore() *MemoryQueryStore {
+	return &MemoryQueryStore{queries: map[string]*list.Element{}, lru: list.New()}
+}
+
+func (s *MemoryQueryStore) Get(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.queries[hash]
+	if !ok {
+		return "", false
+	}
+	s.lru.MoveToFront(e)
+	return e.Value.(*storedQuery).query, true
+}
+
+func (s *MemoryQueryStore) Put(hash, query string) error {
+	if s.readOnly {
+		return ErrReadOnlyQueryStore
+	}
+	if err := checkQuerySize(query, s.MaxQuerySize); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.queries[hash]; ok {
+		s.lru.MoveToFront(e)
+		return nil
+	}
+	max := s.MaxQueries
+	if max == 0 {
+		max = DefaultMaxPersistedQueries
+	}
+	for s.lru.Len() >= max {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.queries, oldest.Value.(*storedQuery).hash)
+	}
+	s.queries[hash] = s.lru.PushFront(&storedQuery{hash: hash, query: query})
+	return nil
+}
+
+// LoadQueryManifest reads a read-only QueryStore from a JSON manifest. The manifest is
+// either an object mapping hashes to queries or an Apollo persisted query manifest
+// ({"operations": [{"id": hash, "body": query}]}). Every hash is verified against its query.
+func LoadQueryManifest(path string) (*MemoryQueryStore, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	queries := map[string]string{}
+	if err := json.Unmarshal(b, &manifest); err == nil && manifest.Operations != nil {
+		for _, op := range manifest.Operations {
+			queries[op.ID] = op.Body
+		}
+	} else if err := json.Unmarshal(b, &queries); err != nil {
+		return nil, err
+	}
+	s := NewMemoryQueryStore()
+	for hash, query := range queries {
+		if QueryHash(query) != hash {
+			return nil, ErrPersistedQueryHashMismatch
+		}
+		s.queries[hash] = s.lru.PushFront(&storedQuery{hash: hash, query: query})
+	}
+	s.readOnly = true
+	return s, nil
+}
+
+// FileQueryStore is a QueryStore keeping one '<hash>.graphql' file per query in a
+// directory. It is read-only unless Writable is set.
+type FileQueryStore struct {
+	// Writable lets queries be registered, up to MaxQueries of them.
+	Writable bool
+	// MaxQueries bounds the number of queries in the directory; DefaultMaxPersistedQueries
+	// if zero.
+	MaxQueries int
+	// MaxQuerySize bounds the size of the queries registered, in bytes;
+	// DefaultMaxPersistedQuerySize if zero.
+	MaxQuerySize int
+
+	dir string
+
+	mu    sync.Mutex
+	count int // of queries in dir
+}
+
+// NewFileQueryStore returns a read-only FileQueryStore backed by dir, creating it if
+// needed.
+func NewFileQueryStore(dir string) (*FileQueryStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileQueryStore{dir: dir}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".graphql") {
+			s.count++
+		}
+	}
+	return s, nil
+}
+
+func (s *FileQueryStore) Get(hash string) (string, bool) {
+	if !validQueryHash(hash) {
+		return "", false
+	}
+	b, err := ioutil.ReadFile(filepath.Join(s.dir, hash+".graphql"))
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func (s *FileQueryStore) Put(hash, query string) error {
+	if !s.Writable {
+		return ErrReadOnlyQueryStore
+	}
+	if !validQueryHash(hash) {
+		return ErrInvalidQueryHash
+	}
+	if err := checkQuerySize(query, s.MaxQuerySize); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.dir, hash+".graphql")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	max := s.MaxQueries
+	if max == 0 {
+		max = DefaultMaxPersistedQueries
+	}
+	if s.count >= max {
+		return ErrQueryStoreFull
+	}
+	// write then rename so concurrent readers never observe a partial query
+	tmp, err := ioutil.TempFile(s.dir, hash)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(query); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// persistedQueryExtension is the 'persistedQuery' request extension sent by automatic
+// persisted query clients.
+type persistedQueryExtension struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// resolveQuery applies the persisted query rules of h to a request for query with the
+// given persisted query extension (which may be nil) and returns the query to execute,
+// and whether it should be registered under the hash of the extension once the request
+// is authenticated and within its rate limits.
+func (h *ExecutorHandler) resolveQuery(query string, ext *persistedQueryExtension) (string, bool, error) {
+	if ext == nil || ext.SHA256Hash == "" {
+		if !h.StrictQueries {
+			return query, false, nil
+		}
+		if h.Queries == nil {
+			return "", false, ErrQueryNotAllowed
+		}
+		if _, ok := h.Queries.Get(QueryHash(query)); !ok {
+			return "", false, ErrQueryNotAllowed
+		}
+		return query, false, nil
+	}
+	if h.Queries == nil {
+		return "", false, ErrPersistedQueryNotSupported
+	}
+	if !validQueryHash(ext.SHA256Hash) {
+		return "", false, ErrInvalidQueryHash
+	}
+	if query == "" {
+		stored, ok := h.Queries.Get(ext.SHA256Hash)
+		if !ok {
+			return "", false, ErrPersistedQueryNotFound
+		}
+		return stored, false, nil
+	}
+	if QueryHash(query) != ext.SHA256Hash {
+		return "", false, ErrPersistedQueryHashMismatch
+	}
+	if _, ok := h.Queries.Get(ext.SHA256Hash); ok {
+		return query, false, nil
+	}
+	if h.StrictQueries {
+		return "", false, ErrQueryNotAllowed
+	}
+	return query, true, nil
+}
+
+// registerQuery registers query under hash. It is only called for queries that parse, so
+// clients cannot fill the store with junk.
+func (h *ExecutorHandler) registerQuery(hash, query string) {
+	switch err := h.Queries.Put(hash, query); err {
+	case nil, ErrReadOnlyQueryStore, ErrQueryStoreFull, ErrPersistedQueryTooLarge:
+		// queries that cannot be registered are still executed
+	default:
+		log.Println("error registering persisted query:", err)
+	}
+}
@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/graphql/parser"
+	"github.com/tmc/graphql/websocket"
+	"golang.org/x/net/context"
+)
+
+// KeepAliveInterval is how often idle subscription streams are sent a keep-alive.
+var KeepAliveInterval = 15 * time.Second
+
+// WebSocketWriteTimeout bounds the time taken to send a message to a graphql-ws client;
+// connections of clients too slow to read their messages are closed.
+var WebSocketWriteTimeout = 10 * time.Second
+
+// DefaultMaxWebSocketOperations bounds the operations running at once on a graphql-ws
+// connection of handlers without a MaxWebSocketOperations.
+const DefaultMaxWebSocketOperations = 100
+
+// DefaultWebSocketInitHeaders are the headers graphql-ws clients of handlers without
+// WebSocketInitHeaders may set in their connection_init payload: the credentials of
+// the connection. Other headers, such as X-Forwarded-For, cannot be overridden.
+var DefaultWebSocketInitHeaders = []string{"Authorization", "X-Parse-Session-Token"}
+
+// ErrTooManyOperations is reported to operations started on a graphql-ws connection
+// already running its maximum number of operations.
+var ErrTooManyOperations = errors.New("too many operations running on this connection")
+
+// serveEventStream delivers the results of a subscription as Server-Sent Events. Every
+// result is sent as a "next" event carrying a Result and a "complete" event is sent once
+// the subscription ends.
+func (h *ExecutorHandler) serveEventStream(ctx context.Context, w http.ResponseWriter, r *http.Request, operation *graphql.Operation) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.WriteHeader(406)
+		writeErr(w, fmt.Errorf("subscriptions require 'Accept: text/event-stream' or a graphql-ws connection"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(500)
+		writeErr(w, fmt.Errorf("streaming is not supported by this connection"))
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-h.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	results, err := h.executor.Subscribe(ctx, operation)
+	if err != nil {
+		h.observe(ctx, operation, err)
+		w.WriteHeader(400)
+		writeErr(w, err)
+		return
+	}
+	defer h.observe(ctx, operation, nil)
+	// streams outlive the write timeout of the server's requests
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(200)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(KeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case res, ok := <-results:
+			if !ok {
+				fmt.Fprint(w, "event: complete\ndata:\n\n")
+				flusher.Flush()
+				return
+			}
+			result := Result{Data: res.Data}
+			if res.Err != nil {
+				result.Error = h.newError(res.Err)
+			}
+			b, err := json.Marshal(result)
+			if err != nil {
+				log.Println("error encoding subscription result:", err)
+				return
+			}
+			fmt.Fprintf(w, "event: next\ndata: %s\n\n", b)
+		}
+		flusher.Flush()
+	}
+}
+
+// graphql-ws message types, as defined by subscriptions-transport-ws.
+const (
+	gqlConnectionInit      = "connection_init"
+	gqlConnectionAck       = "connection_ack"
+	gqlConnectionError     = "connection_error"
+	gqlConnectionTerminate = "connection_terminate"
+	gqlStart               = "start"
+	gqlStop                = "stop"
+	gqlData                = "data"
+	gqlError               = "error"
+	gqlComplete            = "complete"
+)
+
+type gqlMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type gqlStartPayload struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Extensions    struct {
+		PersistedQuery *persistedQueryExtension `json:"persistedQuery,omitempty"`
+	} `json:"extensions"`
+}
+
+// serveWebSocket speaks the graphql-ws protocol over a WebSocket connection. Queries and
+// mutations produce a single "data" message followed by "complete"; subscriptions
+// produce a "data" message per result until they are stopped or end.
+//
+// String values in the connection_init payload naming one of the WebSocketInitHeaders
+// are treated as request headers (for example X-Parse-Session-Token) as browsers cannot
+// set headers on WebSocket requests; the connection is authenticated again with them,
+// and closed if that fails.
+func (h *ExecutorHandler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r, "graphql-ws")
+	if err != nil {
+		log.Println("error upgrading websocket:", err)
+		return
+	}
+	defer conn.Close()
+	conn.WriteTimeout = WebSocketWriteTimeout
+	maxOperations := h.MaxWebSocketOperations
+	if maxOperations <= 0 {
+		maxOperations = DefaultMaxWebSocketOperations
+	}
+	headers := h.WebSocketInitHeaders
+	if headers == nil {
+		headers = DefaultWebSocketInitHeaders
+	}
+	initHeaders := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		initHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+
+	// the connection is closed along with the streams of h, stopping its operations
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.closed:
+			conn.Close()
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	// authErr is reported to operations started before a connection_init authenticates
+	reqCtx, authErr := h.authenticate(newRequestContext(ctx, r), r)
+	// operations holds the running operations by id, each removing itself when it ends
+	var mu sync.Mutex
+	operations := map[string]*wsOperation{}
+	for {
+		var msg gqlMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case gqlConnectionInit:
+			var params map[string]interface{}
+			if len(msg.Payload) > 0 {
+				if err := json.Unmarshal(msg.Payload, &params); err != nil {
+					conn.WriteJSON(gqlMessage{Type: gqlConnectionError, Payload: h.errorPayload(err)})
+					return
+				}
+			}
+			initReq := new(http.Request)
+			*initReq = *r
+			initReq.Header = make(http.Header, len(r.Header)+len(params))
+			for k, v := range r.Header {
+				initReq.Header[k] = v
+			}
+			for k, v := range params {
+				if s, ok := v.(string); ok && initHeaders[http.CanonicalHeaderKey(k)] {
+					initReq.Header.Set(k, s)
+				}
+			}
+			if reqCtx, authErr = h.authenticate(newRequestContext(ctx, initReq), initReq); authErr != nil {
+				conn.WriteJSON(gqlMessage{Type: gqlConnectionError, Payload: h.errorPayload(authErr)})
+				return
+			}
+			conn.WriteJSON(gqlMessage{Type: gqlConnectionAck})
+		case gqlStart:
+			if authErr != nil {
+				conn.WriteJSON(gqlMessage{ID: msg.ID, Type: gqlError, Payload: h.errorPayload(authErr)})
+				continue
+			}
+			var payload gqlStartPayload
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				conn.WriteJSON(gqlMessage{ID: msg.ID, Type: gqlError, Payload: h.errorPayload(err)})
+				continue
+			}
+			query, register, err := h.resolveQuery(payload.Query, payload.Extensions.PersistedQuery)
+			if err != nil {
+				conn.WriteJSON(gqlMessage{ID: msg.ID, Type: gqlError, Payload: h.errorPayload(err)})
+				continue
+			}
+			mu.Lock()
+			running, replaced := operations[msg.ID]
+			if !replaced && len(operations) >= maxOperations {
+				mu.Unlock()
+				conn.WriteJSON(gqlMessage{ID: msg.ID, Type: gqlError, Payload: h.errorPayload(ErrTooManyOperations)})
+				continue
+			}
+			if replaced {
+				running.stop()
+			}
+			opCtx, stop := context.WithCancel(reqCtx)
+			op := &wsOperation{stop: stop}
+			operations[msg.ID] = op
+			mu.Unlock()
+			go func(id string) {
+				defer stop()
+				var registerHash string
+				if register {
+					registerHash = payload.Extensions.PersistedQuery.SHA256Hash
+				}
+				h.runWebSocketOperation(opCtx, conn, id, query, payload.Variables, registerHash)
+				mu.Lock()
+				defer mu.Unlock()
+				// the id may have been reused by a later operation
+				if operations[id] == op {
+					delete(operations, id)
+				}
+			}(msg.ID)
+		case gqlStop:
+			mu.Lock()
+			if op, ok := operations[msg.ID]; ok {
+				op.stop()
+				delete(operations, msg.ID)
+			}
+			mu.Unlock()
+		case gqlConnectionTerminate:
+			return
+		}
+	}
+}
+
+// wsOperation is an operation running on a graphql-ws connection.
+type wsOperation struct {
+	stop context.CancelFunc
+}
+
+// runWebSocketOperation runs query with variables as the operation id of conn, and
+// registers it as the persisted query registerHash, if set, once it is allowed to run.
+func (h *ExecutorHandler) runWebSocketOperation(ctx context.Context, conn *websocket.Conn, id, query string, variables map[string]interface{}, registerHash string) {
+	operation, err := parser.ParseOperation([]byte(query))
+	if err == nil {
+		err = bindVariables(operation, variables)
+	}
+	if err != nil {
+		conn.WriteJSON(gqlMessage{ID: id, Type: gqlError, Payload: h.errorPayload(err)})
+		return
+	}
+	if h.Limiter != nil || h.Observe != nil {
+		// the operations of a connection share its context but not their tracers
+		ctx = tracer.NewContext(ctx, tracer.New(0))
+	}
+	ctx, allowed, _ := h.limit(ctx, operation)
+	if !allowed {
+		conn.WriteJSON(gqlMessage{ID: id, Type: gqlError, Payload: h.errorPayload(ErrRateLimited)})
+		return
+	}
+	if registerHash != "" {
+		h.registerQuery(registerHash, query)
+	}
+	if operation.Type != graphql.OperationSubscription {
+		data, err := h.executor.HandleOperation(ctx, operation)
+		h.limitDone(ctx, operation)
+		h.observe(ctx, operation, err)
+		conn.WriteJSON(gqlMessage{ID: id, Type: gqlData, Payload: h.resultPayload(data, err)})
+		conn.WriteJSON(gqlMessage{ID: id, Type: gqlComplete})
+		return
+	}
+	results, err := h.executor.Subscribe(ctx, operation)
+	if err != nil {
+		h.observe(ctx, operation, err)
+		conn.WriteJSON(gqlMessage{ID: id, Type: gqlError, Payload: h.errorPayload(err)})
+		return
+	}
+	defer h.observe(ctx, operation, nil)
+	for res := range results {
+		if err := conn.WriteJSON(gqlMessage{ID: id, Type: gqlData, Payload: h.resultPayload(res.Data, res.Err)}); err != nil {
+			// a write that failed or timed out leaves the connection unusable
+			conn.Close()
+			return
+		}
+	}
+	conn.WriteJSON(gqlMessage{ID: id, Type: gqlComplete})
+}
+
+// observe passes the tracer of an operation that ended with err to h.Observe, if set.
+func (h *ExecutorHandler) observe(ctx context.Context, operation *graphql.Operation, err error) {
+	if h.Observe == nil {
+		return
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.Done()
+		h.Observe(operation, t, err)
+	}
+}
+
+func (h *ExecutorHandler) errorPayload(err error) json.RawMessage {
+	b, _ := json.Marshal(h.newError(err))
+	return b
+}
+
+func (h *ExecutorHandler) resultPayload(data interface{}, err error) json.RawMessage {
+	payload := struct {
+		Data   interface{} `json:"data"`
+		Errors []Error     `json:"errors,omitempty"`
+	}{Data: data}
+	if err != nil {
+		payload.Errors = []Error{*h.newError(err)}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return h.errorPayload(err)
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxUploadSize bounds the size of multipart requests of handlers without a
+// MaxUploadSize.
+const DefaultMaxUploadSize = 32 << 20
+
+// multipartMemory is the part of multipart requests held in memory, the rest is
+// buffered to temporary files.
+const multipartMemory = 8 << 20
+
+// Upload is a file sent along with an operation in a multipart request (see
+// https://github.com/jaydenseric/graphql-multipart-request-spec). It is the value of
+// the variables the request maps the file to, and can only be read while the request is
+// handled.
+type Upload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+
+	header *multipart.FileHeader
+}
+
+// Open opens the contents of the file.
+func (u *Upload) Open() (multipart.File, error) {
+	return u.header.Open()
+}
+
+// readMultipartRequest reads a multipart request: an 'operations' field holding the
+// request, a 'map' field mapping the names of file fields to the variables they are the
+// value of, and the file fields.
+func (h *ExecutorHandler) readMultipartRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+	maxSize := h.MaxUploadSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxUploadSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		return nil, fmt.Errorf("invalid multipart request: %v", err)
+	}
+	req := &request{}
+	operations := r.MultipartForm.Value["operations"]
+	if len(operations) != 1 {
+		return nil, fmt.Errorf("invalid multipart request: expected one 'operations' field")
+	}
+	if err := json.Unmarshal([]byte(operations[0]), req); err != nil {
+		return nil, fmt.Errorf("invalid multipart request: 'operations' must be a request object: %v", err)
+	}
+	var fileMap map[string][]string
+	if m := r.MultipartForm.Value["map"]; len(m) == 1 {
+		if err := json.Unmarshal([]byte(m[0]), &fileMap); err != nil {
+			return nil, fmt.Errorf("invalid multipart request: invalid 'map' field: %v", err)
+		}
+	}
+	for field, paths := range fileMap {
+		files := r.MultipartForm.File[field]
+		if len(files) != 1 {
+			return nil, fmt.Errorf("invalid multipart request: missing file field '%s'", field)
+		}
+		upload := &Upload{
+			Filename:    files[0].Filename,
+			ContentType: files[0].Header.Get("Content-Type"),
+			Size:        files[0].Size,
+			header:      files[0],
+		}
+		for _, path := range paths {
+			if err := setVariable(req, path, upload); err != nil {
+				return nil, fmt.Errorf("invalid multipart request: %v", err)
+			}
+		}
+	}
+	return req, nil
+}
+
+// setVariable sets the value at path, such as 'variables.file' or 'variables.files.0',
+// in the variables of req.
+func setVariable(req *request, path string, value interface{}) error {
+	parts := strings.Split(path, ".")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "variables" {
+		return fmt.Errorf("unsupported path '%s'", path)
+	}
+	if req.Variables == nil {
+		req.Variables = map[string]interface{}{}
+	}
+	name := parts[1]
+	if len(parts) == 2 {
+		req.Variables[name] = value
+		return nil
+	}
+	i, err := strconv.Atoi(parts[2])
+	list, ok := req.Variables[name].([]interface{})
+	if err != nil || !ok || i < 0 || i >= len(list) {
+		return fmt.Errorf("path '%s' does not refer to an element of a list variable", path)
+	}
+	list[i] = value
+	return nil
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tmc/graphql"
+)
+
+// bindVariables replaces the variables referenced by the arguments of operation with
+// their values in variables, or their default values. Variables that are neither set
+// nor have a default are null.
+func bindVariables(operation *graphql.Operation, variables map[string]interface{}) error {
+	values := map[string]interface{}{}
+	for _, def := range operation.VariableDefinitions {
+		if def.DefaultValue != nil {
+			values[variableName(def.Variable.Name)] = *def.DefaultValue
+		}
+	}
+	for name, value := range variables {
+		values[name] = value
+	}
+	return bindSelections(operation.SelectionSet, values)
+}
+
+func bindSelections(selections graphql.SelectionSet, values map[string]interface{}) error {
+	for _, selection := range selections {
+		var children graphql.SelectionSet
+		switch {
+		case selection.Field != nil:
+			for i, arg := range selection.Field.Arguments {
+				value, err := bindValue(arg.Value, values)
+				if err != nil {
+					return err
+				}
+				selection.Field.Arguments[i].Value = value
+			}
+			children = selection.Field.SelectionSet
+		case selection.InlineFragment != nil:
+			children = selection.InlineFragment.SelectionSet
+		}
+		if err := bindSelections(children, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindValue returns value with the variables it references replaced.
+func bindValue(value interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case graphql.Variable:
+		result := values[variableName(v.Name)]
+		if v.PropertySelection == nil {
+			return result, nil
+		}
+		object, ok := result.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot select property '%s' of variable %s: not an object", v.PropertySelection.Name, v.Name)
+		}
+		return object[v.PropertySelection.Name], nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, e := range v {
+			bound, err := bindValue(e, values)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = bound
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			bound, err := bindValue(e, values)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = bound
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// variableName strips the '$' the parser keeps in variable names.
+func variableName(name string) string {
+	return strings.TrimPrefix(name, "$")
+}
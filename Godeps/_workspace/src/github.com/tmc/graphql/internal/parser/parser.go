@@ -353,48 +353,57 @@ var g = &grammar{
 							ignoreCase: false,
 						},
 					},
+					&actionExpr{
+						pos: position{line: 110, col: 18, offset: 3063},
+						run: (*parser).callonOperationType6,
+						expr: &litMatcher{
+							pos:        position{line: 110, col: 18, offset: 3063},
+							val:        "subscription",
+							ignoreCase: false,
+						},
+					},
 				},
 			},
 		},
 		{
 			name: "OperationName",
-			pos:  position{line: 110, col: 1, offset: 3046},
+			pos:  position{line: 111, col: 1, offset: 3124},
 			expr: &actionExpr{
-				pos: position{line: 110, col: 17, offset: 3064},
+				pos: position{line: 111, col: 17, offset: 3142},
 				run: (*parser).callonOperationName1,
 				expr: &ruleRefExpr{
-					pos:  position{line: 110, col: 17, offset: 3064},
+					pos:  position{line: 111, col: 17, offset: 3142},
 					name: "Name",
 				},
 			},
 		},
 		{
 			name: "VariableDefinitions",
-			pos:  position{line: 113, col: 1, offset: 3101},
+			pos:  position{line: 114, col: 1, offset: 3179},
 			expr: &actionExpr{
-				pos: position{line: 113, col: 23, offset: 3125},
+				pos: position{line: 114, col: 23, offset: 3203},
 				run: (*parser).callonVariableDefinitions1,
 				expr: &seqExpr{
-					pos: position{line: 113, col: 23, offset: 3125},
+					pos: position{line: 114, col: 23, offset: 3203},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 113, col: 23, offset: 3125},
+							pos:        position{line: 114, col: 23, offset: 3203},
 							val:        "(",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 27, offset: 3129},
+							pos:   position{line: 114, col: 27, offset: 3207},
 							label: "vds",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 113, col: 31, offset: 3133},
+								pos: position{line: 114, col: 31, offset: 3211},
 								expr: &ruleRefExpr{
-									pos:  position{line: 113, col: 31, offset: 3133},
+									pos:  position{line: 114, col: 31, offset: 3211},
 									name: "VariableDefinition",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 113, col: 51, offset: 3153},
+							pos:        position{line: 114, col: 51, offset: 3231},
 							val:        ")",
 							ignoreCase: false,
 						},
@@ -404,63 +413,63 @@ var g = &grammar{
 		},
 		{
 			name: "VariableDefinition",
-			pos:  position{line: 120, col: 1, offset: 3314},
+			pos:  position{line: 121, col: 1, offset: 3392},
 			expr: &actionExpr{
-				pos: position{line: 120, col: 22, offset: 3337},
+				pos: position{line: 121, col: 22, offset: 3415},
 				run: (*parser).callonVariableDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 120, col: 22, offset: 3337},
+					pos: position{line: 121, col: 22, offset: 3415},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 120, col: 22, offset: 3337},
+							pos:  position{line: 121, col: 22, offset: 3415},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 120, col: 24, offset: 3339},
+							pos:   position{line: 121, col: 24, offset: 3417},
 							label: "v",
 							expr: &ruleRefExpr{
-								pos:  position{line: 120, col: 26, offset: 3341},
+								pos:  position{line: 121, col: 26, offset: 3419},
 								name: "Variable",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 120, col: 35, offset: 3350},
+							pos:  position{line: 121, col: 35, offset: 3428},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 120, col: 37, offset: 3352},
+							pos:        position{line: 121, col: 37, offset: 3430},
 							val:        ":",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 120, col: 41, offset: 3356},
+							pos:  position{line: 121, col: 41, offset: 3434},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 120, col: 43, offset: 3358},
+							pos:   position{line: 121, col: 43, offset: 3436},
 							label: "t",
 							expr: &ruleRefExpr{
-								pos:  position{line: 120, col: 45, offset: 3360},
+								pos:  position{line: 121, col: 45, offset: 3438},
 								name: "Type",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 120, col: 50, offset: 3365},
+							pos:  position{line: 121, col: 50, offset: 3443},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 120, col: 52, offset: 3367},
+							pos:   position{line: 121, col: 52, offset: 3445},
 							label: "d",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 120, col: 54, offset: 3369},
+								pos: position{line: 121, col: 54, offset: 3447},
 								expr: &ruleRefExpr{
-									pos:  position{line: 120, col: 54, offset: 3369},
+									pos:  position{line: 121, col: 54, offset: 3447},
 									name: "DefaultValue",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 120, col: 68, offset: 3383},
+							pos:  position{line: 121, col: 68, offset: 3461},
 							name: "_",
 						},
 					},
@@ -469,23 +478,23 @@ var g = &grammar{
 		},
 		{
 			name: "DefaultValue",
-			pos:  position{line: 132, col: 1, offset: 3619},
+			pos:  position{line: 133, col: 1, offset: 3697},
 			expr: &actionExpr{
-				pos: position{line: 132, col: 16, offset: 3636},
+				pos: position{line: 133, col: 16, offset: 3714},
 				run: (*parser).callonDefaultValue1,
 				expr: &seqExpr{
-					pos: position{line: 132, col: 16, offset: 3636},
+					pos: position{line: 133, col: 16, offset: 3714},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 132, col: 16, offset: 3636},
+							pos:        position{line: 133, col: 16, offset: 3714},
 							val:        "=",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 132, col: 20, offset: 3640},
+							pos:   position{line: 133, col: 20, offset: 3718},
 							label: "v",
 							expr: &ruleRefExpr{
-								pos:  position{line: 132, col: 22, offset: 3642},
+								pos:  position{line: 133, col: 22, offset: 3720},
 								name: "Value",
 							},
 						},
@@ -495,31 +504,31 @@ var g = &grammar{
 		},
 		{
 			name: "SelectionSet",
-			pos:  position{line: 134, col: 1, offset: 3667},
+			pos:  position{line: 135, col: 1, offset: 3745},
 			expr: &actionExpr{
-				pos: position{line: 134, col: 16, offset: 3684},
+				pos: position{line: 135, col: 16, offset: 3762},
 				run: (*parser).callonSelectionSet1,
 				expr: &seqExpr{
-					pos: position{line: 134, col: 16, offset: 3684},
+					pos: position{line: 135, col: 16, offset: 3762},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 134, col: 16, offset: 3684},
+							pos:        position{line: 135, col: 16, offset: 3762},
 							val:        "{",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 134, col: 20, offset: 3688},
+							pos:   position{line: 135, col: 20, offset: 3766},
 							label: "s",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 134, col: 23, offset: 3691},
+								pos: position{line: 135, col: 23, offset: 3769},
 								expr: &ruleRefExpr{
-									pos:  position{line: 134, col: 23, offset: 3691},
+									pos:  position{line: 135, col: 23, offset: 3769},
 									name: "Selection",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 134, col: 35, offset: 3703},
+							pos:        position{line: 135, col: 35, offset: 3781},
 							val:        "}",
 							ignoreCase: false,
 						},
@@ -529,80 +538,80 @@ var g = &grammar{
 		},
 		{
 			name: "Selection",
-			pos:  position{line: 145, col: 1, offset: 3969},
+			pos:  position{line: 146, col: 1, offset: 4047},
 			expr: &choiceExpr{
-				pos: position{line: 145, col: 13, offset: 3983},
+				pos: position{line: 146, col: 13, offset: 4061},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 145, col: 13, offset: 3983},
+						pos: position{line: 146, col: 13, offset: 4061},
 						run: (*parser).callonSelection2,
 						expr: &seqExpr{
-							pos: position{line: 145, col: 14, offset: 3984},
+							pos: position{line: 146, col: 14, offset: 4062},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 145, col: 14, offset: 3984},
+									pos:  position{line: 146, col: 14, offset: 4062},
 									name: "_",
 								},
 								&labeledExpr{
-									pos:   position{line: 145, col: 16, offset: 3986},
+									pos:   position{line: 146, col: 16, offset: 4064},
 									label: "f",
 									expr: &ruleRefExpr{
-										pos:  position{line: 145, col: 18, offset: 3988},
+										pos:  position{line: 146, col: 18, offset: 4066},
 										name: "Field",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 145, col: 24, offset: 3994},
+									pos:  position{line: 146, col: 24, offset: 4072},
 									name: "_",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 148, col: 5, offset: 4076},
+						pos: position{line: 149, col: 5, offset: 4154},
 						run: (*parser).callonSelection8,
 						expr: &seqExpr{
-							pos: position{line: 148, col: 6, offset: 4077},
+							pos: position{line: 149, col: 6, offset: 4155},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 148, col: 6, offset: 4077},
+									pos:  position{line: 149, col: 6, offset: 4155},
 									name: "_",
 								},
 								&labeledExpr{
-									pos:   position{line: 148, col: 8, offset: 4079},
+									pos:   position{line: 149, col: 8, offset: 4157},
 									label: "fs",
 									expr: &ruleRefExpr{
-										pos:  position{line: 148, col: 11, offset: 4082},
+										pos:  position{line: 149, col: 11, offset: 4160},
 										name: "FragmentSpread",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 148, col: 26, offset: 4097},
+									pos:  position{line: 149, col: 26, offset: 4175},
 									name: "_",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 151, col: 5, offset: 4216},
+						pos: position{line: 152, col: 5, offset: 4294},
 						run: (*parser).callonSelection14,
 						expr: &seqExpr{
-							pos: position{line: 151, col: 6, offset: 4217},
+							pos: position{line: 152, col: 6, offset: 4295},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 151, col: 6, offset: 4217},
+									pos:  position{line: 152, col: 6, offset: 4295},
 									name: "_",
 								},
 								&labeledExpr{
-									pos:   position{line: 151, col: 8, offset: 4219},
+									pos:   position{line: 152, col: 8, offset: 4297},
 									label: "fs",
 									expr: &ruleRefExpr{
-										pos:  position{line: 151, col: 11, offset: 4222},
+										pos:  position{line: 152, col: 11, offset: 4300},
 										name: "InlineFragment",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 151, col: 26, offset: 4237},
+									pos:  position{line: 152, col: 26, offset: 4315},
 									name: "_",
 								},
 							},
@@ -613,77 +622,77 @@ var g = &grammar{
 		},
 		{
 			name: "Field",
-			pos:  position{line: 156, col: 1, offset: 4355},
+			pos:  position{line: 157, col: 1, offset: 4433},
 			expr: &actionExpr{
-				pos: position{line: 156, col: 9, offset: 4365},
+				pos: position{line: 157, col: 9, offset: 4443},
 				run: (*parser).callonField1,
 				expr: &seqExpr{
-					pos: position{line: 156, col: 9, offset: 4365},
+					pos: position{line: 157, col: 9, offset: 4443},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 156, col: 9, offset: 4365},
+							pos:   position{line: 157, col: 9, offset: 4443},
 							label: "fa",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 156, col: 12, offset: 4368},
+								pos: position{line: 157, col: 12, offset: 4446},
 								expr: &ruleRefExpr{
-									pos:  position{line: 156, col: 12, offset: 4368},
+									pos:  position{line: 157, col: 12, offset: 4446},
 									name: "FieldAlias",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 24, offset: 4380},
+							pos:  position{line: 157, col: 24, offset: 4458},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 26, offset: 4382},
+							pos:   position{line: 157, col: 26, offset: 4460},
 							label: "fn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 156, col: 29, offset: 4385},
+								pos:  position{line: 157, col: 29, offset: 4463},
 								name: "FieldName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 39, offset: 4395},
+							pos:  position{line: 157, col: 39, offset: 4473},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 41, offset: 4397},
+							pos:   position{line: 157, col: 41, offset: 4475},
 							label: "as",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 156, col: 44, offset: 4400},
+								pos: position{line: 157, col: 44, offset: 4478},
 								expr: &ruleRefExpr{
-									pos:  position{line: 156, col: 44, offset: 4400},
+									pos:  position{line: 157, col: 44, offset: 4478},
 									name: "Arguments",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 55, offset: 4411},
+							pos:  position{line: 157, col: 55, offset: 4489},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 57, offset: 4413},
+							pos:   position{line: 157, col: 57, offset: 4491},
 							label: "ds",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 156, col: 60, offset: 4416},
+								pos: position{line: 157, col: 60, offset: 4494},
 								expr: &ruleRefExpr{
-									pos:  position{line: 156, col: 60, offset: 4416},
+									pos:  position{line: 157, col: 60, offset: 4494},
 									name: "Directives",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 72, offset: 4428},
+							pos:  position{line: 157, col: 72, offset: 4506},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 74, offset: 4430},
+							pos:   position{line: 157, col: 74, offset: 4508},
 							label: "sels",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 156, col: 79, offset: 4435},
+								pos: position{line: 157, col: 79, offset: 4513},
 								expr: &ruleRefExpr{
-									pos:  position{line: 156, col: 79, offset: 4435},
+									pos:  position{line: 157, col: 79, offset: 4513},
 									name: "SelectionSet",
 								},
 							},
@@ -694,23 +703,23 @@ var g = &grammar{
 		},
 		{
 			name: "FieldAlias",
-			pos:  position{line: 183, col: 1, offset: 4958},
+			pos:  position{line: 184, col: 1, offset: 5036},
 			expr: &actionExpr{
-				pos: position{line: 183, col: 14, offset: 4973},
+				pos: position{line: 184, col: 14, offset: 5051},
 				run: (*parser).callonFieldAlias1,
 				expr: &seqExpr{
-					pos: position{line: 183, col: 14, offset: 4973},
+					pos: position{line: 184, col: 14, offset: 5051},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 183, col: 14, offset: 4973},
+							pos:   position{line: 184, col: 14, offset: 5051},
 							label: "n",
 							expr: &ruleRefExpr{
-								pos:  position{line: 183, col: 16, offset: 4975},
+								pos:  position{line: 184, col: 16, offset: 5053},
 								name: "Name",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 183, col: 21, offset: 4980},
+							pos:        position{line: 184, col: 21, offset: 5058},
 							val:        ":",
 							ignoreCase: false,
 						},
@@ -720,39 +729,39 @@ var g = &grammar{
 		},
 		{
 			name: "FieldName",
-			pos:  position{line: 184, col: 1, offset: 5002},
+			pos:  position{line: 185, col: 1, offset: 5080},
 			expr: &ruleRefExpr{
-				pos:  position{line: 184, col: 13, offset: 5016},
+				pos:  position{line: 185, col: 13, offset: 5094},
 				name: "Name",
 			},
 		},
 		{
 			name: "Arguments",
-			pos:  position{line: 185, col: 1, offset: 5021},
+			pos:  position{line: 186, col: 1, offset: 5099},
 			expr: &actionExpr{
-				pos: position{line: 185, col: 13, offset: 5035},
+				pos: position{line: 186, col: 13, offset: 5113},
 				run: (*parser).callonArguments1,
 				expr: &seqExpr{
-					pos: position{line: 185, col: 13, offset: 5035},
+					pos: position{line: 186, col: 13, offset: 5113},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 185, col: 13, offset: 5035},
+							pos:        position{line: 186, col: 13, offset: 5113},
 							val:        "(",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 185, col: 17, offset: 5039},
+							pos:   position{line: 186, col: 17, offset: 5117},
 							label: "args",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 185, col: 23, offset: 5045},
+								pos: position{line: 186, col: 23, offset: 5123},
 								expr: &ruleRefExpr{
-									pos:  position{line: 185, col: 23, offset: 5045},
+									pos:  position{line: 186, col: 23, offset: 5123},
 									name: "Argument",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 185, col: 34, offset: 5056},
+							pos:        position{line: 186, col: 34, offset: 5134},
 							val:        ")",
 							ignoreCase: false,
 						},
@@ -762,48 +771,48 @@ var g = &grammar{
 		},
 		{
 			name: "Argument",
-			pos:  position{line: 196, col: 1, offset: 5301},
+			pos:  position{line: 197, col: 1, offset: 5379},
 			expr: &actionExpr{
-				pos: position{line: 196, col: 12, offset: 5314},
+				pos: position{line: 197, col: 12, offset: 5392},
 				run: (*parser).callonArgument1,
 				expr: &seqExpr{
-					pos: position{line: 196, col: 12, offset: 5314},
+					pos: position{line: 197, col: 12, offset: 5392},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 196, col: 12, offset: 5314},
+							pos:  position{line: 197, col: 12, offset: 5392},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 196, col: 14, offset: 5316},
+							pos:   position{line: 197, col: 14, offset: 5394},
 							label: "an",
 							expr: &ruleRefExpr{
-								pos:  position{line: 196, col: 17, offset: 5319},
+								pos:  position{line: 197, col: 17, offset: 5397},
 								name: "ArgumentName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 196, col: 30, offset: 5332},
+							pos:  position{line: 197, col: 30, offset: 5410},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 196, col: 32, offset: 5334},
+							pos:        position{line: 197, col: 32, offset: 5412},
 							val:        ":",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 196, col: 36, offset: 5338},
+							pos:  position{line: 197, col: 36, offset: 5416},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 196, col: 38, offset: 5340},
+							pos:   position{line: 197, col: 38, offset: 5418},
 							label: "v",
 							expr: &ruleRefExpr{
-								pos:  position{line: 196, col: 40, offset: 5342},
+								pos:  position{line: 197, col: 40, offset: 5420},
 								name: "Value",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 196, col: 46, offset: 5348},
+							pos:  position{line: 197, col: 46, offset: 5426},
 							name: "_",
 						},
 					},
@@ -812,23 +821,23 @@ var g = &grammar{
 		},
 		{
 			name: "ArgumentName",
-			pos:  position{line: 202, col: 1, offset: 5421},
+			pos:  position{line: 203, col: 1, offset: 5499},
 			expr: &ruleRefExpr{
-				pos:  position{line: 202, col: 16, offset: 5438},
+				pos:  position{line: 203, col: 16, offset: 5516},
 				name: "Name",
 			},
 		},
 		{
 			name: "Name",
-			pos:  position{line: 204, col: 1, offset: 5444},
+			pos:  position{line: 205, col: 1, offset: 5522},
 			expr: &actionExpr{
-				pos: position{line: 204, col: 8, offset: 5453},
+				pos: position{line: 205, col: 8, offset: 5531},
 				run: (*parser).callonName1,
 				expr: &seqExpr{
-					pos: position{line: 204, col: 8, offset: 5453},
+					pos: position{line: 205, col: 8, offset: 5531},
 					exprs: []interface{}{
 						&charClassMatcher{
-							pos:        position{line: 204, col: 8, offset: 5453},
+							pos:        position{line: 205, col: 8, offset: 5531},
 							val:        "[a-z_]i",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z'},
@@ -836,9 +845,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 204, col: 16, offset: 5461},
+							pos: position{line: 205, col: 16, offset: 5539},
 							expr: &charClassMatcher{
-								pos:        position{line: 204, col: 16, offset: 5461},
+								pos:        position{line: 205, col: 16, offset: 5539},
 								val:        "[0-9a-z_]i",
 								chars:      []rune{'_'},
 								ranges:     []rune{'0', '9', 'a', 'z'},
@@ -852,33 +861,33 @@ var g = &grammar{
 		},
 		{
 			name: "FragmentSpread",
-			pos:  position{line: 208, col: 1, offset: 5506},
+			pos:  position{line: 209, col: 1, offset: 5584},
 			expr: &actionExpr{
-				pos: position{line: 208, col: 19, offset: 5526},
+				pos: position{line: 209, col: 19, offset: 5604},
 				run: (*parser).callonFragmentSpread1,
 				expr: &seqExpr{
-					pos: position{line: 208, col: 19, offset: 5526},
+					pos: position{line: 209, col: 19, offset: 5604},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 208, col: 19, offset: 5526},
+							pos:        position{line: 209, col: 19, offset: 5604},
 							val:        "...",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 208, col: 25, offset: 5532},
+							pos:   position{line: 209, col: 25, offset: 5610},
 							label: "fn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 208, col: 28, offset: 5535},
+								pos:  position{line: 209, col: 28, offset: 5613},
 								name: "FragmentName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 208, col: 41, offset: 5548},
+							pos:   position{line: 209, col: 41, offset: 5626},
 							label: "ds",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 208, col: 44, offset: 5551},
+								pos: position{line: 209, col: 44, offset: 5629},
 								expr: &ruleRefExpr{
-									pos:  position{line: 208, col: 44, offset: 5551},
+									pos:  position{line: 209, col: 44, offset: 5629},
 									name: "Directives",
 								},
 							},
@@ -889,68 +898,68 @@ var g = &grammar{
 		},
 		{
 			name: "InlineFragment",
-			pos:  position{line: 219, col: 1, offset: 5751},
+			pos:  position{line: 220, col: 1, offset: 5829},
 			expr: &actionExpr{
-				pos: position{line: 219, col: 19, offset: 5771},
+				pos: position{line: 220, col: 19, offset: 5849},
 				run: (*parser).callonInlineFragment1,
 				expr: &seqExpr{
-					pos: position{line: 219, col: 19, offset: 5771},
+					pos: position{line: 220, col: 19, offset: 5849},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 219, col: 19, offset: 5771},
+							pos:        position{line: 220, col: 19, offset: 5849},
 							val:        "...",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 25, offset: 5777},
+							pos:  position{line: 220, col: 25, offset: 5855},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 219, col: 27, offset: 5779},
+							pos:        position{line: 220, col: 27, offset: 5857},
 							val:        "on",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 32, offset: 5784},
+							pos:  position{line: 220, col: 32, offset: 5862},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 219, col: 34, offset: 5786},
+							pos:   position{line: 220, col: 34, offset: 5864},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 219, col: 37, offset: 5789},
+								pos:  position{line: 220, col: 37, offset: 5867},
 								name: "TypeName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 46, offset: 5798},
+							pos:  position{line: 220, col: 46, offset: 5876},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 219, col: 48, offset: 5800},
+							pos:   position{line: 220, col: 48, offset: 5878},
 							label: "ds",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 219, col: 51, offset: 5803},
+								pos: position{line: 220, col: 51, offset: 5881},
 								expr: &ruleRefExpr{
-									pos:  position{line: 219, col: 51, offset: 5803},
+									pos:  position{line: 220, col: 51, offset: 5881},
 									name: "Directives",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 63, offset: 5815},
+							pos:  position{line: 220, col: 63, offset: 5893},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 219, col: 65, offset: 5817},
+							pos:   position{line: 220, col: 65, offset: 5895},
 							label: "sels",
 							expr: &ruleRefExpr{
-								pos:  position{line: 219, col: 70, offset: 5822},
+								pos:  position{line: 220, col: 70, offset: 5900},
 								name: "SelectionSet",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 83, offset: 5835},
+							pos:  position{line: 220, col: 83, offset: 5913},
 							name: "_",
 						},
 					},
@@ -959,80 +968,80 @@ var g = &grammar{
 		},
 		{
 			name: "FragmentDefinition",
-			pos:  position{line: 231, col: 1, offset: 6079},
+			pos:  position{line: 232, col: 1, offset: 6157},
 			expr: &actionExpr{
-				pos: position{line: 231, col: 22, offset: 6102},
+				pos: position{line: 232, col: 22, offset: 6180},
 				run: (*parser).callonFragmentDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 231, col: 22, offset: 6102},
+					pos: position{line: 232, col: 22, offset: 6180},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 231, col: 22, offset: 6102},
+							pos:        position{line: 232, col: 22, offset: 6180},
 							val:        "fragment",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 33, offset: 6113},
+							pos:  position{line: 232, col: 33, offset: 6191},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 35, offset: 6115},
+							pos:   position{line: 232, col: 35, offset: 6193},
 							label: "fn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 231, col: 38, offset: 6118},
+								pos:  position{line: 232, col: 38, offset: 6196},
 								name: "FragmentName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 51, offset: 6131},
+							pos:  position{line: 232, col: 51, offset: 6209},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 231, col: 53, offset: 6133},
+							pos:        position{line: 232, col: 53, offset: 6211},
 							val:        "on",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 58, offset: 6138},
+							pos:  position{line: 232, col: 58, offset: 6216},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 60, offset: 6140},
+							pos:   position{line: 232, col: 60, offset: 6218},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 231, col: 63, offset: 6143},
+								pos:  position{line: 232, col: 63, offset: 6221},
 								name: "TypeName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 73, offset: 6153},
+							pos:  position{line: 232, col: 73, offset: 6231},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 75, offset: 6155},
+							pos:   position{line: 232, col: 75, offset: 6233},
 							label: "ds",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 231, col: 78, offset: 6158},
+								pos: position{line: 232, col: 78, offset: 6236},
 								expr: &ruleRefExpr{
-									pos:  position{line: 231, col: 78, offset: 6158},
+									pos:  position{line: 232, col: 78, offset: 6236},
 									name: "Directives",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 90, offset: 6170},
+							pos:  position{line: 232, col: 90, offset: 6248},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 92, offset: 6172},
+							pos:   position{line: 232, col: 92, offset: 6250},
 							label: "sels",
 							expr: &ruleRefExpr{
-								pos:  position{line: 231, col: 97, offset: 6177},
+								pos:  position{line: 232, col: 97, offset: 6255},
 								name: "SelectionSet",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 110, offset: 6190},
+							pos:  position{line: 232, col: 110, offset: 6268},
 							name: "_",
 						},
 					},
@@ -1041,15 +1050,15 @@ var g = &grammar{
 		},
 		{
 			name: "FragmentName",
-			pos:  position{line: 243, col: 1, offset: 6457},
+			pos:  position{line: 244, col: 1, offset: 6535},
 			expr: &actionExpr{
-				pos: position{line: 243, col: 16, offset: 6474},
+				pos: position{line: 244, col: 16, offset: 6552},
 				run: (*parser).callonFragmentName1,
 				expr: &labeledExpr{
-					pos:   position{line: 243, col: 16, offset: 6474},
+					pos:   position{line: 244, col: 16, offset: 6552},
 					label: "n",
 					expr: &ruleRefExpr{
-						pos:  position{line: 243, col: 18, offset: 6476},
+						pos:  position{line: 244, col: 18, offset: 6554},
 						name: "Name",
 					},
 				},
@@ -1057,64 +1066,64 @@ var g = &grammar{
 		},
 		{
 			name: "Value",
-			pos:  position{line: 245, col: 1, offset: 6500},
+			pos:  position{line: 246, col: 1, offset: 6578},
 			expr: &actionExpr{
-				pos: position{line: 245, col: 9, offset: 6510},
+				pos: position{line: 246, col: 9, offset: 6588},
 				run: (*parser).callonValue1,
 				expr: &seqExpr{
-					pos: position{line: 245, col: 9, offset: 6510},
+					pos: position{line: 246, col: 9, offset: 6588},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 245, col: 9, offset: 6510},
+							pos:  position{line: 246, col: 9, offset: 6588},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 245, col: 11, offset: 6512},
+							pos:   position{line: 246, col: 11, offset: 6590},
 							label: "v",
 							expr: &choiceExpr{
-								pos: position{line: 245, col: 14, offset: 6515},
+								pos: position{line: 246, col: 14, offset: 6593},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 14, offset: 6515},
+										pos:  position{line: 246, col: 14, offset: 6593},
 										name: "Null",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 21, offset: 6522},
+										pos:  position{line: 246, col: 21, offset: 6600},
 										name: "Boolean",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 31, offset: 6532},
-										name: "Int",
+										pos:  position{line: 246, col: 31, offset: 6610},
+										name: "Float",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 37, offset: 6538},
-										name: "Float",
+										pos:  position{line: 246, col: 39, offset: 6618},
+										name: "Int",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 45, offset: 6546},
+										pos:  position{line: 246, col: 45, offset: 6624},
 										name: "String",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 54, offset: 6555},
+										pos:  position{line: 246, col: 54, offset: 6633},
 										name: "EnumValue",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 66, offset: 6567},
+										pos:  position{line: 246, col: 66, offset: 6645},
 										name: "Array",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 74, offset: 6575},
+										pos:  position{line: 246, col: 74, offset: 6653},
 										name: "Object",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 83, offset: 6584},
+										pos:  position{line: 246, col: 83, offset: 6662},
 										name: "Variable",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 245, col: 93, offset: 6594},
+							pos:  position{line: 246, col: 93, offset: 6672},
 							name: "_",
 						},
 					},
@@ -1123,12 +1132,12 @@ var g = &grammar{
 		},
 		{
 			name: "Null",
-			pos:  position{line: 249, col: 1, offset: 6616},
+			pos:  position{line: 250, col: 1, offset: 6694},
 			expr: &actionExpr{
-				pos: position{line: 249, col: 8, offset: 6625},
+				pos: position{line: 250, col: 8, offset: 6703},
 				run: (*parser).callonNull1,
 				expr: &litMatcher{
-					pos:        position{line: 249, col: 8, offset: 6625},
+					pos:        position{line: 250, col: 8, offset: 6703},
 					val:        "null",
 					ignoreCase: false,
 				},
@@ -1136,24 +1145,24 @@ var g = &grammar{
 		},
 		{
 			name: "Boolean",
-			pos:  position{line: 250, col: 1, offset: 6652},
+			pos:  position{line: 251, col: 1, offset: 6730},
 			expr: &choiceExpr{
-				pos: position{line: 250, col: 11, offset: 6664},
+				pos: position{line: 251, col: 11, offset: 6742},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 250, col: 11, offset: 6664},
+						pos: position{line: 251, col: 11, offset: 6742},
 						run: (*parser).callonBoolean2,
 						expr: &litMatcher{
-							pos:        position{line: 250, col: 11, offset: 6664},
+							pos:        position{line: 251, col: 11, offset: 6742},
 							val:        "true",
 							ignoreCase: false,
 						},
 					},
 					&actionExpr{
-						pos: position{line: 250, col: 41, offset: 6694},
+						pos: position{line: 251, col: 41, offset: 6772},
 						run: (*parser).callonBoolean4,
 						expr: &litMatcher{
-							pos:        position{line: 250, col: 41, offset: 6694},
+							pos:        position{line: 251, col: 41, offset: 6772},
 							val:        "false",
 							ignoreCase: false,
 						},
@@ -1163,22 +1172,22 @@ var g = &grammar{
 		},
 		{
 			name: "Int",
-			pos:  position{line: 251, col: 1, offset: 6724},
+			pos:  position{line: 252, col: 1, offset: 6802},
 			expr: &actionExpr{
-				pos: position{line: 251, col: 7, offset: 6732},
+				pos: position{line: 252, col: 7, offset: 6810},
 				run: (*parser).callonInt1,
 				expr: &seqExpr{
-					pos: position{line: 251, col: 7, offset: 6732},
+					pos: position{line: 252, col: 7, offset: 6810},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 251, col: 7, offset: 6732},
+							pos: position{line: 252, col: 7, offset: 6810},
 							expr: &ruleRefExpr{
-								pos:  position{line: 251, col: 7, offset: 6732},
+								pos:  position{line: 252, col: 7, offset: 6810},
 								name: "Sign",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 251, col: 13, offset: 6738},
+							pos:  position{line: 252, col: 13, offset: 6816},
 							name: "IntegerPart",
 						},
 					},
@@ -1187,40 +1196,40 @@ var g = &grammar{
 		},
 		{
 			name: "Float",
-			pos:  position{line: 254, col: 1, offset: 6791},
+			pos:  position{line: 255, col: 1, offset: 6869},
 			expr: &actionExpr{
-				pos: position{line: 254, col: 9, offset: 6801},
+				pos: position{line: 255, col: 9, offset: 6879},
 				run: (*parser).callonFloat1,
 				expr: &seqExpr{
-					pos: position{line: 254, col: 9, offset: 6801},
+					pos: position{line: 255, col: 9, offset: 6879},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 254, col: 9, offset: 6801},
+							pos: position{line: 255, col: 9, offset: 6879},
 							expr: &ruleRefExpr{
-								pos:  position{line: 254, col: 9, offset: 6801},
+								pos:  position{line: 255, col: 9, offset: 6879},
 								name: "Sign",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 254, col: 15, offset: 6807},
+							pos:  position{line: 255, col: 15, offset: 6885},
 							name: "IntegerPart",
 						},
 						&litMatcher{
-							pos:        position{line: 254, col: 27, offset: 6819},
+							pos:        position{line: 255, col: 27, offset: 6897},
 							val:        ".",
 							ignoreCase: false,
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 254, col: 31, offset: 6823},
+							pos: position{line: 255, col: 31, offset: 6901},
 							expr: &ruleRefExpr{
-								pos:  position{line: 254, col: 31, offset: 6823},
+								pos:  position{line: 255, col: 31, offset: 6901},
 								name: "Digit",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 254, col: 38, offset: 6830},
+							pos: position{line: 255, col: 38, offset: 6908},
 							expr: &ruleRefExpr{
-								pos:  position{line: 254, col: 38, offset: 6830},
+								pos:  position{line: 255, col: 38, offset: 6908},
 								name: "ExponentPart",
 							},
 						},
@@ -1230,35 +1239,35 @@ var g = &grammar{
 		},
 		{
 			name: "Sign",
-			pos:  position{line: 257, col: 1, offset: 6895},
+			pos:  position{line: 258, col: 1, offset: 6973},
 			expr: &litMatcher{
-				pos:        position{line: 257, col: 8, offset: 6904},
+				pos:        position{line: 258, col: 8, offset: 6982},
 				val:        "-",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "IntegerPart",
-			pos:  position{line: 258, col: 1, offset: 6908},
+			pos:  position{line: 259, col: 1, offset: 6986},
 			expr: &choiceExpr{
-				pos: position{line: 258, col: 15, offset: 6924},
+				pos: position{line: 259, col: 15, offset: 7002},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 258, col: 15, offset: 6924},
+						pos:        position{line: 259, col: 15, offset: 7002},
 						val:        "0",
 						ignoreCase: false,
 					},
 					&seqExpr{
-						pos: position{line: 258, col: 21, offset: 6930},
+						pos: position{line: 259, col: 21, offset: 7008},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 258, col: 21, offset: 6930},
+								pos:  position{line: 259, col: 21, offset: 7008},
 								name: "NonZeroDigit",
 							},
 							&zeroOrMoreExpr{
-								pos: position{line: 258, col: 34, offset: 6943},
+								pos: position{line: 259, col: 34, offset: 7021},
 								expr: &ruleRefExpr{
-									pos:  position{line: 258, col: 34, offset: 6943},
+									pos:  position{line: 259, col: 34, offset: 7021},
 									name: "Digit",
 								},
 							},
@@ -1269,26 +1278,26 @@ var g = &grammar{
 		},
 		{
 			name: "ExponentPart",
-			pos:  position{line: 259, col: 1, offset: 6950},
+			pos:  position{line: 260, col: 1, offset: 7028},
 			expr: &seqExpr{
-				pos: position{line: 259, col: 16, offset: 6967},
+				pos: position{line: 260, col: 16, offset: 7045},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 259, col: 16, offset: 6967},
+						pos:        position{line: 260, col: 16, offset: 7045},
 						val:        "e",
 						ignoreCase: false,
 					},
 					&zeroOrOneExpr{
-						pos: position{line: 259, col: 20, offset: 6971},
+						pos: position{line: 260, col: 20, offset: 7049},
 						expr: &ruleRefExpr{
-							pos:  position{line: 259, col: 20, offset: 6971},
+							pos:  position{line: 260, col: 20, offset: 7049},
 							name: "Sign",
 						},
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 259, col: 26, offset: 6977},
+						pos: position{line: 260, col: 26, offset: 7055},
 						expr: &ruleRefExpr{
-							pos:  position{line: 259, col: 26, offset: 6977},
+							pos:  position{line: 260, col: 26, offset: 7055},
 							name: "Digit",
 						},
 					},
@@ -1297,9 +1306,9 @@ var g = &grammar{
 		},
 		{
 			name: "Digit",
-			pos:  position{line: 260, col: 1, offset: 6984},
+			pos:  position{line: 261, col: 1, offset: 7062},
 			expr: &charClassMatcher{
-				pos:        position{line: 260, col: 9, offset: 6994},
+				pos:        position{line: 261, col: 9, offset: 7072},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -1308,9 +1317,9 @@ var g = &grammar{
 		},
 		{
 			name: "NonZeroDigit",
-			pos:  position{line: 261, col: 1, offset: 7000},
+			pos:  position{line: 262, col: 1, offset: 7078},
 			expr: &charClassMatcher{
-				pos:        position{line: 261, col: 16, offset: 7017},
+				pos:        position{line: 262, col: 16, offset: 7095},
 				val:        "[123456789]",
 				chars:      []rune{'1', '2', '3', '4', '5', '6', '7', '8', '9'},
 				ignoreCase: false,
@@ -1319,28 +1328,28 @@ var g = &grammar{
 		},
 		{
 			name: "String",
-			pos:  position{line: 262, col: 1, offset: 7029},
+			pos:  position{line: 263, col: 1, offset: 7107},
 			expr: &actionExpr{
-				pos: position{line: 262, col: 10, offset: 7040},
+				pos: position{line: 263, col: 10, offset: 7118},
 				run: (*parser).callonString1,
 				expr: &seqExpr{
-					pos: position{line: 262, col: 10, offset: 7040},
+					pos: position{line: 263, col: 10, offset: 7118},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 262, col: 10, offset: 7040},
+							pos:        position{line: 263, col: 10, offset: 7118},
 							val:        "\"",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 262, col: 14, offset: 7044},
+							pos:   position{line: 263, col: 14, offset: 7122},
 							label: "s",
 							expr: &ruleRefExpr{
-								pos:  position{line: 262, col: 16, offset: 7046},
+								pos:  position{line: 263, col: 16, offset: 7124},
 								name: "string",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 262, col: 23, offset: 7053},
+							pos:        position{line: 263, col: 23, offset: 7131},
 							val:        "\"",
 							ignoreCase: false,
 						},
@@ -1350,14 +1359,14 @@ var g = &grammar{
 		},
 		{
 			name: "string",
-			pos:  position{line: 265, col: 1, offset: 7085},
+			pos:  position{line: 266, col: 1, offset: 7163},
 			expr: &actionExpr{
-				pos: position{line: 265, col: 10, offset: 7096},
+				pos: position{line: 266, col: 10, offset: 7174},
 				run: (*parser).callonstring1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 265, col: 10, offset: 7096},
+					pos: position{line: 266, col: 10, offset: 7174},
 					expr: &ruleRefExpr{
-						pos:  position{line: 265, col: 10, offset: 7096},
+						pos:  position{line: 266, col: 10, offset: 7174},
 						name: "StringCharacter",
 					},
 				},
@@ -1365,41 +1374,41 @@ var g = &grammar{
 		},
 		{
 			name: "StringCharacter",
-			pos:  position{line: 268, col: 1, offset: 7145},
+			pos:  position{line: 269, col: 1, offset: 7223},
 			expr: &choiceExpr{
-				pos: position{line: 268, col: 19, offset: 7165},
+				pos: position{line: 269, col: 19, offset: 7243},
 				alternatives: []interface{}{
 					&charClassMatcher{
-						pos:        position{line: 268, col: 19, offset: 7165},
+						pos:        position{line: 269, col: 19, offset: 7243},
 						val:        "[^\\\\\"]",
 						chars:      []rune{'\\', '"'},
 						ignoreCase: false,
 						inverted:   true,
 					},
 					&seqExpr{
-						pos: position{line: 268, col: 28, offset: 7174},
+						pos: position{line: 269, col: 28, offset: 7252},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 268, col: 28, offset: 7174},
+								pos:        position{line: 269, col: 28, offset: 7252},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 268, col: 33, offset: 7179},
+								pos:  position{line: 269, col: 33, offset: 7257},
 								name: "EscapedCharacter",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 268, col: 52, offset: 7198},
+						pos: position{line: 269, col: 52, offset: 7276},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 268, col: 52, offset: 7198},
+								pos:        position{line: 269, col: 52, offset: 7276},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 268, col: 57, offset: 7203},
+								pos:  position{line: 269, col: 57, offset: 7281},
 								name: "EscapedUnicode",
 							},
 						},
@@ -1409,38 +1418,38 @@ var g = &grammar{
 		},
 		{
 			name: "EscapedUnicode",
-			pos:  position{line: 269, col: 1, offset: 7218},
+			pos:  position{line: 270, col: 1, offset: 7296},
 			expr: &seqExpr{
-				pos: position{line: 269, col: 18, offset: 7237},
+				pos: position{line: 270, col: 18, offset: 7315},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 269, col: 18, offset: 7237},
+						pos:        position{line: 270, col: 18, offset: 7315},
 						val:        "u",
 						ignoreCase: false,
 					},
 					&charClassMatcher{
-						pos:        position{line: 269, col: 22, offset: 7241},
+						pos:        position{line: 270, col: 22, offset: 7319},
 						val:        "[0-9a-f]i",
 						ranges:     []rune{'0', '9', 'a', 'f'},
 						ignoreCase: true,
 						inverted:   false,
 					},
 					&charClassMatcher{
-						pos:        position{line: 269, col: 32, offset: 7251},
+						pos:        position{line: 270, col: 32, offset: 7329},
 						val:        "[0-9a-f]i",
 						ranges:     []rune{'0', '9', 'a', 'f'},
 						ignoreCase: true,
 						inverted:   false,
 					},
 					&charClassMatcher{
-						pos:        position{line: 269, col: 42, offset: 7261},
+						pos:        position{line: 270, col: 42, offset: 7339},
 						val:        "[0-9a-f]i",
 						ranges:     []rune{'0', '9', 'a', 'f'},
 						ignoreCase: true,
 						inverted:   false,
 					},
 					&charClassMatcher{
-						pos:        position{line: 269, col: 52, offset: 7271},
+						pos:        position{line: 270, col: 52, offset: 7349},
 						val:        "[0-9a-f]i",
 						ranges:     []rune{'0', '9', 'a', 'f'},
 						ignoreCase: true,
@@ -1451,19 +1460,19 @@ var g = &grammar{
 		},
 		{
 			name: "EscapedCharacter",
-			pos:  position{line: 270, col: 1, offset: 7281},
+			pos:  position{line: 271, col: 1, offset: 7359},
 			expr: &choiceExpr{
-				pos: position{line: 270, col: 20, offset: 7302},
+				pos: position{line: 271, col: 20, offset: 7380},
 				alternatives: []interface{}{
 					&charClassMatcher{
-						pos:        position{line: 270, col: 20, offset: 7302},
+						pos:        position{line: 271, col: 20, offset: 7380},
 						val:        "[\"/bfnrt]",
 						chars:      []rune{'"', '/', 'b', 'f', 'n', 'r', 't'},
 						ignoreCase: false,
 						inverted:   false,
 					},
 					&litMatcher{
-						pos:        position{line: 270, col: 32, offset: 7314},
+						pos:        position{line: 271, col: 32, offset: 7392},
 						val:        "\\",
 						ignoreCase: false,
 					},
@@ -1472,31 +1481,31 @@ var g = &grammar{
 		},
 		{
 			name: "EnumValue",
-			pos:  position{line: 272, col: 1, offset: 7320},
+			pos:  position{line: 273, col: 1, offset: 7398},
 			expr: &actionExpr{
-				pos: position{line: 272, col: 13, offset: 7334},
+				pos: position{line: 273, col: 13, offset: 7412},
 				run: (*parser).callonEnumValue1,
 				expr: &seqExpr{
-					pos: position{line: 272, col: 13, offset: 7334},
+					pos: position{line: 273, col: 13, offset: 7412},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 272, col: 13, offset: 7334},
+							pos:   position{line: 273, col: 13, offset: 7412},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 272, col: 16, offset: 7337},
+								pos:  position{line: 273, col: 16, offset: 7415},
 								name: "TypeName",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 272, col: 25, offset: 7346},
+							pos:        position{line: 273, col: 25, offset: 7424},
 							val:        ".",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 272, col: 29, offset: 7350},
+							pos:   position{line: 273, col: 29, offset: 7428},
 							label: "v",
 							expr: &ruleRefExpr{
-								pos:  position{line: 272, col: 31, offset: 7352},
+								pos:  position{line: 273, col: 31, offset: 7430},
 								name: "EnumValueName",
 							},
 						},
@@ -1506,31 +1515,31 @@ var g = &grammar{
 		},
 		{
 			name: "Array",
-			pos:  position{line: 279, col: 1, offset: 7457},
+			pos:  position{line: 280, col: 1, offset: 7535},
 			expr: &actionExpr{
-				pos: position{line: 279, col: 9, offset: 7467},
+				pos: position{line: 280, col: 9, offset: 7545},
 				run: (*parser).callonArray1,
 				expr: &seqExpr{
-					pos: position{line: 279, col: 9, offset: 7467},
+					pos: position{line: 280, col: 9, offset: 7545},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 279, col: 9, offset: 7467},
+							pos:        position{line: 280, col: 9, offset: 7545},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 279, col: 13, offset: 7471},
+							pos:   position{line: 280, col: 13, offset: 7549},
 							label: "values",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 279, col: 20, offset: 7478},
+								pos: position{line: 280, col: 20, offset: 7556},
 								expr: &ruleRefExpr{
-									pos:  position{line: 279, col: 20, offset: 7478},
+									pos:  position{line: 280, col: 20, offset: 7556},
 									name: "Value",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 279, col: 27, offset: 7485},
+							pos:        position{line: 280, col: 27, offset: 7563},
 							val:        "]",
 							ignoreCase: false,
 						},
@@ -1540,31 +1549,31 @@ var g = &grammar{
 		},
 		{
 			name: "Object",
-			pos:  position{line: 288, col: 1, offset: 7646},
+			pos:  position{line: 289, col: 1, offset: 7724},
 			expr: &actionExpr{
-				pos: position{line: 288, col: 10, offset: 7657},
+				pos: position{line: 289, col: 10, offset: 7735},
 				run: (*parser).callonObject1,
 				expr: &seqExpr{
-					pos: position{line: 288, col: 10, offset: 7657},
+					pos: position{line: 289, col: 10, offset: 7735},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 288, col: 10, offset: 7657},
+							pos:        position{line: 289, col: 10, offset: 7735},
 							val:        "{",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 288, col: 14, offset: 7661},
+							pos:   position{line: 289, col: 14, offset: 7739},
 							label: "ps",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 288, col: 17, offset: 7664},
+								pos: position{line: 289, col: 17, offset: 7742},
 								expr: &ruleRefExpr{
-									pos:  position{line: 288, col: 17, offset: 7664},
+									pos:  position{line: 289, col: 17, offset: 7742},
 									name: "Property",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 288, col: 27, offset: 7674},
+							pos:        position{line: 289, col: 27, offset: 7752},
 							val:        "}",
 							ignoreCase: false,
 						},
@@ -1574,34 +1583,34 @@ var g = &grammar{
 		},
 		{
 			name: "Variable",
-			pos:  position{line: 300, col: 1, offset: 7927},
+			pos:  position{line: 301, col: 1, offset: 8005},
 			expr: &choiceExpr{
-				pos: position{line: 300, col: 12, offset: 7940},
+				pos: position{line: 301, col: 12, offset: 8018},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 300, col: 12, offset: 7940},
+						pos: position{line: 301, col: 12, offset: 8018},
 						run: (*parser).callonVariable2,
 						expr: &seqExpr{
-							pos: position{line: 300, col: 12, offset: 7940},
+							pos: position{line: 301, col: 12, offset: 8018},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 300, col: 12, offset: 7940},
+									pos:   position{line: 301, col: 12, offset: 8018},
 									label: "vn",
 									expr: &ruleRefExpr{
-										pos:  position{line: 300, col: 15, offset: 7943},
+										pos:  position{line: 301, col: 15, offset: 8021},
 										name: "VariableName",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 300, col: 28, offset: 7956},
+									pos:        position{line: 301, col: 28, offset: 8034},
 									val:        ".",
 									ignoreCase: false,
 								},
 								&labeledExpr{
-									pos:   position{line: 300, col: 32, offset: 7960},
+									pos:   position{line: 301, col: 32, offset: 8038},
 									label: "pn",
 									expr: &ruleRefExpr{
-										pos:  position{line: 300, col: 35, offset: 7963},
+										pos:  position{line: 301, col: 35, offset: 8041},
 										name: "PropertyName",
 									},
 								},
@@ -1609,13 +1618,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 305, col: 5, offset: 8096},
+						pos: position{line: 306, col: 5, offset: 8174},
 						run: (*parser).callonVariable9,
 						expr: &labeledExpr{
-							pos:   position{line: 305, col: 5, offset: 8096},
+							pos:   position{line: 306, col: 5, offset: 8174},
 							label: "vn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 305, col: 8, offset: 8099},
+								pos:  position{line: 306, col: 8, offset: 8177},
 								name: "VariableName",
 							},
 						},
@@ -1625,22 +1634,22 @@ var g = &grammar{
 		},
 		{
 			name: "VariableName",
-			pos:  position{line: 310, col: 1, offset: 8172},
+			pos:  position{line: 311, col: 1, offset: 8250},
 			expr: &actionExpr{
-				pos: position{line: 310, col: 16, offset: 8189},
+				pos: position{line: 311, col: 16, offset: 8267},
 				run: (*parser).callonVariableName1,
 				expr: &seqExpr{
-					pos: position{line: 310, col: 16, offset: 8189},
+					pos: position{line: 311, col: 16, offset: 8267},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 310, col: 16, offset: 8189},
+							pos:        position{line: 311, col: 16, offset: 8267},
 							val:        "$",
 							ignoreCase: false,
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 310, col: 20, offset: 8193},
+							pos: position{line: 311, col: 20, offset: 8271},
 							expr: &charClassMatcher{
-								pos:        position{line: 310, col: 20, offset: 8193},
+								pos:        position{line: 311, col: 20, offset: 8271},
 								val:        "[0-9a-z_]i",
 								chars:      []rune{'_'},
 								ranges:     []rune{'0', '9', 'a', 'z'},
@@ -1654,31 +1663,31 @@ var g = &grammar{
 		},
 		{
 			name: "Property",
-			pos:  position{line: 318, col: 1, offset: 8516},
+			pos:  position{line: 319, col: 1, offset: 8594},
 			expr: &actionExpr{
-				pos: position{line: 318, col: 12, offset: 8529},
+				pos: position{line: 319, col: 12, offset: 8607},
 				run: (*parser).callonProperty1,
 				expr: &seqExpr{
-					pos: position{line: 318, col: 12, offset: 8529},
+					pos: position{line: 319, col: 12, offset: 8607},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 318, col: 12, offset: 8529},
+							pos:   position{line: 319, col: 12, offset: 8607},
 							label: "pn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 318, col: 15, offset: 8532},
+								pos:  position{line: 319, col: 15, offset: 8610},
 								name: "PropertyName",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 318, col: 28, offset: 8545},
+							pos:        position{line: 319, col: 28, offset: 8623},
 							val:        ":",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 318, col: 32, offset: 8549},
+							pos:   position{line: 319, col: 32, offset: 8627},
 							label: "v",
 							expr: &ruleRefExpr{
-								pos:  position{line: 318, col: 34, offset: 8551},
+								pos:  position{line: 319, col: 34, offset: 8629},
 								name: "Value",
 							},
 						},
@@ -1688,29 +1697,29 @@ var g = &grammar{
 		},
 		{
 			name: "PropertyName",
-			pos:  position{line: 321, col: 1, offset: 8621},
+			pos:  position{line: 322, col: 1, offset: 8699},
 			expr: &actionExpr{
-				pos: position{line: 321, col: 16, offset: 8638},
+				pos: position{line: 322, col: 16, offset: 8716},
 				run: (*parser).callonPropertyName1,
 				expr: &ruleRefExpr{
-					pos:  position{line: 321, col: 16, offset: 8638},
+					pos:  position{line: 322, col: 16, offset: 8716},
 					name: "Name",
 				},
 			},
 		},
 		{
 			name: "Directives",
-			pos:  position{line: 323, col: 1, offset: 8674},
+			pos:  position{line: 324, col: 1, offset: 8752},
 			expr: &actionExpr{
-				pos: position{line: 323, col: 14, offset: 8689},
+				pos: position{line: 324, col: 14, offset: 8767},
 				run: (*parser).callonDirectives1,
 				expr: &labeledExpr{
-					pos:   position{line: 323, col: 14, offset: 8689},
+					pos:   position{line: 324, col: 14, offset: 8767},
 					label: "ds",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 323, col: 17, offset: 8692},
+						pos: position{line: 324, col: 17, offset: 8770},
 						expr: &ruleRefExpr{
-							pos:  position{line: 323, col: 17, offset: 8692},
+							pos:  position{line: 324, col: 17, offset: 8770},
 							name: "Directive",
 						},
 					},
@@ -1719,52 +1728,52 @@ var g = &grammar{
 		},
 		{
 			name: "Directive",
-			pos:  position{line: 330, col: 1, offset: 8841},
+			pos:  position{line: 331, col: 1, offset: 8919},
 			expr: &actionExpr{
-				pos: position{line: 330, col: 13, offset: 8855},
+				pos: position{line: 331, col: 13, offset: 8933},
 				run: (*parser).callonDirective1,
 				expr: &seqExpr{
-					pos: position{line: 330, col: 13, offset: 8855},
+					pos: position{line: 331, col: 13, offset: 8933},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 330, col: 13, offset: 8855},
+							pos:        position{line: 331, col: 13, offset: 8933},
 							val:        "@",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 330, col: 17, offset: 8859},
+							pos:   position{line: 331, col: 17, offset: 8937},
 							label: "d",
 							expr: &choiceExpr{
-								pos: position{line: 330, col: 20, offset: 8862},
+								pos: position{line: 331, col: 20, offset: 8940},
 								alternatives: []interface{}{
 									&actionExpr{
-										pos: position{line: 330, col: 20, offset: 8862},
+										pos: position{line: 331, col: 20, offset: 8940},
 										run: (*parser).callonDirective6,
 										expr: &seqExpr{
-											pos: position{line: 330, col: 21, offset: 8863},
+											pos: position{line: 331, col: 21, offset: 8941},
 											exprs: []interface{}{
 												&labeledExpr{
-													pos:   position{line: 330, col: 21, offset: 8863},
+													pos:   position{line: 331, col: 21, offset: 8941},
 													label: "dn",
 													expr: &ruleRefExpr{
-														pos:  position{line: 330, col: 24, offset: 8866},
+														pos:  position{line: 331, col: 24, offset: 8944},
 														name: "DirectiveName",
 													},
 												},
 												&litMatcher{
-													pos:        position{line: 330, col: 38, offset: 8880},
+													pos:        position{line: 331, col: 38, offset: 8958},
 													val:        ":",
 													ignoreCase: false,
 												},
 												&ruleRefExpr{
-													pos:  position{line: 330, col: 42, offset: 8884},
+													pos:  position{line: 331, col: 42, offset: 8962},
 													name: "_",
 												},
 												&labeledExpr{
-													pos:   position{line: 330, col: 44, offset: 8886},
+													pos:   position{line: 331, col: 44, offset: 8964},
 													label: "v",
 													expr: &ruleRefExpr{
-														pos:  position{line: 330, col: 46, offset: 8888},
+														pos:  position{line: 331, col: 46, offset: 8966},
 														name: "Value",
 													},
 												},
@@ -1772,33 +1781,33 @@ var g = &grammar{
 										},
 									},
 									&actionExpr{
-										pos: position{line: 336, col: 5, offset: 8998},
+										pos: position{line: 337, col: 5, offset: 9076},
 										run: (*parser).callonDirective14,
 										expr: &seqExpr{
-											pos: position{line: 336, col: 6, offset: 8999},
+											pos: position{line: 337, col: 6, offset: 9077},
 											exprs: []interface{}{
 												&labeledExpr{
-													pos:   position{line: 336, col: 6, offset: 8999},
+													pos:   position{line: 337, col: 6, offset: 9077},
 													label: "dn",
 													expr: &ruleRefExpr{
-														pos:  position{line: 336, col: 9, offset: 9002},
+														pos:  position{line: 337, col: 9, offset: 9080},
 														name: "DirectiveName",
 													},
 												},
 												&litMatcher{
-													pos:        position{line: 336, col: 23, offset: 9016},
+													pos:        position{line: 337, col: 23, offset: 9094},
 													val:        ":",
 													ignoreCase: false,
 												},
 												&ruleRefExpr{
-													pos:  position{line: 336, col: 27, offset: 9020},
+													pos:  position{line: 337, col: 27, offset: 9098},
 													name: "_",
 												},
 												&labeledExpr{
-													pos:   position{line: 336, col: 29, offset: 9022},
+													pos:   position{line: 337, col: 29, offset: 9100},
 													label: "t",
 													expr: &ruleRefExpr{
-														pos:  position{line: 336, col: 31, offset: 9024},
+														pos:  position{line: 337, col: 31, offset: 9102},
 														name: "Type",
 													},
 												},
@@ -1806,13 +1815,13 @@ var g = &grammar{
 										},
 									},
 									&actionExpr{
-										pos: position{line: 342, col: 5, offset: 9133},
+										pos: position{line: 343, col: 5, offset: 9211},
 										run: (*parser).callonDirective22,
 										expr: &labeledExpr{
-											pos:   position{line: 342, col: 5, offset: 9133},
+											pos:   position{line: 343, col: 5, offset: 9211},
 											label: "dn",
 											expr: &ruleRefExpr{
-												pos:  position{line: 342, col: 8, offset: 9136},
+												pos:  position{line: 343, col: 8, offset: 9214},
 												name: "DirectiveName",
 											},
 										},
@@ -1821,7 +1830,7 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 346, col: 5, offset: 9212},
+							pos:  position{line: 347, col: 5, offset: 9290},
 							name: "_",
 						},
 					},
@@ -1830,30 +1839,30 @@ var g = &grammar{
 		},
 		{
 			name: "DirectiveName",
-			pos:  position{line: 350, col: 1, offset: 9234},
+			pos:  position{line: 351, col: 1, offset: 9312},
 			expr: &ruleRefExpr{
-				pos:  position{line: 350, col: 17, offset: 9252},
+				pos:  position{line: 351, col: 17, offset: 9330},
 				name: "Name",
 			},
 		},
 		{
 			name: "Type",
-			pos:  position{line: 352, col: 1, offset: 9258},
+			pos:  position{line: 353, col: 1, offset: 9336},
 			expr: &actionExpr{
-				pos: position{line: 352, col: 8, offset: 9267},
+				pos: position{line: 353, col: 8, offset: 9345},
 				run: (*parser).callonType1,
 				expr: &labeledExpr{
-					pos:   position{line: 352, col: 8, offset: 9267},
+					pos:   position{line: 353, col: 8, offset: 9345},
 					label: "t",
 					expr: &choiceExpr{
-						pos: position{line: 352, col: 11, offset: 9270},
+						pos: position{line: 353, col: 11, offset: 9348},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 352, col: 11, offset: 9270},
+								pos:  position{line: 353, col: 11, offset: 9348},
 								name: "OptionalType",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 352, col: 26, offset: 9285},
+								pos:  position{line: 353, col: 26, offset: 9363},
 								name: "GenericType",
 							},
 						},
@@ -1863,23 +1872,23 @@ var g = &grammar{
 		},
 		{
 			name: "OptionalType",
-			pos:  position{line: 353, col: 1, offset: 9316},
+			pos:  position{line: 354, col: 1, offset: 9394},
 			expr: &actionExpr{
-				pos: position{line: 353, col: 16, offset: 9333},
+				pos: position{line: 354, col: 16, offset: 9411},
 				run: (*parser).callonOptionalType1,
 				expr: &seqExpr{
-					pos: position{line: 353, col: 16, offset: 9333},
+					pos: position{line: 354, col: 16, offset: 9411},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 353, col: 16, offset: 9333},
+							pos:   position{line: 354, col: 16, offset: 9411},
 							label: "t",
 							expr: &ruleRefExpr{
-								pos:  position{line: 353, col: 18, offset: 9335},
+								pos:  position{line: 354, col: 18, offset: 9413},
 								name: "GenericType",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 353, col: 30, offset: 9347},
+							pos:        position{line: 354, col: 30, offset: 9425},
 							val:        "?",
 							ignoreCase: false,
 						},
@@ -1889,32 +1898,32 @@ var g = &grammar{
 		},
 		{
 			name: "GenericType",
-			pos:  position{line: 358, col: 1, offset: 9418},
+			pos:  position{line: 359, col: 1, offset: 9496},
 			expr: &actionExpr{
-				pos: position{line: 358, col: 15, offset: 9434},
+				pos: position{line: 359, col: 15, offset: 9512},
 				run: (*parser).callonGenericType1,
 				expr: &seqExpr{
-					pos: position{line: 358, col: 15, offset: 9434},
+					pos: position{line: 359, col: 15, offset: 9512},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 358, col: 15, offset: 9434},
+							pos:   position{line: 359, col: 15, offset: 9512},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 358, col: 18, offset: 9437},
+								pos:  position{line: 359, col: 18, offset: 9515},
 								name: "TypeName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 358, col: 27, offset: 9446},
+							pos:  position{line: 359, col: 27, offset: 9524},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 358, col: 29, offset: 9448},
+							pos:   position{line: 359, col: 29, offset: 9526},
 							label: "tps",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 358, col: 33, offset: 9452},
+								pos: position{line: 359, col: 33, offset: 9530},
 								expr: &ruleRefExpr{
-									pos:  position{line: 358, col: 33, offset: 9452},
+									pos:  position{line: 359, col: 33, offset: 9530},
 									name: "TypeParams",
 								},
 							},
@@ -1925,29 +1934,29 @@ var g = &grammar{
 		},
 		{
 			name: "TypeParams",
-			pos:  position{line: 363, col: 1, offset: 9519},
+			pos:  position{line: 364, col: 1, offset: 9597},
 			expr: &seqExpr{
-				pos: position{line: 363, col: 14, offset: 9534},
+				pos: position{line: 364, col: 14, offset: 9612},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 363, col: 14, offset: 9534},
+						pos:        position{line: 364, col: 14, offset: 9612},
 						val:        ":",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 363, col: 18, offset: 9538},
+						pos:        position{line: 364, col: 18, offset: 9616},
 						val:        "<",
 						ignoreCase: false,
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 363, col: 22, offset: 9542},
+						pos: position{line: 364, col: 22, offset: 9620},
 						expr: &ruleRefExpr{
-							pos:  position{line: 363, col: 22, offset: 9542},
+							pos:  position{line: 364, col: 22, offset: 9620},
 							name: "Type",
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 363, col: 28, offset: 9548},
+						pos:        position{line: 364, col: 28, offset: 9626},
 						val:        ">",
 						ignoreCase: false,
 					},
@@ -1956,75 +1965,75 @@ var g = &grammar{
 		},
 		{
 			name: "TypeName",
-			pos:  position{line: 364, col: 1, offset: 9552},
+			pos:  position{line: 365, col: 1, offset: 9630},
 			expr: &ruleRefExpr{
-				pos:  position{line: 364, col: 12, offset: 9565},
+				pos:  position{line: 365, col: 12, offset: 9643},
 				name: "Name",
 			},
 		},
 		{
 			name: "TypeDefinition",
-			pos:  position{line: 365, col: 1, offset: 9570},
+			pos:  position{line: 366, col: 1, offset: 9648},
 			expr: &actionExpr{
-				pos: position{line: 365, col: 18, offset: 9589},
+				pos: position{line: 366, col: 18, offset: 9667},
 				run: (*parser).callonTypeDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 365, col: 18, offset: 9589},
+					pos: position{line: 366, col: 18, offset: 9667},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 365, col: 18, offset: 9589},
+							pos:        position{line: 366, col: 18, offset: 9667},
 							val:        "type",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 365, col: 25, offset: 9596},
+							pos:  position{line: 366, col: 25, offset: 9674},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 365, col: 27, offset: 9598},
+							pos:   position{line: 366, col: 27, offset: 9676},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 365, col: 30, offset: 9601},
+								pos:  position{line: 366, col: 30, offset: 9679},
 								name: "TypeName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 365, col: 39, offset: 9610},
+							pos:  position{line: 366, col: 39, offset: 9688},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 365, col: 41, offset: 9612},
+							pos:   position{line: 366, col: 41, offset: 9690},
 							label: "is",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 365, col: 44, offset: 9615},
+								pos: position{line: 366, col: 44, offset: 9693},
 								expr: &ruleRefExpr{
-									pos:  position{line: 365, col: 44, offset: 9615},
+									pos:  position{line: 366, col: 44, offset: 9693},
 									name: "Interfaces",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 365, col: 56, offset: 9627},
+							pos:  position{line: 366, col: 56, offset: 9705},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 365, col: 58, offset: 9629},
+							pos:        position{line: 366, col: 58, offset: 9707},
 							val:        "{",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 365, col: 62, offset: 9633},
+							pos:   position{line: 366, col: 62, offset: 9711},
 							label: "fds",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 365, col: 66, offset: 9637},
+								pos: position{line: 366, col: 66, offset: 9715},
 								expr: &ruleRefExpr{
-									pos:  position{line: 365, col: 66, offset: 9637},
+									pos:  position{line: 366, col: 66, offset: 9715},
 									name: "FieldDefinition",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 365, col: 83, offset: 9654},
+							pos:        position{line: 366, col: 83, offset: 9732},
 							val:        "}",
 							ignoreCase: false,
 						},
@@ -2034,67 +2043,67 @@ var g = &grammar{
 		},
 		{
 			name: "TypeExtension",
-			pos:  position{line: 385, col: 1, offset: 10135},
+			pos:  position{line: 386, col: 1, offset: 10213},
 			expr: &actionExpr{
-				pos: position{line: 385, col: 17, offset: 10153},
+				pos: position{line: 386, col: 17, offset: 10231},
 				run: (*parser).callonTypeExtension1,
 				expr: &seqExpr{
-					pos: position{line: 385, col: 17, offset: 10153},
+					pos: position{line: 386, col: 17, offset: 10231},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 385, col: 17, offset: 10153},
+							pos:        position{line: 386, col: 17, offset: 10231},
 							val:        "extend",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 385, col: 26, offset: 10162},
+							pos:  position{line: 386, col: 26, offset: 10240},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 385, col: 28, offset: 10164},
+							pos:   position{line: 386, col: 28, offset: 10242},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 385, col: 31, offset: 10167},
+								pos:  position{line: 386, col: 31, offset: 10245},
 								name: "TypeName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 385, col: 40, offset: 10176},
+							pos:  position{line: 386, col: 40, offset: 10254},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 385, col: 42, offset: 10178},
+							pos:   position{line: 386, col: 42, offset: 10256},
 							label: "is",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 385, col: 45, offset: 10181},
+								pos: position{line: 386, col: 45, offset: 10259},
 								expr: &ruleRefExpr{
-									pos:  position{line: 385, col: 45, offset: 10181},
+									pos:  position{line: 386, col: 45, offset: 10259},
 									name: "Interfaces",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 385, col: 57, offset: 10193},
+							pos:  position{line: 386, col: 57, offset: 10271},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 385, col: 59, offset: 10195},
+							pos:        position{line: 386, col: 59, offset: 10273},
 							val:        "{",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 385, col: 63, offset: 10199},
+							pos:   position{line: 386, col: 63, offset: 10277},
 							label: "fds",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 385, col: 67, offset: 10203},
+								pos: position{line: 386, col: 67, offset: 10281},
 								expr: &ruleRefExpr{
-									pos:  position{line: 385, col: 67, offset: 10203},
+									pos:  position{line: 386, col: 67, offset: 10281},
 									name: "FieldDefinition",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 385, col: 84, offset: 10220},
+							pos:        position{line: 386, col: 84, offset: 10298},
 							val:        "}",
 							ignoreCase: false,
 						},
@@ -2104,14 +2113,14 @@ var g = &grammar{
 		},
 		{
 			name: "Interfaces",
-			pos:  position{line: 405, col: 1, offset: 10700},
+			pos:  position{line: 406, col: 1, offset: 10778},
 			expr: &actionExpr{
-				pos: position{line: 405, col: 14, offset: 10715},
+				pos: position{line: 406, col: 14, offset: 10793},
 				run: (*parser).callonInterfaces1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 405, col: 14, offset: 10715},
+					pos: position{line: 406, col: 14, offset: 10793},
 					expr: &ruleRefExpr{
-						pos:  position{line: 405, col: 14, offset: 10715},
+						pos:  position{line: 406, col: 14, offset: 10793},
 						name: "GenericType",
 					},
 				},
@@ -2119,63 +2128,63 @@ var g = &grammar{
 		},
 		{
 			name: "FieldDefinition",
-			pos:  position{line: 409, col: 1, offset: 10822},
+			pos:  position{line: 410, col: 1, offset: 10900},
 			expr: &actionExpr{
-				pos: position{line: 409, col: 19, offset: 10842},
+				pos: position{line: 410, col: 19, offset: 10920},
 				run: (*parser).callonFieldDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 409, col: 19, offset: 10842},
+					pos: position{line: 410, col: 19, offset: 10920},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 409, col: 19, offset: 10842},
+							pos:  position{line: 410, col: 19, offset: 10920},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 409, col: 21, offset: 10844},
+							pos:   position{line: 410, col: 21, offset: 10922},
 							label: "fn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 409, col: 24, offset: 10847},
+								pos:  position{line: 410, col: 24, offset: 10925},
 								name: "FieldName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 409, col: 34, offset: 10857},
+							pos:  position{line: 410, col: 34, offset: 10935},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 409, col: 36, offset: 10859},
+							pos:   position{line: 410, col: 36, offset: 10937},
 							label: "args",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 409, col: 41, offset: 10864},
+								pos: position{line: 410, col: 41, offset: 10942},
 								expr: &ruleRefExpr{
-									pos:  position{line: 409, col: 41, offset: 10864},
+									pos:  position{line: 410, col: 41, offset: 10942},
 									name: "ArgumentDefinitions",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 409, col: 62, offset: 10885},
+							pos:  position{line: 410, col: 62, offset: 10963},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 409, col: 64, offset: 10887},
+							pos:        position{line: 410, col: 64, offset: 10965},
 							val:        ":",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 409, col: 68, offset: 10891},
+							pos:  position{line: 410, col: 68, offset: 10969},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 409, col: 70, offset: 10893},
+							pos:   position{line: 410, col: 70, offset: 10971},
 							label: "t",
 							expr: &ruleRefExpr{
-								pos:  position{line: 409, col: 72, offset: 10895},
+								pos:  position{line: 410, col: 72, offset: 10973},
 								name: "Type",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 409, col: 77, offset: 10900},
+							pos:  position{line: 410, col: 77, offset: 10978},
 							name: "_",
 						},
 					},
@@ -2184,31 +2193,31 @@ var g = &grammar{
 		},
 		{
 			name: "ArgumentDefinitions",
-			pos:  position{line: 420, col: 1, offset: 11137},
+			pos:  position{line: 421, col: 1, offset: 11215},
 			expr: &actionExpr{
-				pos: position{line: 420, col: 23, offset: 11161},
+				pos: position{line: 421, col: 23, offset: 11239},
 				run: (*parser).callonArgumentDefinitions1,
 				expr: &seqExpr{
-					pos: position{line: 420, col: 23, offset: 11161},
+					pos: position{line: 421, col: 23, offset: 11239},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 420, col: 23, offset: 11161},
+							pos:        position{line: 421, col: 23, offset: 11239},
 							val:        "(",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 420, col: 27, offset: 11165},
+							pos:   position{line: 421, col: 27, offset: 11243},
 							label: "args",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 420, col: 32, offset: 11170},
+								pos: position{line: 421, col: 32, offset: 11248},
 								expr: &ruleRefExpr{
-									pos:  position{line: 420, col: 32, offset: 11170},
+									pos:  position{line: 421, col: 32, offset: 11248},
 									name: "ArgumentDefinition",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 420, col: 52, offset: 11190},
+							pos:        position{line: 421, col: 52, offset: 11268},
 							val:        ")",
 							ignoreCase: false,
 						},
@@ -2218,57 +2227,57 @@ var g = &grammar{
 		},
 		{
 			name: "ArgumentDefinition",
-			pos:  position{line: 427, col: 1, offset: 11353},
+			pos:  position{line: 428, col: 1, offset: 11431},
 			expr: &actionExpr{
-				pos: position{line: 427, col: 22, offset: 11376},
+				pos: position{line: 428, col: 22, offset: 11454},
 				run: (*parser).callonArgumentDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 427, col: 22, offset: 11376},
+					pos: position{line: 428, col: 22, offset: 11454},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 427, col: 22, offset: 11376},
+							pos:  position{line: 428, col: 22, offset: 11454},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 427, col: 24, offset: 11378},
+							pos:   position{line: 428, col: 24, offset: 11456},
 							label: "an",
 							expr: &ruleRefExpr{
-								pos:  position{line: 427, col: 27, offset: 11381},
+								pos:  position{line: 428, col: 27, offset: 11459},
 								name: "ArgumentName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 427, col: 40, offset: 11394},
+							pos:  position{line: 428, col: 40, offset: 11472},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 427, col: 42, offset: 11396},
+							pos:        position{line: 428, col: 42, offset: 11474},
 							val:        ":",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 427, col: 46, offset: 11400},
+							pos:  position{line: 428, col: 46, offset: 11478},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 427, col: 48, offset: 11402},
+							pos:   position{line: 428, col: 48, offset: 11480},
 							label: "t",
 							expr: &ruleRefExpr{
-								pos:  position{line: 427, col: 50, offset: 11404},
+								pos:  position{line: 428, col: 50, offset: 11482},
 								name: "Type",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 427, col: 55, offset: 11409},
+							pos:  position{line: 428, col: 55, offset: 11487},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 427, col: 57, offset: 11411},
+							pos:   position{line: 428, col: 57, offset: 11489},
 							label: "dv",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 427, col: 60, offset: 11414},
+								pos: position{line: 428, col: 60, offset: 11492},
 								expr: &ruleRefExpr{
-									pos:  position{line: 427, col: 60, offset: 11414},
+									pos:  position{line: 428, col: 60, offset: 11492},
 									name: "DefaultValue",
 								},
 							},
@@ -2279,52 +2288,52 @@ var g = &grammar{
 		},
 		{
 			name: "EnumDefinition",
-			pos:  position{line: 439, col: 1, offset: 11645},
+			pos:  position{line: 440, col: 1, offset: 11723},
 			expr: &actionExpr{
-				pos: position{line: 439, col: 18, offset: 11664},
+				pos: position{line: 440, col: 18, offset: 11742},
 				run: (*parser).callonEnumDefinition1,
 				expr: &seqExpr{
-					pos: position{line: 439, col: 18, offset: 11664},
+					pos: position{line: 440, col: 18, offset: 11742},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 439, col: 18, offset: 11664},
+							pos:        position{line: 440, col: 18, offset: 11742},
 							val:        "enum",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 439, col: 25, offset: 11671},
+							pos:  position{line: 440, col: 25, offset: 11749},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 439, col: 27, offset: 11673},
+							pos:   position{line: 440, col: 27, offset: 11751},
 							label: "tn",
 							expr: &ruleRefExpr{
-								pos:  position{line: 439, col: 30, offset: 11676},
+								pos:  position{line: 440, col: 30, offset: 11754},
 								name: "TypeName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 439, col: 39, offset: 11685},
+							pos:  position{line: 440, col: 39, offset: 11763},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 439, col: 41, offset: 11687},
+							pos:        position{line: 440, col: 41, offset: 11765},
 							val:        "{",
 							ignoreCase: false,
 						},
 						&labeledExpr{
-							pos:   position{line: 439, col: 45, offset: 11691},
+							pos:   position{line: 440, col: 45, offset: 11769},
 							label: "vals",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 439, col: 50, offset: 11696},
+								pos: position{line: 440, col: 50, offset: 11774},
 								expr: &ruleRefExpr{
-									pos:  position{line: 439, col: 50, offset: 11696},
+									pos:  position{line: 440, col: 50, offset: 11774},
 									name: "EnumValueName",
 								},
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 439, col: 65, offset: 11711},
+							pos:        position{line: 440, col: 65, offset: 11789},
 							val:        "}",
 							ignoreCase: false,
 						},
@@ -2334,27 +2343,27 @@ var g = &grammar{
 		},
 		{
 			name: "EnumValueName",
-			pos:  position{line: 449, col: 1, offset: 11892},
+			pos:  position{line: 450, col: 1, offset: 11970},
 			expr: &actionExpr{
-				pos: position{line: 449, col: 17, offset: 11910},
+				pos: position{line: 450, col: 17, offset: 11988},
 				run: (*parser).callonEnumValueName1,
 				expr: &seqExpr{
-					pos: position{line: 449, col: 17, offset: 11910},
+					pos: position{line: 450, col: 17, offset: 11988},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 449, col: 17, offset: 11910},
+							pos:  position{line: 450, col: 17, offset: 11988},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 449, col: 19, offset: 11912},
+							pos:   position{line: 450, col: 19, offset: 11990},
 							label: "n",
 							expr: &ruleRefExpr{
-								pos:  position{line: 449, col: 21, offset: 11914},
+								pos:  position{line: 450, col: 21, offset: 11992},
 								name: "Name",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 449, col: 26, offset: 11919},
+							pos:  position{line: 450, col: 26, offset: 11997},
 							name: "_",
 						},
 					},
@@ -2364,25 +2373,25 @@ var g = &grammar{
 		{
 			name:        "_",
 			displayName: "\"ignored\"",
-			pos:         position{line: 451, col: 1, offset: 11940},
+			pos:         position{line: 452, col: 1, offset: 12018},
 			expr: &actionExpr{
-				pos: position{line: 451, col: 15, offset: 11956},
+				pos: position{line: 452, col: 15, offset: 12034},
 				run: (*parser).callon_1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 451, col: 15, offset: 11956},
+					pos: position{line: 452, col: 15, offset: 12034},
 					expr: &choiceExpr{
-						pos: position{line: 451, col: 16, offset: 11957},
+						pos: position{line: 452, col: 16, offset: 12035},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 451, col: 16, offset: 11957},
+								pos:  position{line: 452, col: 16, offset: 12035},
 								name: "whitespace",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 451, col: 29, offset: 11970},
+								pos:  position{line: 452, col: 29, offset: 12048},
 								name: "Comment",
 							},
 							&litMatcher{
-								pos:        position{line: 451, col: 39, offset: 11980},
+								pos:        position{line: 452, col: 39, offset: 12058},
 								val:        ",",
 								ignoreCase: false,
 							},
@@ -2393,9 +2402,9 @@ var g = &grammar{
 		},
 		{
 			name: "whitespace",
-			pos:  position{line: 452, col: 1, offset: 12006},
+			pos:  position{line: 453, col: 1, offset: 12084},
 			expr: &charClassMatcher{
-				pos:        position{line: 452, col: 14, offset: 12021},
+				pos:        position{line: 453, col: 14, offset: 12099},
 				val:        "[ \\n\\t\\r]",
 				chars:      []rune{' ', '\n', '\t', '\r'},
 				ignoreCase: false,
@@ -2404,11 +2413,11 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 454, col: 1, offset: 12032},
+			pos:  position{line: 455, col: 1, offset: 12110},
 			expr: &notExpr{
-				pos: position{line: 454, col: 7, offset: 12040},
+				pos: position{line: 455, col: 7, offset: 12118},
 				expr: &anyMatcher{
-					line: 454, col: 8, offset: 12041,
+					line: 455, col: 8, offset: 12119,
 				},
 			},
 		},
@@ -2595,6 +2604,16 @@ func (p *parser) callonOperationType4() (interface{}, error) {
 	return p.cur.onOperationType4()
 }
 
+func (c *current) onOperationType6() (interface{}, error) {
+	return graphql.OperationSubscription, nil
+}
+
+func (p *parser) callonOperationType6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOperationType6()
+}
+
 func (c *current) onOperationName1() (interface{}, error) {
 	return string(c.text), nil
 }
@@ -27,15 +27,24 @@ func (e ErrMalformedOperation) Error() string {
 	return fmt.Sprintf("parser: malformed graphql operation: %v", e.underlying)
 }
 
-// ParseOperation attempts to parse a graphql.Operation from a byte slice.
-func ParseOperation(query []byte) (*graphql.Operation, error) {
+// ParseDocument attempts to parse a graphql.Document from a byte slice.
+func ParseDocument(query []byte) (*graphql.Document, error) {
 	result, err := parser.Parse("", query)
 	if err != nil {
 		return nil, ErrMalformedOperation{err}
 	}
 	doc, ok := result.(graphql.Document)
 	if !ok {
-		return nil, ErrMalformedOperation{err}
+		return nil, ErrMalformedOperation{fmt.Errorf("expected a query document")}
+	}
+	return &doc, nil
+}
+
+// ParseOperation attempts to parse a graphql.Operation from a byte slice.
+func ParseOperation(query []byte) (*graphql.Operation, error) {
+	doc, err := ParseDocument(query)
+	if err != nil {
+		return nil, err
 	}
 	switch len(doc.Operations) {
 	case 1:
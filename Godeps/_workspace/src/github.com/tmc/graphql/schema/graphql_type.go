@@ -69,6 +69,9 @@ type GraphQLFieldSpec struct {
 	Func        GraphQLFieldFunc
 	Arguments   []graphql.Argument // Describes any arguments the field accepts
 	IsRoot      bool               // If true, this field should be exposed at the root of the GraphQL schema
+	// Type names the GraphQL type of the values of the field, written '[T]' for lists of
+	// T. It is empty if the type is not known statically.
+	Type string
 	// TODO(tmc) add isDeprecated/deprecationReason
 }
 
@@ -77,8 +80,8 @@ func (g *GraphQLFieldSpec) GraphQLTypeInfo() GraphQLTypeInfo {
 		Name:        "GraphQLFieldSpec",
 		Description: "A GraphQL field specification",
 		Fields: map[string]*GraphQLFieldSpec{
-			"name":        {"name", "Field name", g.name, nil, false},
-			"description": {"description", "Field description", g.description, nil, false},
+			"name":        {Name: "name", Description: "Field name", Func: g.name, Type: "String"},
+			"description": {Name: "description", Description: "Field description", Func: g.description, Type: "String"},
 		},
 	}
 }
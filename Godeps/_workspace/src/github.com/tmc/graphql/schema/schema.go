@@ -73,10 +73,10 @@ func (s *Schema) GraphQLTypeInfo() GraphQLTypeInfo {
 		Name:        "Schema",
 		Description: "Root schema object",
 		Fields: map[string]*GraphQLFieldSpec{
-			"__schema":    {"__schema", "Schema entry root field", s.handleSchemaCall, nil, true},
-			"__type":      {"__type", "Query registered types by name", s.handleTypeCall, nil, true},
-			"types":       {"types", "Introspection of registered types", s.handleTypesCall, nil, false},
-			"root_fields": {"root_fields", "List fields that are exposed at the root of the GraphQL schema.", s.handleRootFields, nil, false},
+			"__schema":    {Name: "__schema", Description: "Schema entry root field", Func: s.handleSchemaCall, IsRoot: true},
+			"__type":      {Name: "__type", Description: "Query registered types by name", Func: s.handleTypeCall, IsRoot: true},
+			"types":       {Name: "types", Description: "Introspection of registered types", Func: s.handleTypesCall},
+			"root_fields": {Name: "root_fields", Description: "List fields that are exposed at the root of the GraphQL schema.", Func: s.handleRootFields},
 		},
 	}
 }
@@ -2,8 +2,8 @@ package graphql
 
 import "encoding/json"
 
-// OperationType is either "query" or "mutation"
-// Queries are reads and mutations cause side-effects.
+// OperationType is either "query", "mutation" or "subscription"
+// Queries are reads, mutations cause side-effects and subscriptions deliver a stream of results.
 type OperationType string
 
 const (
@@ -11,6 +11,8 @@ const (
 	OperationQuery OperationType = "query"
 	// OperationMutation is a mutation.
 	OperationMutation OperationType = "mutation"
+	// OperationSubscription is a long-lived read operation that yields a result per event.
+	OperationSubscription OperationType = "subscription"
 )
 
 // Document is the top-level representation of a string in GraphQL.
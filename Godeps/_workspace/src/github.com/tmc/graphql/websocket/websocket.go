@@ -0,0 +1,343 @@
+// Package websocket implements the subset of RFC 6455 used by graphql-ws servers and
+// LiveQuery clients: text messages, which may be fragmented, and ping, pong and close
+// control frames.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultMaxMessageSize bounds the size of messages read by a Conn.
+const DefaultMaxMessageSize = 1 << 20
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// ErrMessageTooLarge is returned when a message exceeds the MaxMessageSize of a Conn.
+var ErrMessageTooLarge = errors.New("websocket: message too large")
+
+// Conn is a WebSocket connection, from either its server or its client end.
+type Conn struct {
+	// MaxMessageSize bounds the size of messages read; DefaultMaxMessageSize if zero.
+	MaxMessageSize int
+	// WriteTimeout bounds the time taken by every write; writes never time out if zero.
+	// The connection is unusable after a write times out.
+	WriteTimeout time.Duration
+
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // client frames are masked, server frames are not
+
+	mu sync.Mutex // guards writes to conn
+}
+
+func headerContains(h http.Header, name, value string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// IsUpgrade reports whether r asks to upgrade to a WebSocket connection.
+func IsUpgrade(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") && headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade completes the WebSocket handshake for r, selecting protocol if the client
+// offered it.
+func Upgrade(w http.ResponseWriter, r *http.Request, protocol string) (*Conn, error) {
+	if r.Method != "GET" {
+		return nil, fmt.Errorf("websocket: method %s not allowed", r.Method)
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", 426)
+		return nil, fmt.Errorf("websocket: unsupported version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", 400)
+		return nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets are not supported by this connection", 500)
+		return nil, errors.New("websocket: response does not implement http.Hijacker")
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// connections outlive the read and write timeouts of the server's requests
+	netConn.SetDeadline(time.Time{})
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n")
+	fmt.Fprintf(brw, "Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	fmt.Fprintf(brw, "Sec-WebSocket-Accept: %s\r\n", acceptKey(key))
+	if headerContains(r.Header, "Sec-WebSocket-Protocol", protocol) {
+		fmt.Fprintf(brw, "Sec-WebSocket-Protocol: %s\r\n", protocol)
+	}
+	fmt.Fprintf(brw, "\r\n")
+	if err := brw.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &Conn{conn: netConn, br: brw.Reader}, nil
+}
+
+// Dial opens a client connection to the ws:// or wss:// URL rawURL.
+func Dial(rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var netConn net.Conn
+	switch u.Scheme {
+	case "ws":
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+		netConn, err = net.Dial("tcp", host)
+	case "wss":
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+		netConn, err = tls.Dial("tcp", host, &tls.Config{ServerName: u.Hostname()})
+	default:
+		return nil, fmt.Errorf("websocket: url must be ws:// or wss://, got '%s'", rawURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{
+		Method: "GET",
+		URL:    u,
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		netConn.Close()
+		return nil, fmt.Errorf("websocket: handshake failed: %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		netConn.Close()
+		return nil, errors.New("websocket: handshake failed: invalid Sec-WebSocket-Accept")
+	}
+	return &Conn{conn: netConn, br: br, client: true}, nil
+}
+
+func (c *Conn) maxMessageSize() int {
+	if c.MaxMessageSize > 0 {
+		return c.MaxMessageSize
+	}
+	return DefaultMaxMessageSize
+}
+
+// ReadJSON reads the next message and decodes it into v.
+func (c *Conn) ReadJSON(v interface{}) error {
+	message, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(message, v)
+}
+
+// ReadMessage returns the next text or binary message, reassembling fragmented
+// messages and answering pings along the way. io.EOF is returned once the peer closes
+// the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	fragmented := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if opcode >= opClose && (!fin || len(payload) > 125) {
+			return nil, errors.New("websocket: invalid control frame")
+		}
+		switch opcode {
+		case opClose:
+			c.writeFrame(opClose, payload)
+			return nil, io.EOF
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opText, opBinary, opContinuation:
+			if (opcode == opContinuation) != fragmented {
+				return nil, errors.New("websocket: unexpected continuation frame")
+			}
+			if len(message)+len(payload) > c.maxMessageSize() {
+				return nil, ErrMessageTooLarge
+			}
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+			fragmented = true
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.br, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	// only the frames of clients are masked
+	if masked == c.client {
+		err = errors.New("websocket: invalid frame masking")
+		return
+	}
+	if length > uint64(c.maxMessageSize()) {
+		err = ErrMessageTooLarge
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// WriteJSON sends v encoded as JSON in a text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(opText, b)
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, maskBit|byte(n))
+	case n <= 0xffff:
+		header = append(header, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		header = append(header, mask[:]...)
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.WriteTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	}
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// Close closes the underlying connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -1,11 +1,13 @@
 package parse
 
 import (
-	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
 )
 
 // Client is the primary struct that this package provides. It represents the
@@ -16,9 +18,18 @@ type Client struct {
 	masterKey    string
 	sessionToken string
 
-	logger *log.Logger
+	logger        *log.Logger
+	observer      RequestObserver
+	retryPolicy   *RetryPolicy
+	retryObserver RetryObserver
+	httpClient    *http.Client
+	ctx           context.Context
 }
 
+// RequestObserver is notified after every API request a Client makes. endpoint is the
+// request URI relative to BaseURL and statusCode is zero if no response was received.
+type RequestObserver func(method, endpoint string, statusCode int, err error, duration time.Duration)
+
 // NewClient creates a new Client to interact with the Parse API.
 func NewClient(parseAppID string, RESTAPIKey string) (*Client, error) {
 	return &Client{appID: parseAppID, restApiKey: RESTAPIKey}, nil
@@ -28,8 +39,7 @@ func NewClient(parseAppID string, RESTAPIKey string) (*Client, error) {
 func (c *Client) WithMasterKey(masterKey string) *Client {
 	newClient, _ := NewClient(c.appID, "")
 	newClient.masterKey = masterKey
-	newClient.logger = c.logger
-	return newClient
+	return c.inherit(newClient)
 }
 
 // WithSessionToken returns a Client with the session token set, authenticating as the
@@ -37,15 +47,60 @@ func (c *Client) WithMasterKey(masterKey string) *Client {
 func (c *Client) WithSessionToken(sessionToken string) *Client {
 	newClient, _ := NewClient(c.appID, c.restApiKey)
 	newClient.sessionToken = sessionToken
+	return c.inherit(newClient)
+}
+
+// WithRetryObserver returns a copy of the Client that notifies fn before every retry, in
+// addition to any RetryObserver the Client already has.
+func (c *Client) WithRetryObserver(fn RetryObserver) *Client {
+	newClient := *c
+	if previous := c.retryObserver; previous != nil {
+		newClient.retryObserver = func(method, endpoint string, attempt int, err error, delay time.Duration) {
+			previous(method, endpoint, attempt, err, delay)
+			fn(method, endpoint, attempt, err, delay)
+		}
+	} else {
+		newClient.retryObserver = fn
+	}
+	return &newClient
+}
+
+// WithContext returns a copy of the Client whose requests, and the waits between their
+// retries, are canceled once ctx is done.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	newClient := *c
+	newClient.ctx = ctx
+	return &newClient
+}
+
+// inherit copies the settings of c that do not concern credentials to newClient.
+func (c *Client) inherit(newClient *Client) *Client {
 	newClient.logger = c.logger
+	newClient.observer = c.observer
+	newClient.retryPolicy = c.retryPolicy
+	newClient.retryObserver = c.retryObserver
+	newClient.httpClient = c.httpClient
+	newClient.ctx = c.ctx
 	return newClient
 }
 
+// SetHTTPClient sets the HTTP client used for requests made by the Client and the Clients
+// derived from it afterwards. A nil client selects http.DefaultClient.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
 // TraceOn turns on API response tracing to the given logger.
 func (c *Client) TraceOn(logger *log.Logger) {
 	c.logger = logger
 }
 
+// Observe registers fn to be notified of every API request made by the Client and the
+// Clients derived from it afterwards.
+func (c *Client) Observe(fn RequestObserver) {
+	c.observer = fn
+}
+
 // TraceOff turns on API response tracing
 func (c *Client) TraceOff() {
 	c.logger = nil
@@ -83,16 +138,72 @@ func (c *Client) doWithBody(method string, endpoint string, body io.Reader) (*ht
 	return c.do(method, endpoint, "application/json", body)
 }
 
+// do performs a request, retrying it according to the retry policy of the Client.
 func (c *Client) do(method, endpoint, contentType string, body io.Reader) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doOnce(method, endpoint, contentType, body)
+		delay, retry := c.retryPolicy.delay(method, attempt, resp, err)
+		if !retry {
+			if err != nil {
+				// the response of a failed request only serves to decide on retries
+				return nil, err
+			}
+			return resp, nil
+		}
+		c.trace("Retry", method, endpoint, attempt+1, delay, err)
+		if c.retryObserver != nil {
+			c.retryObserver(method, endpoint, attempt+1, err, delay)
+		}
+		if err := c.wait(delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait waits for delay, or returns the error of the Client's context if it is done first.
+func (c *Client) wait(delay time.Duration) error {
+	if c.ctx == nil {
+		time.Sleep(delay)
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+func (c *Client) doOnce(method, endpoint, contentType string, body io.Reader) (resp *http.Response, err error) {
+	statusCode := 0
+	if c.observer != nil {
+		start := time.Now()
+		defer func() {
+			c.observer(method, endpoint, statusCode, err, time.Since(start))
+		}()
+	}
 	u, err := url.Parse(BaseURL + endpoint)
 	if err != nil {
 		return nil, err
 	}
 	req, err := c.prepReq(method, u.String(), contentType, body)
-	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if c.ctx != nil {
+		req = req.WithContext(c.ctx)
+	}
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	statusCode = resp.StatusCode
 	switch resp.StatusCode {
 	case 200:
 	case 201:
@@ -101,11 +212,21 @@ func (c *Client) do(method, endpoint, contentType string, body io.Reader) (*http
 	case 404:
 		defer resp.Body.Close()
 		err, _ := unmarshalError(resp.Body)
+		if e, ok := err.(*Error); ok {
+			e.StatusCode = resp.StatusCode
+		}
 		return nil, err
 	case 401:
+		resp.Body.Close()
+		resp.Body = http.NoBody
 		return resp, ErrUnauthorized
 	default:
-		return resp, fmt.Errorf("got unexpected status code %d", resp.StatusCode)
+		// the body is consumed by the error, leaving the status and headers of resp to
+		// decide whether to retry
+		err := statusError(resp)
+		resp.Body.Close()
+		resp.Body = http.NoBody
+		return resp, err
 	}
 	return resp, err
 }
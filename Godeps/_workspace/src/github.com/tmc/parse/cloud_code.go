@@ -53,3 +53,31 @@ func (c *Client) CallCloudJob(jobName string, arguments interface{}) ([]byte, er
 	c.trace("CallCloudJob", uri, string(body))
 	return body, err
 }
+
+// RunJob starts the given cloud code job, which requires the master key. The params are
+// serialized as JSON. On success the ID of the _JobStatus object tracking the run is
+// returned, if the server reports one.
+func (c *Client) RunJob(jobName string, params interface{}) (jobStatusID string, err error) {
+	if c.masterKey == "" {
+		return "", ErrRequiresMasterKey
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	uri := fmt.Sprintf("/1/jobs/%s", jobName)
+	resp, err := c.doWithBody("POST", uri, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	c.trace("RunJob", uri, string(body))
+	return resp.Header.Get("X-Parse-Job-Status-Id"), nil
+}
@@ -27,6 +27,32 @@ func (c *Client) Create(object Object) (objectID string, err error) {
 	return id, nil
 }
 
+// CreateClass creates an object of class className from the JSON serialization of
+// fields. On success the new object's ID and creation time are returned.
+func (c *Client) CreateClass(className string, fields interface{}) (objectID string, createdAt time.Time, err error) {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return "", createdAt, err
+	}
+	uri := "/1/classes/" + className
+	resp, err := c.doWithBody("POST", uri, bytes.NewReader(payload))
+	if err != nil {
+		return "", createdAt, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", createdAt, err
+	}
+	c.trace("Create", uri, string(body))
+	created := &struct {
+		ObjectID  string    `json:"objectId"`
+		CreatedAt time.Time `json:"createdAt"`
+	}{}
+	err = json.Unmarshal(body, created)
+	return created.ObjectID, created.CreatedAt, err
+}
+
 // GetClass populates the passed object by looking up based on Class name and objectID.
 func (c *Client) GetClass(className string, objectID string, object interface{}) error {
 	uri := fmt.Sprintf("/1/classes/%s/%s", className, objectID)
@@ -60,8 +86,18 @@ func (c *Client) Update(object Object) (updateTime time.Time, err error) {
 	if err != nil {
 		return updateTime, err
 	}
-	payload, err := json.Marshal(object)
-	uri := fmt.Sprintf("/1/classes/%s/%s", className, object.ObjectID())
+	return c.UpdateClass(className, object.ObjectID(), object)
+}
+
+// UpdateClass submits the JSON serialization of fields, the fields to change or
+// operations to apply, to the object of class className with the given objectID and on
+// success returns the updated time.
+func (c *Client) UpdateClass(className string, objectID string, fields interface{}) (updateTime time.Time, err error) {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return updateTime, err
+	}
+	uri := fmt.Sprintf("/1/classes/%s/%s", className, objectID)
 	resp, err := c.doWithBody("PUT", uri, bytes.NewReader(payload))
 	if err != nil {
 		return updateTime, err
@@ -85,9 +121,17 @@ func (c *Client) Delete(object Object) error {
 	if err != nil {
 		return err
 	}
-	uri := fmt.Sprintf("/1/classes/%s/%s", className, object.ObjectID())
+	return c.DeleteClass(className, object.ObjectID())
+}
+
+// DeleteClass removes the object objectID of className from the Parse data store.
+func (c *Client) DeleteClass(className string, objectID string) error {
+	uri := fmt.Sprintf("/1/classes/%s/%s", className, objectID)
 	resp, err := c.doSimple("DELETE", uri)
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 	c.trace("Delete", uri)
-	return err
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 )
 
 var (
@@ -51,6 +52,7 @@ var (
 	ErrProductNotFoundInAppStore         = 147
 	ErrPushMisconfigured                 = 115
 	ErrReceiptMissing                    = 143
+	ErrRequestLimitExceeded              = 155
 	ErrTimeout                           = 124
 	ErrUnsavedFile                       = 151
 	ErrUserCannotBeAlteredWithoutSession = 206
@@ -58,25 +60,50 @@ var (
 	ErrUserEmailMissing                  = 204
 	ErrUserEmailTaken                    = 203
 	ErrUserIDMismatch                    = 209
+	ErrInvalidSessionToken               = 209
 	ErrUsernameMissing                   = 200
 	ErrUsernameTaken                     = 202
 	ErrUserPasswordMissing               = 201
 	ErrUserWithEmailNotFound             = 205
 	ErrScriptError                       = 141
 	ErrValidationError                   = 142
+	ErrClassNotEmpty                     = 255
 )
 
-// Error represents a Parse API error.
+// Error represents a Parse API error. StatusCode is the HTTP status of the response that
+// carried it.
 type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"error"`
+	Code       int    `json:"code"`
+	Message    string `json:"error"`
+	StatusCode int    `json:"-"`
 }
 
 func (e Error) Error() string {
 	// TODO(tmc): improve formatting
+	if e.Code == 0 {
+		return fmt.Sprintf("parse.com error: %s", e.Message)
+	}
 	return fmt.Sprintf("parse.com error %v: %s", e.Code, e.Message)
 }
 
+// IsCode reports whether err, or an error it wraps, is a Parse API error with the given
+// code, such as ErrObjectNotFound.
+func IsCode(err error, code int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == code
+}
+
+// statusError builds the error for a response with an unexpected status, using the Parse
+// error in its body if there is one.
+func statusError(resp *http.Response) error {
+	err := &Error{}
+	if decodeErr := json.NewDecoder(resp.Body).Decode(err); decodeErr != nil || err.Code == 0 {
+		err = &Error{Message: fmt.Sprintf("got unexpected status code %d", resp.StatusCode)}
+	}
+	err.StatusCode = resp.StatusCode
+	return err
+}
+
 func unmarshalError(r io.Reader) (error, bool) {
 	err := &Error{}
 	if r == nil {
@@ -0,0 +1,65 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// Push is a push notification sent to the installations subscribed to any of Channels,
+// or matching Where.
+type Push struct {
+	Channels []string               `json:"channels,omitempty"`
+	Where    map[string]interface{} `json:"where,omitempty"`
+	// Data is the payload of the notification, such as {"alert": "hello"}.
+	Data map[string]interface{} `json:"data"`
+	// PushTime schedules the notification, if not zero.
+	PushTime time.Time `json:"-"`
+	// ExpirationTime is the time after which the notification is not delivered anymore,
+	// if not zero.
+	ExpirationTime time.Time `json:"-"`
+	// ExpirationInterval is the number of seconds after PushTime, or after it is sent, the
+	// notification expires, if not zero.
+	ExpirationInterval int `json:"expiration_interval,omitempty"`
+}
+
+// MarshalJSON encodes p the way the /1/push endpoint expects it.
+func (p *Push) MarshalJSON() ([]byte, error) {
+	type push Push
+	body := struct {
+		*push
+		PushTime       string `json:"push_time,omitempty"`
+		ExpirationTime string `json:"expiration_time,omitempty"`
+	}{push: (*push)(p)}
+	if !p.PushTime.IsZero() {
+		body.PushTime = p.PushTime.UTC().Format(time.RFC3339)
+	}
+	if !p.ExpirationTime.IsZero() {
+		body.ExpirationTime = p.ExpirationTime.UTC().Format(time.RFC3339)
+	}
+	return json.Marshal(body)
+}
+
+// SendPush sends a push notification. On success the ID of the _PushStatus object
+// tracking its delivery is returned, if the server reports one.
+func (c *Client) SendPush(push *Push) (statusID string, err error) {
+	if c.masterKey == "" {
+		return "", ErrRequiresMasterKey
+	}
+	payload, err := json.Marshal(push)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.doWithBody("POST", "/1/push", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	c.trace("SendPush", "/1/push", string(body))
+	return resp.Header.Get("X-Parse-Push-Status-Id"), nil
+}
@@ -16,13 +16,50 @@ type QueryOptions struct {
 	Order string
 	// Skip the given number of fields.
 	Skip int
+	// Count requests the total number of objects matching Where alongside the results.
+	Count bool
 }
 
 // QueryClass performs a lookup of objects based on query options and an explicit class name.
 //
 // destination must be a pointer to a slice of types satisfying the Object interface.
 func (c *Client) QueryClass(className string, options *QueryOptions, destination interface{}) error {
-	uri, err := url.Parse(fmt.Sprintf("/1/classes/%s", className))
+	_, err := c.queryClass(className, options, destination)
+	return err
+}
+
+// QueryClassCount behaves like QueryClass but also returns the total number of objects
+// matching the query, ignoring Limit and Skip.
+func (c *Client) QueryClassCount(className string, options *QueryOptions, destination interface{}) (int, error) {
+	opts := QueryOptions{}
+	if options != nil {
+		opts = *options
+	}
+	opts.Count = true
+	return c.queryClass(className, &opts, destination)
+}
+
+// CountClass returns the number of objects of the given class matching options without
+// fetching any of them.
+func (c *Client) CountClass(className string, options *QueryOptions) (int, error) {
+	opts := QueryOptions{}
+	if options != nil {
+		opts.Where = options.Where
+	}
+	opts.Count = true
+	return c.queryClass(className, &opts, nil)
+}
+
+func (c *Client) queryClass(className string, options *QueryOptions, destination interface{}) (int, error) {
+	return c.query(fmt.Sprintf("/1/classes/%s", className), options, destination)
+}
+
+// query performs a query on the objects listed by endpoint, such as /1/classes/<className>.
+func (c *Client) query(endpoint string, options *QueryOptions, destination interface{}) (int, error) {
+	uri, err := url.Parse(endpoint)
+	if err != nil {
+		return 0, err
+	}
 
 	if options != nil {
 		params := uri.Query()
@@ -38,28 +75,40 @@ func (c *Client) QueryClass(className string, options *QueryOptions, destination
 		if options.Skip != 0 {
 			params.Set("skip", fmt.Sprint(options.Skip))
 		}
+		if options.Count {
+			params.Set("count", "1")
+			// a count without a destination is a count-only query
+			if destination == nil {
+				params.Set("limit", "0")
+			}
+		}
 		uri.RawQuery = params.Encode()
 	}
 
 	resp, err := c.doSimple("GET", uri.String())
 	if err != nil {
-		return err
+		return 0, err
 	}
+	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	// delay parsing of results
 	c.trace("Query", uri, string(body))
 	results := struct {
 		Results json.RawMessage `json:"results"`
+		Count   int             `json:"count"`
 	}{}
 	// first pass
 	err = json.Unmarshal(body, &results)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if destination == nil {
+		return results.Count, nil
 	}
-	return json.Unmarshal(results.Results, destination)
+	return results.Count, json.Unmarshal(results.Results, destination)
 }
 
 // Query performs a lookup of objects based on query options.
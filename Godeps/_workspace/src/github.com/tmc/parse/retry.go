@@ -0,0 +1,96 @@
+package parse
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries idempotent (GET) requests, which includes
+// queries but not logins, whose credentials are sent in the URL. Requests are retried when they fail with a 429 or 5xx status, with one of the
+// ErrTimeout, ErrExceededQuota or ErrRequestLimitExceeded Parse errors, or with a network
+// error.
+//
+// Retries back off exponentially from BaseDelay up to MaxDelay with random jitter. A
+// Retry-After response header longer than the computed delay is honored, unless it
+// exceeds MaxDelay in which case the request is not retried.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy is a reasonable RetryPolicy for interactive use.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  200 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
+}
+
+// RetryObserver is notified before a request is retried. attempt starts at 1 for the
+// first retry and err is the error that caused it.
+type RetryObserver func(method, endpoint string, attempt int, err error, delay time.Duration)
+
+// SetRetryPolicy sets the policy used to retry failed requests made by the Client and the
+// Clients derived from it afterwards. A nil policy disables retries.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// delay reports whether the attempt'th try of a request, which ended with resp and err,
+// should be retried and how long to wait before doing so.
+func (p *RetryPolicy) delay(method string, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if p == nil || err == nil || method != "GET" || attempt >= p.MaxRetries {
+		return 0, false
+	}
+	var (
+		retryAfter time.Duration
+		parseErr   *Error
+		netErr     net.Error
+	)
+	switch {
+	case resp != nil && (resp.StatusCode == 429 || resp.StatusCode >= 500):
+	case errors.As(err, &parseErr):
+		if parseErr.Code != ErrTimeout && parseErr.Code != ErrExceededQuota && parseErr.Code != ErrRequestLimitExceeded {
+			return 0, false
+		}
+	case errors.As(err, &netErr):
+	default:
+		return 0, false
+	}
+	if resp != nil {
+		retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+
+	backoff := p.BaseDelay << uint(attempt)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	if half := int64(backoff / 2); half > 0 {
+		backoff = time.Duration(half + rand.Int63n(half+1))
+	}
+	if retryAfter > backoff {
+		if retryAfter > p.MaxDelay {
+			return 0, false
+		}
+		backoff = retryAfter
+	}
+	return backoff, true
+}
+
+// parseRetryAfter parses the delay-seconds or HTTP-date forms of a Retry-After header.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(time.Now())
+	}
+	return 0
+}
@@ -1,6 +1,7 @@
 package parse
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -14,6 +15,9 @@ type SchemaField struct {
 type Schema struct {
 	ClassName string                 `json:"className,omitempty"`
 	Fields    map[string]SchemaField `json:"fields,omitempty"`
+	// ClassLevelPermissions are the class-level permissions of the class, such as
+	// {"find": {"*": true}, "create": {"role:Admin": true}}.
+	ClassLevelPermissions map[string]interface{} `json:"classLevelPermissions,omitempty"`
 }
 
 //
@@ -58,3 +62,68 @@ func (c *Client) GetFullSchema() (map[string]*Schema, error) {
 	}
 	return mapResult, nil
 }
+
+// CreateClassSchema creates the class schema.ClassName with the fields and class-level
+// permissions of schema, which requires the master key. On success the schema of the new
+// class is returned.
+func (c *Client) CreateClassSchema(schema *Schema) (*Schema, error) {
+	return c.writeClassSchema("POST", schema.ClassName, schema)
+}
+
+// UpdateClassSchema adds the fields of schema to the class schema.ClassName, deletes its
+// fields named in deleteFields and, if schema has any, replaces its class-level
+// permissions. It requires the master key. On success the updated schema of the class is
+// returned.
+func (c *Client) UpdateClassSchema(schema *Schema, deleteFields ...string) (*Schema, error) {
+	fields := make(map[string]interface{}, len(schema.Fields)+len(deleteFields))
+	for name, field := range schema.Fields {
+		fields[name] = field
+	}
+	for _, name := range deleteFields {
+		fields[name] = map[string]string{"__op": "Delete"}
+	}
+	return c.writeClassSchema("PUT", schema.ClassName, map[string]interface{}{
+		"className":             schema.ClassName,
+		"fields":                fields,
+		"classLevelPermissions": schema.ClassLevelPermissions,
+	})
+}
+
+// DeleteClassSchema deletes the class className, which requires the master key. Parse
+// only deletes classes without objects.
+func (c *Client) DeleteClassSchema(className string) error {
+	if c.masterKey == "" {
+		return ErrRequiresMasterKey
+	}
+	uri := fmt.Sprintf("/1/schemas/%s", className)
+	resp, err := c.doSimple("DELETE", uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.trace("DeleteClassSchema", uri)
+	return nil
+}
+
+func (c *Client) writeClassSchema(method, className string, payload interface{}) (*Schema, error) {
+	if c.masterKey == "" {
+		return nil, ErrRequiresMasterKey
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	uri := fmt.Sprintf("/1/schemas/%s", className)
+	resp, err := c.doWithBody(method, uri, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.trace("WriteClassSchema", uri, string(body))
+	var schema *Schema
+	return schema, json.Unmarshal(body, &schema)
+}
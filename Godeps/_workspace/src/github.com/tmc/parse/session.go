@@ -0,0 +1,44 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// QuerySessions queries the sessions visible to the Client: the sessions of the user of
+// its session token, or any session with the master key.
+//
+// destination must be a pointer to a slice of session objects, such as maps.
+func (c *Client) QuerySessions(options *QueryOptions, destination interface{}) error {
+	_, err := c.query("/1/sessions", options, destination)
+	return err
+}
+
+// CurrentSession populates session with the session of the session token of the Client.
+func (c *Client) CurrentSession(session interface{}) error {
+	uri := "/1/sessions/me"
+	resp, err := c.doSimple("GET", uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	c.trace("CurrentSession", uri, string(body))
+	return json.Unmarshal(body, session)
+}
+
+// DeleteSession revokes the session with the given objectID.
+func (c *Client) DeleteSession(objectID string) error {
+	uri := fmt.Sprintf("/1/sessions/%s", objectID)
+	resp, err := c.doSimple("DELETE", uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.trace("DeleteSession", uri)
+	return nil
+}
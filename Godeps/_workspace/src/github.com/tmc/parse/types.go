@@ -0,0 +1,122 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the layout of the ISO 8601 timestamps used by Parse.
+const dateLayout = "2006-01-02T15:04:05.000Z"
+
+// Pointer is a reference to a Parse object.
+type Pointer struct {
+	ClassName string
+	ObjectID  string
+}
+
+// MarshalJSON encodes p as a Parse Pointer.
+func (p Pointer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"__type":    "Pointer",
+		"className": p.ClassName,
+		"objectId":  p.ObjectID,
+	})
+}
+
+// UnmarshalJSON decodes a Parse Pointer, or a full object returned for an included
+// pointer.
+func (p *Pointer) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Type      string `json:"__type"`
+		ClassName string `json:"className"`
+		ObjectID  string `json:"objectId"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Type != "Pointer" && v.Type != "Object" {
+		return fmt.Errorf("parse: expected a Pointer, got type '%s'", v.Type)
+	}
+	p.ClassName, p.ObjectID = v.ClassName, v.ObjectID
+	return nil
+}
+
+// Date is a Parse Date.
+type Date struct {
+	time.Time
+}
+
+// MarshalJSON encodes d as a Parse Date.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"__type": "Date",
+		"iso":    d.UTC().Format(dateLayout),
+	})
+}
+
+// UnmarshalJSON decodes a Parse Date, or a bare ISO 8601 string such as the createdAt
+// field.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var iso string
+	if err := json.Unmarshal(b, &iso); err != nil {
+		var v struct {
+			Type string `json:"__type"`
+			ISO  string `json:"iso"`
+		}
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		if v.Type != "Date" {
+			return fmt.Errorf("parse: expected a Date, got type '%s'", v.Type)
+		}
+		iso = v.ISO
+	}
+	t, err := time.Parse(time.RFC3339Nano, iso)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// GeoPoint is a Parse GeoPoint.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// MarshalJSON encodes g as a Parse GeoPoint.
+func (g GeoPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"__type":    "GeoPoint",
+		"latitude":  g.Latitude,
+		"longitude": g.Longitude,
+	})
+}
+
+// UnmarshalJSON decodes a Parse GeoPoint.
+func (g *GeoPoint) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Type      string  `json:"__type"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Type != "GeoPoint" {
+		return fmt.Errorf("parse: expected a GeoPoint, got type '%s'", v.Type)
+	}
+	g.Latitude, g.Longitude = v.Latitude, v.Longitude
+	return nil
+}
+
+// MarshalJSON encodes f as a Parse File, as stored in object fields.
+func (f ParseFile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"__type": "File",
+		"name":   f.Name,
+		"url":    f.URL,
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/url"
 	"time"
 )
@@ -22,7 +21,7 @@ type ParseUser struct {
 	Password      string `json:"password,omitempty"`
 	SessionToken  string `json:"sessionToken,omitempty"`
 	Email         string `json:"email,omitempty"`
-	EmailVerified string `json:"emailVerified,omitempty"`
+	EmailVerified bool   `json:"emailVerified,omitempty"`
 
 	AuthData *authData `json:"authData,omitempty"`
 }
@@ -46,10 +45,13 @@ type authData struct {
 	} `json:"twitter,omitempty"`
 }
 
-// CreateUser creates a user from the specified object. On success the new user is
-// returned. The provided object is not modified.
-func (c *Client) CreateUser(user User) (*ParseUser, error) {
+// CreateUser creates a user from the specified object, a User or a map of fields. On
+// success the new user is returned. The provided object is not modified.
+func (c *Client) CreateUser(user interface{}) (*ParseUser, error) {
 	payload, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
 	c.trace("CreateUser >", "/1/users", string(payload))
 	resp, err := c.doWithBody("POST", "/1/users", bytes.NewReader(payload))
 	if err != nil {
@@ -75,7 +77,8 @@ func (c *Client) LoginUser(username, password string, user User) error {
 	params.Add("password", password)
 	uri.RawQuery = params.Encode()
 
-	resp, err := c.doSimple("GET", uri.String())
+	// logins are not retried, their credentials are in the URL
+	resp, err := c.doOnce("GET", uri.String(), "application/json", nil)
 	if err != nil {
 		return err
 	}
@@ -107,8 +110,9 @@ func (c *Client) GetUser(userID string) (*User, error) {
 	return user, json.Unmarshal(body, &user)
 }
 
-// CurrentUser looks up the user associated with the provided credentials. The provided user is populated on success.
-func (c *Client) CurrentUser(user User) error {
+// CurrentUser looks up the user associated with the provided credentials. The provided
+// user, a User or a pointer to a map, is populated on success.
+func (c *Client) CurrentUser(user interface{}) error {
 	uri := fmt.Sprintf("/1/users/me")
 	resp, err := c.doSimple("GET", uri)
 	if err != nil {
@@ -130,7 +134,6 @@ func (c *Client) UpdateUser(user User) (updateTime time.Time, err error) {
 	payload, err := json.Marshal(user)
 	uri := fmt.Sprintf("/1/users/%s", user.ObjectID())
 	resp, err := c.doWithBody("PUT", uri, bytes.NewReader(payload))
-	log.Println("OI", string(payload))
 	c.trace("UpdateUser >", uri, string(payload))
 	if err != nil {
 		return updateTime, err
@@ -152,9 +155,26 @@ func (c *Client) UpdateUser(user User) (updateTime time.Time, err error) {
 func (c *Client) DeleteUser(user User) error {
 	uri := fmt.Sprintf("/1/users/%s", user.ObjectID())
 	resp, err := c.doSimple("DELETE", uri)
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 	c.trace("DeleteUser", uri)
-	return err
+	return nil
+}
+
+// LogoutUser revokes the session of the session token of the Client.
+func (c *Client) LogoutUser() error {
+	if c.sessionToken == "" {
+		return ErrUnauthorized
+	}
+	resp, err := c.doWithBody("POST", "/1/logout", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.trace("LogoutUser", "/1/logout")
+	return nil
 }
 
 // PasswordResetRequest sends a password reset email to the provided email address.
@@ -166,11 +186,30 @@ func (c *Client) PasswordResetRequest(email string) error {
 		return err
 	}
 	uri := "/1/requestPasswordReset"
-	resp, err := c.doSimple("GET", uri)
+	resp, err := c.doWithBody("POST", uri, bytes.NewReader(payload))
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	c.trace("PasswordResetRequest", uri, string(payload))
-	return err
+	return nil
+}
+
+// VerificationEmailRequest sends a new email address verification email to the provided
+// email address.
+func (c *Client) VerificationEmailRequest(email string) error {
+	payload, err := json.Marshal(struct {
+		Email string `json:"email"`
+	}{Email: email})
+	if err != nil {
+		return err
+	}
+	uri := "/1/verificationEmailRequest"
+	resp, err := c.doWithBody("POST", uri, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.trace("VerificationEmailRequest", uri, string(payload))
+	return nil
 }
@@ -0,0 +1,180 @@
+package parse_graphql
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/schema"
+	"golang.org/x/net/context"
+)
+
+// ACL is the value of the ACL field of a Parse object.
+type ACL struct {
+	Entries []*ACLEntry
+}
+
+// ACLEntry grants read and write access to an object to the public, to a user or to
+// the users of a role.
+type ACLEntry struct {
+	Public   bool
+	UserID   string
+	RoleName string
+	Read     bool
+	Write    bool
+}
+
+// newACL returns the ACL stored as value in an object, or nil if value is not an ACL.
+// Entries list the public first, then users by ID and roles by name.
+func newACL(value interface{}) *ACL {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	acl := &ACL{Entries: make([]*ACLEntry, 0, len(m))}
+	for key, v := range m {
+		access, _ := v.(map[string]interface{})
+		entry := &ACLEntry{Read: access["read"] == true, Write: access["write"] == true}
+		switch {
+		case key == "*":
+			entry.Public = true
+		case strings.HasPrefix(key, "role:"):
+			entry.RoleName = strings.TrimPrefix(key, "role:")
+		default:
+			entry.UserID = key
+		}
+		acl.Entries = append(acl.Entries, entry)
+	}
+	sort.Slice(acl.Entries, func(i, j int) bool {
+		a, b := acl.Entries[i], acl.Entries[j]
+		if a.Public != b.Public {
+			return a.Public
+		}
+		if (a.RoleName == "") != (b.RoleName == "") {
+			return a.RoleName == ""
+		}
+		return a.UserID+a.RoleName < b.UserID+b.RoleName
+	})
+	return acl
+}
+
+func (acl *ACL) GraphQLTypeInfo() schema.GraphQLTypeInfo {
+	return schema.GraphQLTypeInfo{
+		Name:        "ACL",
+		Description: "Parse access control list",
+		Fields: schema.GraphQLFieldSpecMap{
+			"entries": {
+				Name:        "entries",
+				Description: "The access granted to the public, then to users and roles.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return r.Resolve(ctx, acl.Entries, f)
+				},
+				Type: "[ACLEntry]",
+			},
+		},
+	}
+}
+
+func (e *ACLEntry) GraphQLTypeInfo() schema.GraphQLTypeInfo {
+	return schema.GraphQLTypeInfo{
+		Name:        "ACLEntry",
+		Description: "Access to an object granted to the public, a user or a role",
+		Fields: schema.GraphQLFieldSpecMap{
+			"public": {
+				Name:        "public",
+				Description: "Whether the entry applies to everyone.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return e.Public, nil
+				},
+				Type: "Boolean",
+			},
+			"userId": {
+				Name:        "userId",
+				Description: "The objectId of the user the entry applies to, if any.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return nullString(e.UserID), nil
+				},
+				Type: "String",
+			},
+			"roleName": {
+				Name:        "roleName",
+				Description: "The name of the role the entry applies to, if any.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return nullString(e.RoleName), nil
+				},
+				Type: "String",
+			},
+			"read": {
+				Name:        "read",
+				Description: "Whether the object can be read.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return e.Read, nil
+				},
+				Type: "Boolean",
+			},
+			"write": {
+				Name:        "write",
+				Description: "Whether the object can be updated and deleted.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return e.Write, nil
+				},
+				Type: "Boolean",
+			},
+		},
+	}
+}
+
+// nullString returns s, or nil if s is empty.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// aclArgument converts a list of entries such as
+//
+//	[{public: true, read: true}, {userId: "xWMyZ4YEGZ", read: true, write: true}, {roleName: "admins", write: true}]
+//
+// into a Parse ACL.
+func aclArgument(arg string, value interface{}) (map[string]interface{}, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, argumentErrorf("'%s' must be a list of entries, got %#v", arg, value)
+	}
+	acl := map[string]interface{}{}
+	for _, v := range list {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, argumentErrorf("'%s' entries must be objects, got %#v", arg, v)
+		}
+		var keys []string
+		if entry["public"] == true {
+			keys = append(keys, "*")
+		}
+		if id, ok := entry["userId"].(string); ok && id != "" {
+			keys = append(keys, id)
+		}
+		if name, ok := entry["roleName"].(string); ok && name != "" {
+			keys = append(keys, "role:"+name)
+		}
+		if len(keys) != 1 {
+			return nil, argumentErrorf("'%s' entries must set exactly one of 'public: true', 'userId' and 'roleName'", arg)
+		}
+		access := map[string]interface{}{}
+		for _, perm := range []string{"read", "write"} {
+			switch entry[perm] {
+			case true:
+				access[perm] = true
+			case false, nil:
+			default:
+				return nil, argumentErrorf("'%s' entries must have boolean '%s' values, got %#v", arg, perm, entry[perm])
+			}
+		}
+		if len(access) > 0 {
+			acl[keys[0]] = access
+		}
+	}
+	return acl, nil
+}
@@ -0,0 +1,281 @@
+package parse_graphql
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/graphql/schema"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// errAdminOnly is returned by admin fields executed outside of an admin handler.
+var errAdminOnly = fmt.Errorf("%w: admin endpoint only", parse.ErrRequiresMasterKey)
+
+// AdminAuthenticator is the handler.Authenticator of admin handlers. Requests must carry
+// Secret in their X-Admin-Secret header, or a TLS client certificate issued by ClientCAs;
+// they then act with the master key, whatever credentials they send.
+type AdminAuthenticator struct {
+	// Client is the client requests act with, once authed with MasterKey.
+	Client    *parse.Client
+	MasterKey string
+
+	// Secret, if set, authenticates requests sending it in their X-Admin-Secret header.
+	Secret string
+	// ClientCAs, if set, authenticates requests made with a client certificate they
+	// issued. The server must request client certificates.
+	ClientCAs *x509.CertPool
+}
+
+func (a *AdminAuthenticator) Authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	if !a.authorized(r) {
+		return nil, fmt.Errorf("%w: admin credentials required", parse.ErrUnauthorized)
+	}
+	identity := &Identity{MasterKey: a.MasterKey, Master: true, Admin: true}
+	return NewAuthContext(ctx, identity, identity.authed(a.Client)), nil
+}
+
+func (a *AdminAuthenticator) authorized(r *http.Request) bool {
+	if a.Secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Secret")), []byte(a.Secret)) == 1 {
+		return true
+	}
+	if a.ClientCAs == nil || r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return false
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range r.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := r.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         a.ClientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err == nil
+}
+
+// RegisterAdminSchema registers the types and root fields of parseSchema in s, along
+// with the admin-only root fields of AdminSchema. s is meant for an admin handler only,
+// authenticated by an AdminAuthenticator. reload, if not nil, is called once the schema
+// mutations have changed the app's schema, to rebuild the GraphQL schemas served from it.
+func RegisterAdminSchema(s *schema.Schema, client *parse.Client, parseSchema *ParseSchema, changes ChangeSource, reload func() error) error {
+	if err := RegisterSchema(s, client, parseSchema, changes); err != nil {
+		return err
+	}
+	s.Register(&AdminSchema{ParseSchema: parseSchema, reload: reload})
+	return nil
+}
+
+// AdminSchema exposes the admin-only root fields of a ParseSchema: running jobs, reading
+// and changing the app's schema and deleting objects regardless of their ACLs.
+type AdminSchema struct {
+	*ParseSchema
+	reload func() error
+}
+
+func (s *AdminSchema) GraphQLTypeInfo() schema.GraphQLTypeInfo {
+	return schema.GraphQLTypeInfo{
+		Name:        "AdminSchema",
+		Description: "Admin-only root fields",
+		Fields: map[string]*schema.GraphQLFieldSpec{
+			"runJob": {
+				Name:        "runJob",
+				Description: "Start the Cloud Code job 'name' with the 'params' object, returning the ID of the _JobStatus object tracking it, if any.",
+				Func:        adminOnly(s.runJob),
+				Type:        "String",
+				Arguments:   []graphql.Argument{{Name: "name"}, {Name: "params"}},
+				IsRoot:      true,
+			},
+			"classSchemas": {
+				Name:        "classSchemas",
+				Description: "Return the schemas of the classes of the app, as reported by Parse.",
+				Func:        adminOnly(s.classSchemas),
+				IsRoot:      true,
+			},
+			"createClass": {
+				Name:        "createClass",
+				Description: "Create the class 'className' with the 'fields' ({name: {type, targetClass}}) and 'classLevelPermissions' objects, returning its schema.",
+				Func:        adminOnly(s.createClass),
+				Arguments:   []graphql.Argument{{Name: "className"}, {Name: "fields"}, {Name: "classLevelPermissions"}},
+				IsRoot:      true,
+			},
+			"addField": {
+				Name:        "addField",
+				Description: "Add the field 'name' of type 'type' to the class 'className', returning its schema. Pointer and Relation fields need a 'targetClass'.",
+				Func:        adminOnly(s.addField),
+				Arguments:   []graphql.Argument{{Name: "className"}, {Name: "name"}, {Name: "type"}, {Name: "targetClass"}},
+				IsRoot:      true,
+			},
+			"deleteField": {
+				Name:        "deleteField",
+				Description: "Delete the field 'name' of the class 'className', along with its values, returning the schema of the class.",
+				Func:        adminOnly(s.deleteField),
+				Arguments:   []graphql.Argument{{Name: "className"}, {Name: "name"}},
+				IsRoot:      true,
+			},
+			"deleteObject": {
+				Name:        "deleteObject",
+				Description: "Delete the object 'objectId' of class 'className', regardless of its ACL.",
+				Func:        adminOnly(s.deleteObject),
+				Type:        "Boolean",
+				Arguments:   []graphql.Argument{{Name: "className"}, {Name: "objectId"}},
+				IsRoot:      true,
+			},
+		},
+	}
+}
+
+// adminOnly wraps the function of an admin field, rejecting requests not authenticated by
+// an AdminAuthenticator.
+func adminOnly(fn schema.GraphQLFieldFunc) schema.GraphQLFieldFunc {
+	return func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+		if identity, ok := IdentityFromContext(ctx); !ok || !identity.Admin {
+			return nil, errAdminOnly
+		}
+		return fn(ctx, r, f)
+	}
+}
+
+func (s *AdminSchema) runJob(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	value, _ := f.Arguments.Get("name")
+	name, ok := value.(string)
+	if !ok || name == "" {
+		return nil, argumentErrorf("'name' must be the name of a Cloud Code job.")
+	}
+	params, _ := f.Arguments.Get("params")
+	if _, ok := params.(map[string]interface{}); params != nil && !ok {
+		return nil, argumentErrorf("'params' must be an object, got %#v", params)
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return tracedClient(ctx, s.authedClient(ctx)).RunJob(name, params)
+}
+
+func (s *AdminSchema) classSchemas(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	classes, err := tracedClient(ctx, s.authedClient(ctx)).GetFullSchema()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*parse.Schema, 0, len(classes))
+	for _, class := range classes {
+		result = append(result, class)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ClassName < result[j].ClassName })
+	return result, nil
+}
+
+func (s *AdminSchema) deleteObject(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	args, err := stringArguments(f, "className", "objectId")
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return true, tracedClient(ctx, s.authedClient(ctx)).DeleteClass(args[0], args[1])
+}
+
+func (s *AdminSchema) createClass(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	args, err := stringArguments(f, "className")
+	if err != nil {
+		return nil, err
+	}
+	class := &parse.Schema{ClassName: args[0], Fields: map[string]parse.SchemaField{}}
+	if value, _ := f.Arguments.Get("fields"); value != nil {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, argumentErrorf("'fields' must be an object, got %#v", value)
+		}
+		for name, value := range fields {
+			def, _ := value.(map[string]interface{})
+			fieldType, _ := def["type"].(string)
+			if fieldType == "" {
+				return nil, argumentErrorf("field '%s' must be an object with a 'type'.", name)
+			}
+			targetClass, _ := def["targetClass"].(string)
+			class.Fields[name] = parse.SchemaField{Type: fieldType, TargetClass: targetClass}
+		}
+	}
+	if value, _ := f.Arguments.Get("classLevelPermissions"); value != nil {
+		clp, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, argumentErrorf("'classLevelPermissions' must be an object, got %#v", value)
+		}
+		class.ClassLevelPermissions = clp
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return s.schemaChanged(tracedClient(ctx, s.authedClient(ctx)).CreateClassSchema(class))
+}
+
+func (s *AdminSchema) addField(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	args, err := stringArguments(f, "className", "name", "type")
+	if err != nil {
+		return nil, err
+	}
+	value, _ := f.Arguments.Get("targetClass")
+	targetClass, ok := value.(string)
+	if value != nil && !ok {
+		return nil, argumentErrorf("'targetClass' must be a string, got %#v", value)
+	}
+	class := &parse.Schema{
+		ClassName: args[0],
+		Fields:    map[string]parse.SchemaField{args[1]: {Type: args[2], TargetClass: targetClass}},
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return s.schemaChanged(tracedClient(ctx, s.authedClient(ctx)).UpdateClassSchema(class))
+}
+
+func (s *AdminSchema) deleteField(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	args, err := stringArguments(f, "className", "name")
+	if err != nil {
+		return nil, err
+	}
+	if s.Policy.names(args[0], args[1]) {
+		return nil, argumentErrorf("field '%s' of class '%s' is named by the policy, which must be changed first.", args[1], args[0])
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return s.schemaChanged(tracedClient(ctx, s.authedClient(ctx)).UpdateClassSchema(&parse.Schema{ClassName: args[0]}, args[1]))
+}
+
+// schemaChanged returns class, the schema of a class changed by a schema mutation, once
+// the GraphQL schemas have been rebuilt.
+func (s *AdminSchema) schemaChanged(class *parse.Schema, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	if s.reload != nil {
+		if err := s.reload(); err != nil {
+			return nil, fmt.Errorf("class '%s' changed but the GraphQL schema was not rebuilt: %v", class.ClassName, err)
+		}
+	}
+	return class, nil
+}
+
+// stringArguments returns the arguments of f named by names, which must be non-empty
+// strings.
+func stringArguments(f *graphql.Field, names ...string) ([]string, error) {
+	args := make([]string, len(names))
+	for i, name := range names {
+		value, _ := f.Arguments.Get(name)
+		if args[i], _ = value.(string); args[i] == "" {
+			return nil, argumentErrorf("'%s' must be a non-empty string.", name)
+		}
+	}
+	return args, nil
+}
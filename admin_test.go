@@ -0,0 +1,81 @@
+package parse_graphql_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tmc/graphql/executor"
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/graphql/schema"
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/parsetest"
+)
+
+const adminSecret = "admin-secret"
+
+// newAdminEndpoint serves the admin schema of p, authenticated by adminSecret.
+func newAdminEndpoint(t *testing.T, p *parsetest.Server) *endpoint {
+	t.Helper()
+	client := p.Client()
+	classes, err := client.WithMasterKey(parsetest.MasterKey).GetFullSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := parse_graphql.NewParseSchema(client, classes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := schema.New()
+	if err := parse_graphql.RegisterAdminSchema(s, client, ps, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	h := handler.New(executor.New(s))
+	h.ErrorExtensions = parse_graphql.ErrorExtensions
+	h.Authenticator = &parse_graphql.AdminAuthenticator{Client: client, MasterKey: parsetest.MasterKey, Secret: adminSecret}
+	e := &endpoint{Server: httptest.NewServer(h), parse: p, handler: h}
+	t.Cleanup(e.Close)
+	return e
+}
+
+func TestAdminEndpoint(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Post", map[string]parse.SchemaField{"title": {Type: "String"}})
+	// only the master key bypasses an empty ACL
+	id := p.AddObject("Post", map[string]interface{}{"title": "private", "ACL": map[string]interface{}{}})
+	ran := 0
+	p.HandleJob("cleanup", func(req *parsetest.FunctionRequest) (interface{}, error) {
+		ran++
+		return nil, nil
+	})
+	e := newAdminEndpoint(t, p)
+	admin := map[string]string{"X-Admin-Secret": adminSecret}
+
+	for name, header := range map[string]map[string]string{
+		"no secret":    nil,
+		"wrong secret": {"X-Admin-Secret": "nope"},
+		"master key":   {"X-Parse-Master-Key": parsetest.MasterKey},
+	} {
+		if r := e.post(t, header, `{ PostCount }`); r.status != http.StatusUnauthorized {
+			t.Errorf("%s: got %d %v, want 401", name, r.status, r.Data)
+		}
+	}
+
+	if r := e.post(t, admin, `{ PostCount }`); r.field(t, 0) != float64(1) {
+		t.Errorf("admin count: got %v, want the private post", r.Data)
+	}
+	if r := e.post(t, admin, `mutation j { runJob(name: "cleanup") }`); r.Error != nil || ran != 1 {
+		t.Errorf("runJob: got %v (error %v), ran %d times", r.Data, r.Error, ran)
+	}
+	e.post(t, admin, `mutation d { deleteObject(className: "Post", objectId: "`+id+`") }`)
+	if p.Object("Post", id) != nil {
+		t.Error("deleteObject did not delete the post")
+	}
+
+	// admin fields are not exposed by the public schema
+	public := newEndpoint(t, p, nil, nil)
+	if r := public.post(t, nil, `mutation j { runJob(name: "cleanup") }`); r.Error == nil || ran != 1 {
+		t.Errorf("public runJob: got %v, ran %d times, want an error", r.Data, ran)
+	}
+}
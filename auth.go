@@ -0,0 +1,340 @@
+package parse_graphql
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// Identity is the caller of a GraphQL request, as resolved by an authenticator.
+type Identity struct {
+	// SessionToken is the Parse session token the caller acts with, if any.
+	SessionToken string
+	// MasterKey is the master key the caller sent, if any. Parse checks it.
+	MasterKey string
+	// Master is set once the caller's master key has been checked against the app's, or
+	// for callers authenticated by an AdminAuthenticator. Only then do policies grant the
+	// caller master access; an unchecked MasterKey only reaches Parse.
+	Master bool
+	// UserID is the objectId of the user of a caller authenticated with a JWT.
+	UserID string
+	// Claims are the claims of the JWT the caller authenticated with, if any.
+	Claims map[string]interface{}
+	// Admin is set for callers authenticated by an AdminAuthenticator.
+	Admin bool
+}
+
+type authContextKey int
+
+const requestAuthKey authContextKey = 0
+
+type requestAuth struct {
+	identity *Identity
+	client   *parse.Client
+	caller   caller
+}
+
+// NewAuthContext returns ctx carrying the identity of the caller of a request, and the
+// client authed as the caller that fields act with.
+func NewAuthContext(ctx context.Context, identity *Identity, client *parse.Client) context.Context {
+	return context.WithValue(ctx, requestAuthKey, &requestAuth{identity: identity, client: client})
+}
+
+// IdentityFromContext returns the identity NewAuthContext attached to ctx, if any.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	auth, ok := ctx.Value(requestAuthKey).(*requestAuth)
+	if !ok {
+		return nil, false
+	}
+	return auth.identity, true
+}
+
+// requestIdentity returns the identity of the caller of ctx's request: the one attached
+// by an authenticator or, without one, the Parse credentials of the request's headers.
+func requestIdentity(ctx context.Context) *Identity {
+	if identity, ok := IdentityFromContext(ctx); ok {
+		return identity
+	}
+	if r, ok := handler.RequestFromContext(ctx); ok {
+		return headerIdentity(r)
+	}
+	return &Identity{}
+}
+
+// headerIdentity returns the Parse credentials of r: its X-Parse-Master-Key header, and
+// the session token of its X-Parse-Session-Token or 'Authorization: Bearer' header.
+func headerIdentity(r *http.Request) *Identity {
+	identity := &Identity{
+		SessionToken: r.Header.Get("X-Parse-Session-Token"),
+		MasterKey:    r.Header.Get("X-Parse-Master-Key"),
+	}
+	if token := bearerToken(r); token != "" && identity.SessionToken == "" {
+		identity.SessionToken = token
+	}
+	return identity
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// authed returns client authed with the credentials of identity: its master key, which
+// takes precedence, or its session token.
+func (identity *Identity) authed(client *parse.Client) *parse.Client {
+	switch {
+	case identity.MasterKey != "":
+		return client.WithMasterKey(identity.MasterKey)
+	case identity.SessionToken != "":
+		return client.WithSessionToken(identity.SessionToken)
+	}
+	return client
+}
+
+// DefaultJWTSessionLifetime is the lifetime of the sessions created for JWT users.
+const DefaultJWTSessionLifetime = time.Hour
+
+// DefaultMaxJWTSessions bounds the number of JWT users whose sessions are kept.
+const DefaultMaxJWTSessions = 10000
+
+// ParseAuthenticator is a handler.Authenticator mapping requests onto Parse credentials:
+// the X-Parse-Master-Key header, which must match MasterKey, and a session token sent in
+// the X-Parse-Session-Token header or an 'Authorization: Bearer' header. With JWT set, bearer JWTs are verified
+// instead, and act as the user named by their UserClaim: a session is created for the
+// user with MasterClient, and reused until it is about to expire, when it is replaced and
+// revoked.
+type ParseAuthenticator struct {
+	// Client is the client fields act with, authed as the caller.
+	Client *parse.Client
+	// MasterClient is the client, authed with the master key, creating the sessions of
+	// JWT users and looking them up.
+	MasterClient *parse.Client
+
+	// JWT, if set, verifies the bearer tokens that are JWTs.
+	JWT *JWTVerifier
+	// UserClaim is the claim naming the user of a JWT, "sub" if empty.
+	UserClaim string
+	// UserField is the _User field UserClaim is matched against, "objectId" if empty.
+	UserField string
+	// SessionLifetime is the lifetime of the sessions created for JWT users, whatever the
+	// expiry of their JWTs, which every request must still send;
+	// DefaultJWTSessionLifetime if zero.
+	SessionLifetime time.Duration
+	// MaxSessions bounds the number of JWT users whose sessions are kept; the sessions of
+	// others are revoked when it is reached. DefaultMaxJWTSessions if zero.
+	MaxSessions int
+
+	// MasterKey is the app's master key, which X-Parse-Master-Key headers must match.
+	// Without one, requests sending the header are rejected.
+	MasterKey string
+	// DisallowMasterKey rejects requests sending an X-Parse-Master-Key header, leaving
+	// master key operations to an admin handler.
+	DisallowMasterKey bool
+
+	mu       sync.Mutex
+	sessions map[string]jwtSession      // by UserClaim value
+	pending  map[string]*jwtSessionCall // sessions being created, by UserClaim value
+}
+
+// jwtSessionCall is the creation of a session shared by the concurrent requests of a
+// user, done once closed.
+type jwtSessionCall struct {
+	done    chan struct{}
+	session jwtSession
+	err     error
+}
+
+type jwtSession struct {
+	id      string // objectId of the _Session
+	userID  string
+	token   string
+	expires time.Time
+}
+
+// jwtSessionMargin is how long before their expiry sessions of JWT users are replaced.
+const jwtSessionMargin = time.Minute
+
+func (a *ParseAuthenticator) Authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	identity := headerIdentity(r)
+	if identity.MasterKey != "" {
+		if a.DisallowMasterKey || a.MasterKey == "" {
+			return nil, fmt.Errorf("%w: the master key is not accepted here", parse.ErrUnauthorized)
+		}
+		if subtle.ConstantTimeCompare([]byte(identity.MasterKey), []byte(a.MasterKey)) != 1 {
+			return nil, fmt.Errorf("%w: invalid master key", parse.ErrUnauthorized)
+		}
+		identity.Master = true
+	}
+	if token := bearerToken(r); a.JWT != nil && token != "" && strings.Count(token, ".") == 2 {
+		if r.Header.Get("X-Parse-Session-Token") != "" {
+			return nil, fmt.Errorf("%w: both a session token and a JWT were sent", parse.ErrUnauthorized)
+		}
+		claims, err := a.JWT.Verify(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", parse.ErrUnauthorized, err)
+		}
+		session, err := a.jwtSession(claims)
+		if err != nil {
+			return nil, err
+		}
+		identity.SessionToken = session.token
+		identity.UserID = session.userID
+		identity.Claims = claims
+	}
+	return NewAuthContext(ctx, identity, identity.authed(a.Client)), nil
+}
+
+// jwtSession returns a session of the user named by claims.
+func (a *ParseAuthenticator) jwtSession(claims map[string]interface{}) (jwtSession, error) {
+	claim := a.UserClaim
+	if claim == "" {
+		claim = "sub"
+	}
+	name, _ := claims[claim].(string)
+	if name == "" {
+		return jwtSession{}, fmt.Errorf("%w: JWT has no '%s' claim", parse.ErrUnauthorized, claim)
+	}
+	a.mu.Lock()
+	if session, ok := a.sessions[name]; ok && time.Now().Add(jwtSessionMargin).Before(session.expires) {
+		a.mu.Unlock()
+		return session, nil
+	}
+	if call, ok := a.pending[name]; ok {
+		a.mu.Unlock()
+		<-call.done
+		return call.session, call.err
+	}
+	call := &jwtSessionCall{done: make(chan struct{})}
+	if a.pending == nil {
+		a.pending = map[string]*jwtSessionCall{}
+	}
+	a.pending[name] = call
+	a.mu.Unlock()
+
+	// Parse is called without the lock, which only guards the maps
+	call.session, call.err = a.newJWTSession(name)
+	var revoked []jwtSession
+	a.mu.Lock()
+	delete(a.pending, name)
+	if call.err == nil {
+		if replaced, ok := a.sessions[name]; ok {
+			revoked = append(revoked, replaced)
+		} else {
+			revoked = a.evictSessions()
+		}
+		a.sessions[name] = call.session
+	}
+	a.mu.Unlock()
+	close(call.done)
+	a.revokeSessions(revoked)
+	return call.session, call.err
+}
+
+// evictSessions makes room for the session of a new user when a.sessions is full by
+// forgetting expired sessions, then arbitrary ones, and returns the unexpired sessions
+// forgotten. a.mu must be held.
+func (a *ParseAuthenticator) evictSessions() []jwtSession {
+	if a.sessions == nil {
+		a.sessions = map[string]jwtSession{}
+	}
+	max := a.MaxSessions
+	if max <= 0 {
+		max = DefaultMaxJWTSessions
+	}
+	if len(a.sessions) < max {
+		return nil
+	}
+	now := time.Now()
+	for name, session := range a.sessions {
+		if now.After(session.expires) {
+			delete(a.sessions, name)
+		}
+	}
+	var evicted []jwtSession
+	for name, session := range a.sessions {
+		if len(a.sessions) < max {
+			break
+		}
+		delete(a.sessions, name)
+		evicted = append(evicted, session)
+	}
+	return evicted
+}
+
+// revokeSessions deletes sessions. Failures are ignored: the sessions expire anyway.
+func (a *ParseAuthenticator) revokeSessions(sessions []jwtSession) {
+	for _, session := range sessions {
+		a.MasterClient.DeleteSession(session.id)
+	}
+}
+
+// newJWTSession creates a session of the user named name.
+func (a *ParseAuthenticator) newJWTSession(name string) (jwtSession, error) {
+	userID, err := a.jwtUserID(name)
+	if err != nil {
+		return jwtSession{}, err
+	}
+	lifetime := a.SessionLifetime
+	if lifetime == 0 {
+		lifetime = DefaultJWTSessionLifetime
+	}
+	return a.createSession(userID, time.Now().Add(lifetime))
+}
+
+// jwtUserID returns the objectId of the user whose UserField is name.
+func (a *ParseAuthenticator) jwtUserID(name string) (string, error) {
+	if a.MasterClient == nil {
+		return "", fmt.Errorf("%w: JWT users require a master key client", parse.ErrRequiresMasterKey)
+	}
+	field := a.UserField
+	if field == "" {
+		field = "objectId"
+	}
+	where, err := json.Marshal(map[string]interface{}{field: name})
+	if err != nil {
+		return "", err
+	}
+	var users []map[string]interface{}
+	if err := a.MasterClient.QueryClass("_User", &parse.QueryOptions{Where: string(where), Limit: 1}, &users); err != nil {
+		return "", err
+	}
+	if len(users) == 0 {
+		return "", fmt.Errorf("%w: no user with %s %q", parse.ErrUnauthorized, field, name)
+	}
+	userID, _ := users[0]["objectId"].(string)
+	return userID, nil
+}
+
+// createSession creates a session of the user userID expiring at expires.
+func (a *ParseAuthenticator) createSession(userID string, expires time.Time) (jwtSession, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return jwtSession{}, err
+	}
+	token := "r:" + hex.EncodeToString(b)
+	id, _, err := a.MasterClient.CreateClass("_Session", map[string]interface{}{
+		"sessionToken": token,
+		"user":         pointer("_User", userID),
+		"createdWith":  map[string]interface{}{"action": "login", "authProvider": "jwt"},
+		"restricted":   false,
+		"expiresAt":    map[string]interface{}{"__type": "Date", "iso": expires.UTC().Format(parseTimeFormat)},
+		"ACL":          map[string]interface{}{userID: map[string]interface{}{"read": true, "write": true}},
+	})
+	if err != nil {
+		return jwtSession{}, err
+	}
+	return jwtSession{id: id, userID: userID, token: token, expires: expires}, nil
+}
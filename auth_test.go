@@ -0,0 +1,78 @@
+package parse_graphql_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/parsetest"
+	"golang.org/x/net/context"
+)
+
+// masterOnlyEmail is a policy only letting master key requests read the email of users.
+var masterOnlyEmail = &parse_graphql.Policy{Classes: map[string]*parse_graphql.ClassPolicy{
+	"_User": {Fields: map[string]parse_graphql.Access{"email": {parse_graphql.AccessMaster}}},
+}}
+
+func TestMasterKeyMustMatch(t *testing.T) {
+	p := newParse(t)
+	p.AddUser("ann", "pw", map[string]interface{}{"email": "ann@example.com"})
+	e := newEndpoint(t, p, masterOnlyEmail, func(h *handler.ExecutorHandler) {
+		h.Authenticator = &parse_graphql.ParseAuthenticator{Client: p.Client(), MasterKey: parsetest.MasterKey}
+	})
+
+	for _, query := range []string{
+		`{ _User { email } }`,
+		`subscription s { _UserChanged { email, authData } }`,
+	} {
+		r := e.post(t, map[string]string{"X-Parse-Master-Key": "bogus"}, query)
+		if r.status != http.StatusUnauthorized || r.Data != nil {
+			t.Errorf("%s with a forged master key: got %d %v, want 401", query, r.status, r.Data)
+		}
+	}
+
+	r := e.post(t, map[string]string{"X-Parse-Master-Key": parsetest.MasterKey}, `{ _User { email } }`)
+	if users := r.objects(t, 0); len(users) != 1 || users[0]["email"] != "ann@example.com" {
+		t.Errorf("with the master key: got %v, want ann's email", users)
+	}
+	r = e.post(t, nil, `{ _User { email } }`)
+	if users := r.objects(t, 0); len(users) != 1 || users[0]["email"] != nil {
+		t.Errorf("without the master key: got %v, want a null email", users)
+	}
+}
+
+func TestMasterKeyRejected(t *testing.T) {
+	p := newParse(t)
+	for name, a := range map[string]*parse_graphql.ParseAuthenticator{
+		"no master key configured": {Client: p.Client()},
+		"master key disallowed":    {Client: p.Client(), MasterKey: parsetest.MasterKey, DisallowMasterKey: true},
+	} {
+		r, _ := http.NewRequest("POST", "/", nil)
+		r.Header.Set("X-Parse-Master-Key", parsetest.MasterKey)
+		if _, err := a.Authenticate(context.Background(), r); err == nil {
+			t.Errorf("%s: master key accepted", name)
+		}
+	}
+}
+
+// unverifiedAuthenticator passes the master key of requests on without checking it.
+type unverifiedAuthenticator struct{ client *parse.Client }
+
+func (a unverifiedAuthenticator) Authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	identity := &parse_graphql.Identity{MasterKey: r.Header.Get("X-Parse-Master-Key")}
+	return parse_graphql.NewAuthContext(ctx, identity, a.client), nil
+}
+
+func TestPolicyIgnoresUnverifiedMasterKey(t *testing.T) {
+	p := newParse(t)
+	p.AddUser("ann", "pw", map[string]interface{}{"email": "ann@example.com"})
+	e := newEndpoint(t, p, masterOnlyEmail, func(h *handler.ExecutorHandler) {
+		h.Authenticator = unverifiedAuthenticator{p.Client()}
+	})
+	r := e.post(t, map[string]string{"X-Parse-Master-Key": "bogus"}, `{ _User { email } }`)
+	if users := r.objects(t, 0); len(users) != 1 || users[0]["email"] != nil {
+		t.Errorf("got %v, want a null email", users)
+	}
+}
@@ -0,0 +1,126 @@
+package parse_graphql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// AuthProvider verifies the authData clients send to log in with, or link accounts to, a
+// third-party provider before it is sent to Parse. Parse checks the authData of the
+// providers it supports itself; an AuthProvider lets a server reject invalid tokens
+// early, or accept only the providers it knows.
+type AuthProvider interface {
+	// VerifyAuthData returns an error if authData, such as {id, access_token}, does not
+	// authenticate a user of the provider. Clients see it as an UNAUTHENTICATED error.
+	VerifyAuthData(ctx context.Context, authData map[string]interface{}) error
+}
+
+// AuthProviderFunc adapts a function into an AuthProvider.
+type AuthProviderFunc func(ctx context.Context, authData map[string]interface{}) error
+
+func (fn AuthProviderFunc) VerifyAuthData(ctx context.Context, authData map[string]interface{}) error {
+	return fn(ctx, authData)
+}
+
+// anonymousID matches the UUIDs Parse SDKs identify anonymous users with.
+var anonymousID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AnonymousAuth is the AuthProvider of the 'anonymous' provider, which guest users log
+// in with: its authData is {id: "<uuid>"}, generated by the client.
+var AnonymousAuth AuthProvider = AuthProviderFunc(func(ctx context.Context, authData map[string]interface{}) error {
+	id, _ := authData["id"].(string)
+	if !anonymousID.MatchString(id) {
+		return errors.New("'id' must be a UUID")
+	}
+	return nil
+})
+
+// authDataArguments reads the 'provider' and 'authData' arguments of f, verifying the
+// authData with the AuthProvider of the provider, if any. Without a registered
+// AuthProvider, authData is left for Parse to check, unless s.RequireAuthProviders is set.
+func (s *ParseSchema) authDataArguments(ctx context.Context, f *graphql.Field) (string, map[string]interface{}, error) {
+	value, _ := f.Arguments.Get("provider")
+	provider, ok := value.(string)
+	if !ok || provider == "" {
+		return "", nil, argumentErrorf("'provider' must be the name of an authentication provider, such as \"facebook\".")
+	}
+	value, _ = f.Arguments.Get("authData")
+	authData, ok := value.(map[string]interface{})
+	if !ok {
+		return "", nil, argumentErrorf("'authData' must be an object, such as {id: \"...\", access_token: \"...\"}.")
+	}
+	verifier, ok := s.AuthProviders[provider]
+	if !ok {
+		if s.RequireAuthProviders {
+			return "", nil, argumentErrorf("unsupported authentication provider '%s'.", provider)
+		}
+		return provider, authData, nil
+	}
+	if err := verifier.VerifyAuthData(ctx, authData); err != nil {
+		return "", nil, fmt.Errorf("%w: invalid %s authData: %v", parse.ErrUnauthorized, provider, err)
+	}
+	return provider, authData, nil
+}
+
+// logInWith logs in with the authData of a provider, signing up a new user if no user is
+// linked to the account yet.
+func (s *ParseSchema) logInWith(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	provider, authData, err := s.authDataArguments(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	t, traced := tracer.FromContext(ctx)
+	if traced {
+		t.IncQueries(1)
+	}
+	created, err := tracedClient(ctx, s.client).CreateUser(map[string]interface{}{
+		"authData": map[string]interface{}{provider: authData},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the user may have signed up before, with fields Parse does not return here
+	if traced {
+		t.IncQueries(1)
+	}
+	var user map[string]interface{}
+	if err := tracedClient(ctx, s.client.WithSessionToken(created.SessionToken)).CurrentUser(&user); err != nil {
+		return nil, err
+	}
+	user["sessionToken"] = created.SessionToken
+	return s.loggedInUser(user)
+}
+
+// linkWith links the account of a provider to the current user.
+func (s *ParseSchema) linkWith(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	provider, authData, err := s.authDataArguments(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	return s.setAuthData(ctx, provider, authData)
+}
+
+// unlinkWith unlinks the account of a provider from the current user.
+func (s *ParseSchema) unlinkWith(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	value, _ := f.Arguments.Get("provider")
+	provider, ok := value.(string)
+	if !ok || provider == "" {
+		return nil, argumentErrorf("'provider' must be the name of an authentication provider, such as \"facebook\".")
+	}
+	return s.setAuthData(ctx, provider, nil)
+}
+
+// setAuthData sets the authData of provider on the current user, or removes it if
+// authData is nil, and returns the user.
+func (s *ParseSchema) setAuthData(ctx context.Context, provider string, authData map[string]interface{}) (interface{}, error) {
+	return s.updateCurrentUser(ctx, map[string]interface{}{
+		"authData": map[string]interface{}{provider: authData},
+	})
+}
@@ -0,0 +1,55 @@
+package parse_graphql_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tmc/parse_graphql"
+	"golang.org/x/net/context"
+)
+
+func TestAuthProviderRejection(t *testing.T) {
+	p := newParse(t)
+	_, token := p.AddUser("ann", "pw", nil)
+	github := parse_graphql.AuthProviderFunc(func(ctx context.Context, authData map[string]interface{}) error {
+		if authData["access_token"] != "valid" {
+			return errors.New("invalid access token")
+		}
+		return nil
+	})
+	e := newSchemaEndpoint(t, p, func(ps *parse_graphql.ParseSchema) {
+		ps.AuthProviders["github"] = github
+		ps.RequireAuthProviders = true
+	})
+	session := map[string]string{"X-Parse-Session-Token": token}
+
+	for _, test := range []struct {
+		header   map[string]string
+		mutation string
+		code     string
+	}{
+		{nil, `logInWith(provider: "anonymous", authData: {id: "not-a-uuid"}) { sessionToken }`, "UNAUTHENTICATED"},
+		{nil, `logInWith(provider: "github", authData: {id: "1", access_token: "forged"}) { sessionToken }`, "UNAUTHENTICATED"},
+		{session, `linkWith(provider: "github", authData: {id: "1", access_token: "forged"}) { username }`, "UNAUTHENTICATED"},
+		// providers without an AuthProvider are rejected when they are required
+		{nil, `logInWith(provider: "facebook", authData: {id: "1", access_token: "token"}) { sessionToken }`, "BAD_USER_INPUT"},
+		{nil, `logInWith(provider: "github", authData: "token") { sessionToken }`, "BAD_USER_INPUT"},
+	} {
+		r := e.post(t, test.header, `mutation m { `+test.mutation+` }`)
+		if r.Error == nil || r.Error.Extensions["code"] != test.code {
+			t.Errorf("%s: got %v (error %v), want %s", test.mutation, r.Data, r.Error, test.code)
+		}
+	}
+	if users := p.Objects("_User"); len(users) != 1 || users[0]["authData"] != nil {
+		t.Fatalf("rejected authData reached Parse: %v", users)
+	}
+
+	r := e.post(t, nil, `mutation m { logInWith(provider: "github", authData: {id: "1", access_token: "valid"}) { sessionToken } }`)
+	if user, _ := r.field(t, 0).(map[string]interface{}); user["sessionToken"] == nil {
+		t.Errorf("verified authData: got %v (error %v), want a session", r.Data, r.Error)
+	}
+	r = e.post(t, nil, `mutation m { logInWith(provider: "anonymous", authData: {id: "5f6f2d6e-8e4b-4c1a-9d2e-3b8f7a6c5d4e"}) { sessionToken } }`)
+	if user, _ := r.field(t, 0).(map[string]interface{}); user["sessionToken"] == nil {
+		t.Errorf("anonymous: got %v (error %v), want a session", r.Data, r.Error)
+	}
+}
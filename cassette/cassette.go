@@ -0,0 +1,339 @@
+// Package cassette records Parse API traffic to a file and replays it.
+//
+// A Recorder is an http.RoundTripper that forwards requests to Parse and appends every
+// interaction to a Cassette, which it saves after each request. A Replayer serves the
+// recorded responses without network access, so behavior seen against a real app can be
+// reproduced offline:
+//
+//	c, err := cassette.Load("testdata/posts.json")
+//	client.SetHTTPClient(&http.Client{Transport: cassette.NewReplayer(c)})
+//
+// Credentials, session tokens and passwords are redacted from cassettes, and requests
+// are matched on their method, path, query and body.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secrets in recorded interactions.
+const Redacted = "REDACTED"
+
+// redactedHeaders are the request headers whose values are never recorded.
+var redactedHeaders = []string{
+	"X-Parse-Master-Key",
+	"X-Parse-REST-API-Key",
+	"X-Parse-Session-Token",
+}
+
+// redactedFields are the JSON fields, and query parameters, whose values are never
+// recorded.
+var redactedFields = map[string]bool{
+	"password":     true,
+	"sessionToken": true,
+}
+
+// Request is a recorded API request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded API response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is a sequence of interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+
+	mu sync.Mutex
+}
+
+// Load reads a cassette saved with Save.
+func Load(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cassette: parsing %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to path, replacing it atomically.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *Cassette) add(i *Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, i)
+}
+
+// Recorder is an http.RoundTripper recording interactions to a Cassette.
+type Recorder struct {
+	// Transport performs requests. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	Cassette  *Cassette
+	// Path, if set, is where the cassette is saved after every request.
+	Path string
+}
+
+// NewRecorder returns a Recorder appending to the cassette at path, which is created if
+// it does not exist.
+func NewRecorder(path string) (*Recorder, error) {
+	c, err := Load(path)
+	if os.IsNotExist(err) {
+		c, err = &Cassette{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Cassette: c, Path: path}, nil
+}
+
+// RoundTrip performs the request and records it.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.Cassette.add(&Interaction{
+		Request: *request,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     responseHeader(resp.Header),
+			Body:       redactBody(body),
+		},
+	})
+	if r.Path != "" {
+		if err := r.Cassette.Save(r.Path); err != nil {
+			return nil, fmt.Errorf("cassette: saving %s: %v", r.Path, err)
+		}
+	}
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper serving responses recorded in a Cassette.
+//
+// Interactions matching a request are replayed in the order they were recorded; once
+// they are exhausted the last one is repeated. Requests with no matching interaction
+// fail.
+type Replayer struct {
+	cassette *Cassette
+
+	mu   sync.Mutex
+	used map[*Interaction]bool
+}
+
+// NewReplayer returns a Replayer serving the interactions of c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{cassette: c, used: map[*Interaction]bool{}}
+}
+
+// RoundTrip returns the recorded response to req.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	var match *Interaction
+	for _, i := range r.cassette.Interactions {
+		if i.Request.Method != request.Method || i.Request.URL != request.URL || !sameBody(i.Request.Body, request.Body) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match != nil {
+		r.used[match] = true
+	}
+	r.mu.Unlock()
+	if match == nil {
+		return nil, fmt.Errorf("cassette: no recorded interaction for %s %s", request.Method, request.URL)
+	}
+
+	header := http.Header{}
+	for k, v := range match.Response.Header {
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(match.Response.Body)),
+		ContentLength: int64(len(match.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// newRequest describes req as it is recorded, consuming and restoring its body.
+func newRequest(req *http.Request) (*Request, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	header := http.Header{}
+	for k, v := range req.Header {
+		header[k] = v
+	}
+	for _, k := range redactedHeaders {
+		if header.Get(k) != "" {
+			header.Set(k, Redacted)
+		}
+	}
+	return &Request{
+		Method: req.Method,
+		URL:    requestURL(req.URL),
+		Header: header,
+		Body:   redactBody(body),
+	}, nil
+}
+
+// requestURL identifies a request URL independently of the host serving the API: it is
+// the cleaned path followed by the sorted and redacted query parameters.
+func requestURL(u *url.URL) string {
+	path := "/" + strings.TrimLeft(u.Path, "/")
+	query := u.Query()
+	if len(query) == 0 {
+		return path
+	}
+	for k := range query {
+		if redactedFields[k] {
+			query.Set(k, Redacted)
+		}
+	}
+	return path + "?" + query.Encode()
+}
+
+// responseHeader returns the recorded subset of response headers.
+func responseHeader(h http.Header) http.Header {
+	recorded := http.Header{}
+	for _, k := range []string{"Content-Type", "Location", "Retry-After"} {
+		if v, ok := h[k]; ok {
+			recorded[k] = v
+		}
+	}
+	return recorded
+}
+
+// redactBody returns a JSON body with the values of redacted fields replaced. Other
+// bodies are returned unchanged.
+func redactBody(body []byte) string {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return string(body)
+	}
+	if !redactValue(v) {
+		return string(body)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+// redactValue redacts v in place and reports whether anything was redacted.
+func redactValue(v interface{}) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if redactedFields[k] {
+				v[k] = Redacted
+				redacted = true
+				continue
+			}
+			redacted = redactValue(e) || redacted
+		}
+	case []interface{}:
+		for _, e := range v {
+			redacted = redactValue(e) || redacted
+		}
+	}
+	return redacted
+}
+
+// sameBody reports whether two recorded bodies are equal, comparing JSON bodies by value.
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
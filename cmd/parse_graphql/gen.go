@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/tmc/graphql/schema"
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/codegen"
+)
+
+// GenOptions groups the code generation commands.
+type GenOptions struct{}
+
+// SchemaOptions selects the Parse schema code is generated from.
+type SchemaOptions struct {
+	ParseApplicationID string `short:"a" long:"appID" description:"Parse Application ID" env:"PARSE_APPLICATION_ID"`
+	ParseMasterKey     string `short:"m" long:"masterKey" description:"Parse Master Key" env:"PARSE_MASTER_KEY"`
+	Schema             string `long:"schema" description:"Schema snapshot (the response of /1/schemas) to read instead of fetching the app schema"`
+}
+
+// load returns the schema snapshot if one is set, or fetches the schema of the app.
+func (o *SchemaOptions) load() (map[string]*parse.Schema, error) {
+	if o.Schema != "" {
+		f, err := os.Open(o.Schema)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return codegen.LoadSchema(f)
+	}
+	client, err := parse.NewClient(o.ParseApplicationID, "")
+	if err != nil {
+		return nil, err
+	}
+	schema, err := client.WithMasterKey(o.ParseMasterKey).GetFullSchema()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching parse app schema: %v", err)
+	}
+	return schema, nil
+}
+
+// hooks returns the hook functions of the app, or none when reading a schema snapshot.
+func (o *SchemaOptions) hooks() ([]*parse.HookFunction, error) {
+	if o.Schema != "" {
+		return nil, nil
+	}
+	client, err := parse.NewClient(o.ParseApplicationID, "")
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := client.WithMasterKey(o.ParseMasterKey).GetHookFunctions()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching parse app hooks: %v", err)
+	}
+	return hooks, nil
+}
+
+// writeOutput writes generated code to path, or to stdout if path is empty.
+func writeOutput(path string, src []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(path, src, 0644)
+}
+
+type GenGoOptions struct {
+	SchemaOptions
+	Package string `short:"p" long:"package" description:"Package name of the generated code" default:"models"`
+	Output  string `short:"o" long:"output" description:"File to write the generated code to (stdout if unset)"`
+}
+
+type GenClientOptions struct {
+	SchemaOptions
+	TypeScript string `long:"ts" description:"File to write the TypeScript client to"`
+	Go         string `long:"go" description:"File to write the Go client to"`
+	Package    string `short:"p" long:"package" description:"Package name of the Go client" default:"client"`
+}
+
+var (
+	genOptions       GenOptions
+	genGoOptions     GenGoOptions
+	genClientOptions GenClientOptions
+)
+
+func init() {
+	gen, err := optionsParser.AddCommand("gen", "Generate code from the Parse schema", "", &genOptions)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := gen.AddCommand("go", "Generate Go structs for every Parse class", "", &genGoOptions); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := gen.AddCommand("client", "Generate typed clients for GraphQL operations",
+		"Validates the operations of the given .graphql files against the GraphQL schema of the app and generates TypeScript and Go clients executing them.",
+		&genClientOptions); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (c *GenGoOptions) Execute(args []string) error {
+	schema, err := c.load()
+	if err != nil {
+		return err
+	}
+	src, err := codegen.Go(schema, c.Package)
+	if err != nil {
+		return err
+	}
+	return writeOutput(c.Output, src)
+}
+
+func (c *GenClientOptions) Execute(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no .graphql files given")
+	}
+	if c.TypeScript == "" && c.Go == "" {
+		return fmt.Errorf("one of --ts or --go is required")
+	}
+	classes, err := c.load()
+	if err != nil {
+		return err
+	}
+	hooks, err := c.hooks()
+	if err != nil {
+		return err
+	}
+	client, err := parse.NewClient(c.ParseApplicationID, "")
+	if err != nil {
+		return err
+	}
+	parseSchema, err := parse_graphql.NewParseSchema(client, classes, hooks)
+	if err != nil {
+		return err
+	}
+	s := schema.New()
+	if err := parse_graphql.RegisterSchema(s, client, parseSchema, nil); err != nil {
+		return err
+	}
+
+	var (
+		ops     []*codegen.Operation
+		invalid int
+	)
+	for _, path := range args {
+		op, err := codegen.ReadOperation(path)
+		if err == nil {
+			err = codegen.Validate(s, op)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			invalid++
+			continue
+		}
+		ops = append(ops, op)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d operations are invalid", invalid, len(args))
+	}
+
+	if c.TypeScript != "" {
+		src, err := codegen.TypeScript(ops)
+		if err != nil {
+			return err
+		}
+		if err := writeOutput(c.TypeScript, src); err != nil {
+			return err
+		}
+	}
+	if c.Go != "" {
+		src, err := codegen.GoClient(ops, c.Package)
+		if err != nil {
+			return err
+		}
+		if err := writeOutput(c.Go, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmc/parse"
+)
+
+// readyTimeout bounds how long readiness checks wait for Parse, and readyCacheTTL how long
+// their results are reused, sparing Parse a request per probe.
+const (
+	readyTimeout  = 5 * time.Second
+	readyCacheTTL = 2 * time.Second
+)
+
+// health serves the liveness and readiness checks of the server.
+type health struct {
+	// client is the client, authed with the master key, readiness checks reach Parse with.
+	client *parse.Client
+	// schemas is ready once the GraphQL schemas have been built.
+	schemas *schemas
+
+	draining int32 // set once the server shuts down
+
+	mu       sync.Mutex
+	checked  time.Time
+	err      error
+	checking *readyCheck // the check of Parse in progress, if any
+}
+
+// readyCheck is a check of Parse shared by the readiness checks made while it runs, done
+// once closed.
+type readyCheck struct {
+	done chan struct{}
+	err  error
+}
+
+// serveLive answers liveness checks: the process is serving requests.
+func (h *health) serveLive(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// serveReady answers readiness checks: the server is not shutting down, its GraphQL
+// schemas are built and Parse is reachable.
+func (h *health) serveReady(w http.ResponseWriter, r *http.Request) {
+	if err := h.ready(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func (h *health) ready(ctx context.Context) error {
+	if atomic.LoadInt32(&h.draining) != 0 {
+		return errors.New("shutting down")
+	}
+	if !h.schemas.loaded() {
+		return errors.New("schema not loaded")
+	}
+	h.mu.Lock()
+	if time.Since(h.checked) < readyCacheTTL {
+		err := h.err
+		h.mu.Unlock()
+		return err
+	}
+	check := h.checking
+	if check == nil {
+		check = &readyCheck{done: make(chan struct{})}
+		h.checking = check
+		go h.checkParse(check)
+	}
+	h.mu.Unlock()
+	select {
+	case <-check.done:
+		return check.err
+	case <-ctx.Done():
+		return fmt.Errorf("parse unreachable: %v", ctx.Err())
+	}
+}
+
+// checkParse fetches the schema of the _User class, which every app has, giving up after
+// readyTimeout, and records the result of check.
+func (h *health) checkParse(check *readyCheck) {
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	if _, err := h.client.WithContext(ctx).GetClassSchema("_User"); err != nil {
+		check.err = fmt.Errorf("parse unreachable: %v", err)
+	}
+	h.mu.Lock()
+	h.err = check.err
+	h.checked = time.Now()
+	h.checking = nil
+	h.mu.Unlock()
+	close(check.done)
+}
+
+// drain marks the server as shutting down, failing readiness checks from then on.
+func (h *health) drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
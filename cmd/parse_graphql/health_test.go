@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tmc/graphql/executor"
+	"github.com/tmc/graphql/schema"
+	"github.com/tmc/parse"
+)
+
+func TestReadySharesSlowChecks(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Write([]byte(`{"className": "_User", "fields": {}}`))
+	}))
+	defer s.Close()
+	defer func(baseURL string) { parse.BaseURL = baseURL }(parse.BaseURL)
+	parse.BaseURL = s.URL + "/"
+
+	client, _ := parse.NewClient("app", "key")
+	h := &health{client: client.WithMasterKey("master"), schemas: &schemas{public: executor.New(schema.New())}}
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		if err := h.ready(ctx); err == nil {
+			t.Errorf("check %d: ready while Parse has not answered", i)
+		}
+		cancel()
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("got %d requests to Parse, want one shared by the checks", n)
+	}
+
+	close(release)
+	if err := h.ready(context.Background()); err != nil {
+		t.Errorf("got %v once Parse answered", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("got %d requests to Parse, want the cached result to be reused", n)
+	}
+}
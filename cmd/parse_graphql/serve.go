@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 import (
 	"github.com/tmc/graphql/executor"
@@ -12,13 +21,73 @@ import (
 	"github.com/tmc/graphql/schema"
 	"github.com/tmc/parse"
 	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/cassette"
 )
 
 type ServeOptions struct {
 	ListenAddr         string `short:"l" long:"listen" description:"Listen address" default:":8080"`
+	TLSCert            string `long:"tlsCert" description:"TLS certificate file (PEM) to serve HTTPS with"`
+	TLSKey             string `long:"tlsKey" description:"TLS private key file (PEM) of --tlsCert"`
 	ParseApplicationID string `short:"a" long:"appID" description:"Parse Application ID" env:"PARSE_APPLICATION_ID"`
 	ParseMasterKey     string `short:"m" long:"masterKey" description:"Parse Master Key" env:"PARSE_MASTER_KEY"`
 	ParseRESTAPIKey    string `short:"w" long:"restApiKey" description:"Parse REST API Key" env:"PARSE_REST_API_KEY"`
+
+	ReadTimeout     time.Duration `long:"readTimeout" description:"Maximum duration for reading requests, bodies included" default:"30s"`
+	WriteTimeout    time.Duration `long:"writeTimeout" description:"Maximum duration for executing requests and writing their responses, subscription streams excepted" default:"60s"`
+	IdleTimeout     time.Duration `long:"idleTimeout" description:"How long idle keep-alive connections are kept open" default:"120s"`
+	ShutdownTimeout time.Duration `long:"shutdownTimeout" description:"How long requests in progress may take to complete on SIGTERM or SIGINT" default:"30s"`
+	HealthPath      string        `long:"healthPath" description:"Path to serve the liveness check on (disabled if empty)" default:"/healthz"`
+	ReadyPath       string        `long:"readyPath" description:"Path to serve the readiness check on, which checks the schema is loaded and Parse is reachable (disabled if empty)" default:"/readyz"`
+
+	LiveQueryURL string        `long:"liveQueryURL" description:"Parse LiveQuery websocket URL to feed subscriptions from (polls for changes if unset)" env:"PARSE_LIVE_QUERY_URL"`
+	PollInterval time.Duration `long:"pollInterval" description:"Interval between polls for subscription changes" default:"5s"`
+
+	PersistedQueries         string `long:"persistedQueries" description:"Persisted query manifest (.json) or directory of queries (in memory if unset)"`
+	PersistedQueriesStrict   bool   `long:"persistedQueriesStrict" description:"Reject queries missing from the persisted query store (requires --persistedQueries)"`
+	PersistedQueriesWritable bool   `long:"persistedQueriesWritable" description:"Register queries in the --persistedQueries directory"`
+	PersistedQueriesMax      int    `long:"persistedQueriesMax" description:"Maximum number of queries registered in memory or in the --persistedQueries directory" default:"10000"`
+
+	ParseRetries       int           `long:"parseRetries" description:"Maximum number of retries of failed idempotent Parse requests" default:"3"`
+	ParseRetryDelay    time.Duration `long:"parseRetryDelay" description:"Initial delay between retries of Parse requests" default:"200ms"`
+	ParseRetryMaxDelay time.Duration `long:"parseRetryMaxDelay" description:"Maximum delay between retries of Parse requests" default:"5s"`
+
+	Policy string `long:"policy" description:"Policy file (.json) restricting the classes and fields exposed and who may read them"`
+
+	MaxUploadSize int64 `long:"maxUploadSize" description:"Maximum size in bytes of multipart requests uploading files" default:"33554432"`
+
+	JWTSecret    string        `long:"jwtSecret" description:"HMAC secret bearer JWTs may be signed with" env:"JWT_SECRET"`
+	JWTKeys      string        `long:"jwtKeys" description:"JWKS or PEM file of the keys bearer JWTs may be signed with"`
+	JWTIssuer    string        `long:"jwtIssuer" description:"Required issuer ('iss' claim) of JWTs"`
+	JWTAudience  string        `long:"jwtAudience" description:"Required audience ('aud' claim) of JWTs"`
+	JWTUserClaim string        `long:"jwtUserClaim" description:"JWT claim naming the Parse user of a request" default:"sub"`
+	JWTUserField string        `long:"jwtUserField" description:"_User field the user claim of JWTs is matched against" default:"objectId"`
+	JWTLeeway    time.Duration `long:"jwtLeeway" description:"Clock skew tolerated when checking the expiry of JWTs" default:"30s"`
+	JWTNoExpiry  bool          `long:"jwtAllowNoExpiry" description:"Accept JWTs without an 'exp' claim"`
+
+	SessionCacheTTL  time.Duration `long:"sessionCacheTTL" description:"How long to cache the users of session tokens for fields such as me (disabled if zero)" default:"0"`
+	SessionCacheSize int           `long:"sessionCacheSize" description:"Maximum number of session tokens to cache users of" default:"10000"`
+
+	CORSOrigins     []string      `long:"corsOrigin" description:"Origin allowed to make cross-origin requests, such as https://*.example.com or * (repeatable; same-origin only if unset)" env:"CORS_ORIGINS" env-delim:","`
+	CORSHeaders     []string      `long:"corsHeader" description:"Request header cross-origin requests may send (repeatable)" default:"Content-Type" default:"Authorization" default:"X-Parse-Session-Token" default:"X-Trace-ID"`
+	CORSMethods     []string      `long:"corsMethod" description:"Method cross-origin requests may use (repeatable)" default:"GET" default:"POST"`
+	CORSCredentials bool          `long:"corsCredentials" description:"Let browsers send cookies and HTTP authentication with cross-origin requests"`
+	CORSMaxAge      time.Duration `long:"corsMaxAge" description:"How long browsers may cache the result of preflight requests" default:"10m"`
+
+	RateLimitRequests        string   `long:"rateLimitRequests" description:"Operations each client may execute, as <n>/<duration> such as 60/1m (unlimited if unset)"`
+	RateLimitQueries         string   `long:"rateLimitQueries" description:"Parse calls the operations of each client may make, as <n>/<duration> (unlimited if unset)"`
+	RateLimitOperations      []string `long:"rateLimitOperation" description:"Limits of the operations of a name, as <name>=<requests>[,<queries>] such as search=10/1m,500/1m (repeatable)"`
+	RateLimitAddressRequests string   `long:"rateLimitAddressRequests" description:"Operations each client address may execute whatever the keys it sends, as <n>/<duration> (unlimited if unset)"`
+	RateLimitKeyHeader       string   `long:"rateLimitKeyHeader" description:"Header, set by a gateway checking API keys, carrying the API key rate limits are keyed by for clients without a session"`
+	TrustedProxies           int      `long:"trustedProxies" description:"Number of proxies in front of the server appending client addresses to X-Forwarded-For (header ignored if zero)"`
+
+	Record string `long:"record" description:"Record Parse API traffic to this cassette file"`
+	Replay string `long:"replay" description:"Replay Parse API traffic from this cassette file instead of contacting Parse"`
+
+	MetricsPath string `long:"metricsPath" description:"Path to serve Prometheus metrics on (disabled if empty)" default:"/metrics"`
+
+	AdminPath     string `long:"adminPath" description:"Path to serve the master key admin endpoint on, if --adminSecret or --adminClientCA is set" default:"/admin"`
+	AdminSecret   string `long:"adminSecret" description:"Secret admin requests send in their X-Admin-Secret header" env:"ADMIN_SECRET"`
+	AdminClientCA string `long:"adminClientCA" description:"CA certificates file (PEM) issuing the TLS client certificates admin requests may authenticate with; requires --tlsCert"`
 }
 
 var serveOptions ServeOptions
@@ -29,39 +98,423 @@ func init() {
 	}
 }
 
+// String describes c for the startup log, with its keys and secrets redacted.
+func (c *ServeOptions) String() string {
+	type options ServeOptions // without the String method
+	redacted := options(*c)
+	for _, secret := range []*string{&redacted.ParseMasterKey, &redacted.ParseRESTAPIKey, &redacted.JWTSecret, &redacted.AdminSecret} {
+		if *secret != "" {
+			*secret = "REDACTED"
+		}
+	}
+	return fmt.Sprintf("%+v", redacted)
+}
+
 func (c *ServeOptions) Execute(args []string) error {
 	log.Println(c)
-	schema := schema.New()
 
 	client, err := parse.NewClient(c.ParseApplicationID, c.ParseRESTAPIKey)
 	if err != nil {
 		return err
 	}
+	if err := c.setupCassette(client); err != nil {
+		return err
+	}
+	metrics := parse_graphql.NewMetrics()
+	client.Observe(metrics.ObserveParseRequest)
+	client.SetRetryPolicy(&parse.RetryPolicy{
+		MaxRetries: c.ParseRetries,
+		BaseDelay:  c.ParseRetryDelay,
+		MaxDelay:   c.ParseRetryMaxDelay,
+	})
+	client = client.WithRetryObserver(metrics.ObserveParseRetry)
 	mClient := client.WithMasterKey(c.ParseMasterKey)
 	client.TraceOn(log.New(os.Stdout, "[parse] ", log.LstdFlags))
-	classes, err := mClient.GetFullSchema()
-	if err != nil {
-		return fmt.Errorf("error fetching parse app schema: %v", err)
+	s := &schemas{client: client, mClient: mClient, admin: c.adminEnabled()}
+	if c.Policy != "" {
+		if s.policy, err = parse_graphql.LoadPolicy(c.Policy); err != nil {
+			return fmt.Errorf("error loading policy: %v", err)
+		}
+	}
+	if c.SessionCacheTTL > 0 {
+		s.sessionCache = parse_graphql.NewSessionCache(c.SessionCacheTTL, c.SessionCacheSize)
 	}
-	hooks, err := mClient.GetHookFunctions()
+	s.changes = &parse_graphql.PollingChangeSource{
+		Client:   client,
+		Interval: c.PollInterval,
+	}
+	if c.LiveQueryURL != "" {
+		s.changes = &parse_graphql.LiveQueryChangeSource{
+			URL:           c.LiveQueryURL,
+			ApplicationID: c.ParseApplicationID,
+			RESTAPIKey:    c.ParseRESTAPIKey,
+		}
+	}
+	public, admin, err := s.build()
 	if err != nil {
-		return fmt.Errorf("error fetching parse app hooks: %v", err)
+		return err
+	}
+	s.public = executor.New(public)
+
+	h := handler.New(s.public)
+	if h.Queries, err = c.queryStore(); err != nil {
+		return fmt.Errorf("error loading persisted queries: %v", err)
 	}
-	parseSchema, err := parse_graphql.NewParseSchema(client, classes, hooks)
+	h.StrictQueries = c.PersistedQueriesStrict
+	h.Observe = metrics.ObserveOperation
+	h.ErrorExtensions = parse_graphql.ErrorExtensions
+	h.MaxUploadSize = c.MaxUploadSize
+	if h.CORS, err = c.cors(); err != nil {
+		return err
+	}
+	if h.Authenticator, err = c.authenticator(client, mClient); err != nil {
+		return err
+	}
+	limiter, err := c.limiter()
 	if err != nil {
 		return err
 	}
-	for _, class := range parseSchema.Schema {
-		parseClass, err := parse_graphql.NewParseClass(client, class.ClassName, parseSchema.Schema)
-		if err != nil {
+	if limiter != nil {
+		limiter.Client = client
+		limiter.SessionCache = s.sessionCache
+		h.Limiter = limiter
+	}
+
+	var clientCAs *x509.CertPool
+	if c.AdminClientCA != "" {
+		if clientCAs, err = c.adminClientCAs(); err != nil {
 			return err
 		}
-		schema.Register(parseClass)
 	}
-	schema.Register(parseSchema) // for top-level fields
-	executor := executor.New(schema)
 
 	mux := http.NewServeMux()
-	mux.Handle("/", handler.New(executor))
-	return http.ListenAndServe(c.ListenAddr, mux)
+	mux.Handle("/", h)
+	handlers := []*handler.ExecutorHandler{h}
+	if c.adminEnabled() {
+		if c.ParseMasterKey == "" {
+			return fmt.Errorf("the admin endpoint requires --masterKey")
+		}
+		s.adminExecutor = executor.New(admin)
+		adminHandler := c.adminHandler(s.adminExecutor, client, clientCAs, metrics)
+		mux.Handle(c.AdminPath, adminHandler)
+		handlers = append(handlers, adminHandler)
+	}
+	if c.MetricsPath != "" {
+		mux.Handle(c.MetricsPath, metrics)
+	}
+	health := &health{client: mClient, schemas: s}
+	if c.HealthPath != "" {
+		mux.HandleFunc(c.HealthPath, health.serveLive)
+	}
+	if c.ReadyPath != "" {
+		mux.HandleFunc(c.ReadyPath, health.serveReady)
+	}
+	return c.listenAndServe(mux, health, clientCAs, handlers)
+}
+
+// listenAndServe serves mux until the process receives SIGTERM or SIGINT, then stops
+// accepting connections, ends the subscriptions of streams and waits up to
+// ShutdownTimeout for the other requests in progress to complete. Admin requests may
+// authenticate with client certificates issued by clientCAs, if not nil.
+func (c *ServeOptions) listenAndServe(mux http.Handler, health *health, clientCAs *x509.CertPool, streams []*handler.ExecutorHandler) error {
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("--tlsCert and --tlsKey go together")
+	}
+	srv := &http.Server{
+		Addr:         c.ListenAddr,
+		Handler:      mux,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout,
+		IdleTimeout:  c.IdleTimeout,
+	}
+	if clientCAs != nil {
+		srv.TLSConfig = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: clientCAs}
+	}
+	// subscription streams would hold up the shutdown
+	for _, h := range streams {
+		srv.RegisterOnShutdown(h.CloseStreams)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if c.TLSCert != "" {
+			errs <- srv.ListenAndServeTLS(c.TLSCert, c.TLSKey)
+		} else {
+			errs <- srv.ListenAndServe()
+		}
+	}()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-signals:
+		log.Printf("received %v, shutting down", sig)
+	}
+	health.drain()
+	ctx, stop := context.WithTimeout(context.Background(), c.ShutdownTimeout)
+	defer stop()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("error shutting down, closing remaining connections:", err)
+		srv.Close()
+		return fmt.Errorf("error shutting down: %v", err)
+	}
+	return nil
+}
+
+// adminClientCAs loads the certificates of AdminClientCA.
+func (c *ServeOptions) adminClientCAs() (*x509.CertPool, error) {
+	if c.TLSCert == "" {
+		return nil, fmt.Errorf("--adminClientCA requires --tlsCert")
+	}
+	b, err := ioutil.ReadFile(c.AdminClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("error loading admin client CAs: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("error loading admin client CAs: no certificates found in %s", c.AdminClientCA)
+	}
+	return pool, nil
+}
+
+// setupCassette makes client record or replay its traffic as selected by the Record and
+// Replay options.
+func (c *ServeOptions) setupCassette(client *parse.Client) error {
+	switch {
+	case c.Record != "" && c.Replay != "":
+		return fmt.Errorf("--record and --replay are mutually exclusive")
+	case c.Record != "":
+		recorder, err := cassette.NewRecorder(c.Record)
+		if err != nil {
+			return fmt.Errorf("error opening cassette: %v", err)
+		}
+		client.SetHTTPClient(&http.Client{Transport: recorder})
+	case c.Replay != "":
+		recording, err := cassette.Load(c.Replay)
+		if err != nil {
+			return fmt.Errorf("error loading cassette: %v", err)
+		}
+		client.SetHTTPClient(&http.Client{Transport: cassette.NewReplayer(recording)})
+	}
+	return nil
+}
+
+// adminEnabled reports whether the admin endpoint is enabled, which takes an admin secret
+// or client CAs.
+func (c *ServeOptions) adminEnabled() bool {
+	return (c.AdminSecret != "" || c.AdminClientCA != "") && c.AdminPath != ""
+}
+
+// adminHandler returns the handler of the admin endpoint, which executes operations with
+// the master key against a schema of its own, including the admin-only root fields.
+func (c *ServeOptions) adminHandler(e *executor.Executor, client *parse.Client, clientCAs *x509.CertPool, metrics *parse_graphql.Metrics) *handler.ExecutorHandler {
+	h := handler.New(e)
+	h.Observe = metrics.ObserveOperation
+	h.ErrorExtensions = parse_graphql.ErrorExtensions
+	h.MaxUploadSize = c.MaxUploadSize
+	h.Authenticator = &parse_graphql.AdminAuthenticator{
+		Client:    client,
+		MasterKey: c.ParseMasterKey,
+		Secret:    c.AdminSecret,
+		ClientCAs: clientCAs,
+	}
+	return h
+}
+
+// schemas builds the GraphQL schemas of the endpoints from the schema of the Parse app,
+// and rebuilds them when the admin endpoint changes it.
+type schemas struct {
+	client, mClient *parse.Client
+	policy          *parse_graphql.Policy
+	sessionCache    *parse_graphql.SessionCache
+	changes         parse_graphql.ChangeSource
+	admin           bool
+
+	reloading             sync.Mutex // serializes reloads
+	mu                    sync.Mutex // guards the executors
+	public, adminExecutor *executor.Executor
+}
+
+// build returns the schemas of the public and, if enabled, admin endpoints, built from the
+// current schema and hook functions of the Parse app.
+func (s *schemas) build() (public, admin *schema.Schema, err error) {
+	classes, err := s.mClient.GetFullSchema()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching parse app schema: %v", err)
+	}
+	hooks, err := s.mClient.GetHookFunctions()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching parse app hooks: %v", err)
+	}
+	parseSchema, err := parse_graphql.NewParseSchema(s.client, classes, hooks)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.policy != nil {
+		if err := s.policy.Validate(parseSchema.Schema); err != nil {
+			return nil, nil, err
+		}
+		parseSchema.Policy = s.policy
+	}
+	parseSchema.SessionCache = s.sessionCache
+	public = schema.New()
+	if err := parse_graphql.RegisterSchema(public, s.client, parseSchema, s.changes); err != nil {
+		return nil, nil, err
+	}
+	if !s.admin {
+		return public, nil, nil
+	}
+	admin = schema.New()
+	if err := parse_graphql.RegisterAdminSchema(admin, s.client, parseSchema, s.changes, s.reload); err != nil {
+		return nil, nil, err
+	}
+	return public, admin, nil
+}
+
+// loaded reports whether the schemas have been built.
+func (s *schemas) loaded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.public != nil
+}
+
+// reload rebuilds the schemas of the endpoints, which keep their current ones on errors.
+func (s *schemas) reload() error {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+	// building calls Parse, so only swapping the schemas in holds mu
+	public, admin, err := s.build()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.public.SetSchema(public)
+	if s.adminExecutor != nil {
+		s.adminExecutor.SetSchema(admin)
+	}
+	log.Println("reloaded the GraphQL schema")
+	return nil
+}
+
+// limiter returns the rate limiter configured by the rate limit options, nil if none is
+// set.
+func (c *ServeOptions) limiter() (*parse_graphql.RateLimiter, error) {
+	if c.RateLimitRequests == "" && c.RateLimitQueries == "" && c.RateLimitAddressRequests == "" && len(c.RateLimitOperations) == 0 {
+		return nil, nil
+	}
+	l := &parse_graphql.RateLimiter{
+		Operations:     map[string]parse_graphql.OperationRates{},
+		KeyHeader:      c.RateLimitKeyHeader,
+		TrustedProxies: c.TrustedProxies,
+	}
+	for _, rate := range []struct {
+		flag, value string
+		rate        *parse_graphql.Rate
+	}{
+		{"--rateLimitRequests", c.RateLimitRequests, &l.Requests},
+		{"--rateLimitQueries", c.RateLimitQueries, &l.Queries},
+		{"--rateLimitAddressRequests", c.RateLimitAddressRequests, &l.AddressRequests},
+	} {
+		if rate.value == "" {
+			continue
+		}
+		var err error
+		if *rate.rate, err = parse_graphql.ParseRate(rate.value); err != nil {
+			return nil, fmt.Errorf("%s: %v", rate.flag, err)
+		}
+	}
+	for _, op := range c.RateLimitOperations {
+		i := strings.Index(op, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("--rateLimitOperation: invalid limits %q, want <name>=<requests>[,<queries>]", op)
+		}
+		var rates parse_graphql.OperationRates
+		limits := strings.SplitN(op[i+1:], ",", 2)
+		for j, limit := range limits {
+			rate, err := parse_graphql.ParseRate(limit)
+			if err != nil {
+				return nil, fmt.Errorf("--rateLimitOperation: %v", err)
+			}
+			if j == 0 {
+				rates.Requests = rate
+			} else {
+				rates.Queries = rate
+			}
+		}
+		l.Operations[op[:i]] = rates
+	}
+	return l, nil
+}
+
+// cors returns the CORS policy of the public endpoint, nil if no origin is allowed.
+func (c *ServeOptions) cors() (*handler.CORS, error) {
+	if len(c.CORSOrigins) == 0 {
+		return nil, nil
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin == "*" && c.CORSCredentials {
+			return nil, fmt.Errorf("--corsCredentials cannot be combined with --corsOrigin '*'")
+		}
+	}
+	return &handler.CORS{
+		AllowedOrigins:   c.CORSOrigins,
+		AllowedHeaders:   c.CORSHeaders,
+		AllowedMethods:   c.CORSMethods,
+		AllowCredentials: c.CORSCredentials,
+		MaxAge:           c.CORSMaxAge,
+	}, nil
+}
+
+// authenticator returns the authenticator configured by the JWT options, which accepts
+// Parse session tokens and, if a JWT secret or keys are set, JWTs.
+func (c *ServeOptions) authenticator(client, mClient *parse.Client) (*parse_graphql.ParseAuthenticator, error) {
+	a := &parse_graphql.ParseAuthenticator{
+		Client:       client,
+		MasterClient: mClient,
+		UserClaim:    c.JWTUserClaim,
+		UserField:    c.JWTUserField,
+		// the public endpoint never acts with the master key, which is left to the admin
+		// endpoint
+		DisallowMasterKey: true,
+	}
+	if c.JWTSecret == "" && c.JWTKeys == "" {
+		return a, nil
+	}
+	a.JWT = &parse_graphql.JWTVerifier{Issuer: c.JWTIssuer, Audience: c.JWTAudience, Leeway: c.JWTLeeway, AllowNoExpiry: c.JWTNoExpiry}
+	if c.JWTSecret != "" {
+		a.JWT.AddHMACKey("", []byte(c.JWTSecret))
+	}
+	if c.JWTKeys != "" {
+		if err := a.JWT.LoadKeys(c.JWTKeys); err != nil {
+			return nil, fmt.Errorf("error loading JWT keys: %v", err)
+		}
+	}
+	return a, nil
+}
+
+// queryStore returns the persisted query store selected by the PersistedQueries option.
+func (c *ServeOptions) queryStore() (handler.QueryStore, error) {
+	switch {
+	case c.PersistedQueries == "":
+		if c.PersistedQueriesStrict {
+			// an empty store would reject every query
+			return nil, fmt.Errorf("--persistedQueriesStrict requires --persistedQueries")
+		}
+		s := handler.NewMemoryQueryStore()
+		s.MaxQueries = c.PersistedQueriesMax
+		return s, nil
+	case strings.HasSuffix(c.PersistedQueries, ".json"):
+		return handler.LoadQueryManifest(c.PersistedQueries)
+	default:
+		s, err := handler.NewFileQueryStore(c.PersistedQueries)
+		if err != nil {
+			return nil, err
+		}
+		s.Writable = c.PersistedQueriesWritable
+		s.MaxQueries = c.PersistedQueriesMax
+		return s, nil
+	}
 }
@@ -0,0 +1,14 @@
+package main
+
+import "testing"
+
+func TestQueryStoreStrict(t *testing.T) {
+	c := &ServeOptions{PersistedQueriesStrict: true}
+	if _, err := c.queryStore(); err == nil {
+		t.Error("strict mode with an empty in-memory store: got no error")
+	}
+	c.PersistedQueries = t.TempDir()
+	if _, err := c.queryStore(); err != nil {
+		t.Errorf("strict mode with a directory: got %v", err)
+	}
+}
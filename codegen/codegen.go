@@ -0,0 +1,131 @@
+// Package codegen generates code from the schema of a Parse app.
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/parse"
+)
+
+// LoadSchema reads a schema snapshot: the body of a response to /1/schemas, or the list
+// of class schemas it contains.
+func LoadSchema(r io.Reader) (map[string]*parse.Schema, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("codegen: reading schema: %v", err)
+	}
+	var schemas []*parse.Schema
+	if err := json.Unmarshal(raw, &schemas); err != nil {
+		var response struct {
+			Results []*parse.Schema `json:"results"`
+		}
+		if err := json.Unmarshal(raw, &response); err != nil {
+			return nil, fmt.Errorf("codegen: reading schema: %v", err)
+		}
+		schemas = response.Results
+	}
+	result := make(map[string]*parse.Schema, len(schemas))
+	for _, s := range schemas {
+		result[s.ClassName] = s
+	}
+	return result, nil
+}
+
+// classNames returns the class names of schema in a stable order.
+func classNames(schema map[string]*parse.Schema) []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fieldNames returns the field names of class in a stable order.
+func fieldNames(class *parse.Schema) []string {
+	names := make([]string, 0, len(class.Fields))
+	for name := range class.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initialisms are words spelled in upper case in identifiers.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// words splits a Parse class or field name into words at underscores, dashes and case
+// changes.
+func words(name string) []string {
+	var (
+		result []string
+		word   []rune
+	)
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				result = append(result, string(word))
+				word = nil
+			}
+			continue
+		}
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				result = append(result, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		result = append(result, string(word))
+	}
+	return result
+}
+
+// exportedName converts a Parse class or field name into an exported identifier, such
+// as 'UserID' for 'user_id'.
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, w := range words(name) {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(w)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	if s := b.String(); unicode.IsDigit([]rune(s)[0]) {
+		return "X" + s
+	}
+	return b.String()
+}
+
+// uniqueName returns name, or name followed by suffix and possibly a number if name is
+// taken, and marks the result as taken.
+func uniqueName(name, suffix string, taken map[string]bool) string {
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = name + suffix
+		if i > 2 {
+			candidate = fmt.Sprintf("%s%s%d", name, suffix, i-1)
+		}
+	}
+	taken[candidate] = true
+	return candidate
+}
@@ -0,0 +1,196 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// goScalarTypes maps GraphQL leaf types to the Go types of generated fields. Fields
+// holding arbitrary JSON are interface{}.
+var goScalarTypes = map[string]string{
+	"String":  "*string",
+	"Float":   "*float64",
+	"Int":     "*int",
+	"Boolean": "*bool",
+	"Date":    "*parse.Date",
+}
+
+// GoClient generates a Go source file of package pkg declaring a Client for the GraphQL
+// endpoint of parse_graphql with a method, and result types, for each of ops. The
+// operations must have been validated.
+func GoClient(ops []*Operation, pkg string) ([]byte, error) {
+	types, err := operationTypes(ops)
+	if err != nil {
+		return nil, err
+	}
+	usesParse := false
+	funcs := template.FuncMap{
+		"goType": func(s *Selection) string {
+			t := goType(s)
+			usesParse = usesParse || strings.Contains(t, "parse.")
+			return t
+		},
+		"fieldName": func(s *Selection, taken map[string]bool) string {
+			return uniqueName(exportedName(s.Key), "Field", taken)
+		},
+		"variableType": func(v variable) string {
+			t := goScalarTypes[v.Type]
+			if t == "" {
+				return "interface{}"
+			}
+			usesParse = usesParse || strings.Contains(t, "parse.")
+			return t
+		},
+		"newTaken": func() map[string]bool { return map[string]bool{} },
+		"goString": goString,
+	}
+	var body bytes.Buffer
+	if err := template.Must(template.New("client").Funcs(funcs).Parse(goClientTemplate)).Execute(&body, types); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by parse_graphql gen client. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	buf.WriteString("import (\n\t\"bytes\"\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"net/http\"\n")
+	if usesParse {
+		buf.WriteString("\n\t\"github.com/tmc/parse\"\n")
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+// goType returns the Go type of the field selected by s.
+func goType(s *Selection) string {
+	t := "interface{}"
+	switch {
+	case s.typeName != "":
+		t = "*" + s.typeName
+	case goScalarTypes[s.Type] != "":
+		t = goScalarTypes[s.Type]
+	}
+	if s.List {
+		return "[]" + t
+	}
+	return t
+}
+
+// goString returns a Go string literal of s, raw if possible.
+func goString(s string) string {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+const goClientTemplate = `
+// Client executes operations against a parse_graphql server.
+type Client struct {
+	// Endpoint is the URL of the GraphQL endpoint.
+	Endpoint string
+	// HTTPClient performs requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Header is added to every request, for example to set X-Parse-Session-Token.
+	Header http.Header
+}
+
+// Error is an error returned by the server.
+type Error struct {
+	Message    string                 ` + "`" + `json:"message"` + "`" + `
+	Extensions map[string]interface{} ` + "`" + `json:"extensions,omitempty"` + "`" + `
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// execute runs query with variables and decodes the value of each root field into the
+// corresponding target.
+func (c *Client) execute(ctx context.Context, query string, variables interface{}, targets ...interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Data  []json.RawMessage ` + "`" + `json:"data"` + "`" + `
+		Error *Error            ` + "`" + `json:"error"` + "`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding response (%s): %v", resp.Status, err)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if len(result.Data) != len(targets) {
+		return fmt.Errorf("expected %d results, got %d", len(targets), len(result.Data))
+	}
+	for i, target := range targets {
+		if err := json.Unmarshal(result.Data[i], target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{range .}}{{$op := .}}
+// {{.Result}} is the result of the {{.Name}} {{.Kind}}.
+type {{.Result}} struct {
+{{- $taken := newTaken}}
+{{- range .Selections}}
+	{{fieldName . $taken}} {{goType .}} ` + "`" + `json:"{{.Key}}"` + "`" + `
+{{- end}}
+}
+{{range .Objects}}
+// {{.Name}} is an object selected by the {{$op.Name}} {{$op.Kind}}.
+type {{.Name}} struct {
+{{- $taken := newTaken}}
+{{- range .Fields}}
+	{{fieldName . $taken}} {{goType .}} ` + "`" + `json:"{{.Key}}"` + "`" + `
+{{- end}}
+}
+{{end}}
+{{- if .VariablesType}}
+// {{.VariablesType}} are the variables of the {{.Name}} {{.Kind}}. Nil variables take
+// their default value, or null.
+type {{.VariablesType}} struct {
+{{- range .Variables}}
+	{{.GoName}} {{variableType .}} ` + "`" + `json:"{{.Name}},omitempty"` + "`" + `
+{{- end}}
+}
+{{end}}
+// {{.GoName}} executes the {{.Name}} {{.Kind}}, declared in {{.Path}}.
+func (c *Client) {{.GoName}}(ctx context.Context{{if .VariablesType}}, variables *{{.VariablesType}}{{end}}) (*{{.Result}}, error) {
+	const query = {{goString .Source}}
+	result := &{{.Result}}{}
+{{- $taken := newTaken}}
+	if err := c.execute(ctx, query, {{if .VariablesType}}variables{{else}}nil{{end}}{{range .Selections}}, &result.{{fieldName . $taken}}{{end}}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+{{end}}`
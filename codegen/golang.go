@@ -0,0 +1,196 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+
+	"github.com/tmc/parse"
+)
+
+// embeddedTypes are the parse types embedded in the structs of built-in classes, and the
+// fields they cover. Other classes embed parse.ParseObject.
+var embeddedTypes = map[string]struct {
+	typeName string
+	fields   []string
+}{
+	"_User":         {"parse.ParseUser", []string{"username", "password", "sessionToken", "email", "emailVerified", "authData"}},
+	"_Installation": {"parse.ParseInstallation", []string{"channels", "deviceToken", "deviceType"}},
+}
+
+// objectFields are covered by parse.ParseObject and never generated.
+var objectFields = []string{"objectId", "createdAt", "updatedAt"}
+
+// goFieldTypes maps Parse field types to Go types. Unknown types map to interface{}.
+var goFieldTypes = map[string]string{
+	"String":   "string",
+	"Number":   "float64",
+	"Boolean":  "bool",
+	"Date":     "*parse.Date",
+	"File":     "*parse.ParseFile",
+	"GeoPoint": "*parse.GeoPoint",
+	"Pointer":  "*parse.Pointer",
+	"Array":    "[]interface{}",
+	"Object":   "map[string]interface{}",
+	"ACL":      "map[string]map[string]bool",
+}
+
+type goStruct struct {
+	ClassName string
+	Name      string
+	Embedded  string
+	Fields    []goField
+	Relations []goField
+}
+
+type goField struct {
+	JSONName string
+	Name     string
+	Type     string
+	Comment  string
+}
+
+// OmitEmpty reports whether f is left out of the JSON of objects when nil. Fields of
+// other types are always sent, as their zero values (false, 0, "") are values.
+func (f goField) OmitEmpty() bool {
+	for _, prefix := range []string{"*", "[]", "map[", "interface{}"} {
+		if strings.HasPrefix(f.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedGoNames are the identifiers of the embedded fields and the methods of
+// generated structs, which fields must not shadow.
+var reservedGoNames = []string{
+	"ParseObject", "ParseUser", "ParseInstallation", "ID", "CreatedAt", "UpdatedAt",
+	"ObjectID", "ParseClassName", "Pointer",
+	"Username", "Password", "SessionToken", "Email", "EmailVerified", "AuthData",
+	"Channels", "DeviceToken", "DeviceType",
+}
+
+// Go generates a Go source file of package pkg declaring a struct, satisfying
+// parse.Object and parse.ClassNamer, for every class in schema, along with typed Get,
+// Query and Count helpers.
+func Go(schema map[string]*parse.Schema, pkg string) ([]byte, error) {
+	var structs []goStruct
+	takenTypes := map[string]bool{}
+	for _, className := range classNames(schema) {
+		structs = append(structs, newGoStruct(schema[className], takenTypes))
+	}
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, map[string]interface{}{
+		"Package": pkg,
+		"Structs": structs,
+	}); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+func newGoStruct(class *parse.Schema, takenTypes map[string]bool) goStruct {
+	s := goStruct{
+		ClassName: class.ClassName,
+		Name:      uniqueName(exportedName(class.ClassName), "Class", takenTypes),
+		Embedded:  "parse.ParseObject",
+	}
+	skip := map[string]bool{}
+	for _, name := range objectFields {
+		skip[name] = true
+	}
+	if embedded, ok := embeddedTypes[class.ClassName]; ok {
+		s.Embedded = embedded.typeName
+		for _, name := range embedded.fields {
+			skip[name] = true
+		}
+	}
+	takenFields := map[string]bool{}
+	for _, name := range reservedGoNames {
+		takenFields[name] = true
+	}
+	for _, name := range fieldNames(class) {
+		field := class.Fields[name]
+		if skip[name] {
+			continue
+		}
+		f := goField{
+			JSONName: name,
+			Name:     uniqueName(exportedName(name), "Field", takenFields),
+		}
+		switch field.Type {
+		case "Relation":
+			f.Comment = fmt.Sprintf("%s is a Relation to %s objects, queried with $relatedTo.", name, field.TargetClass)
+			s.Relations = append(s.Relations, f)
+			continue
+		case "Pointer":
+			f.Comment = fmt.Sprintf("Pointer to %s", field.TargetClass)
+		}
+		f.Type = goFieldTypes[field.Type]
+		if f.Type == "" {
+			f.Type = "interface{}"
+			f.Comment = fmt.Sprintf("Parse type %s", field.Type)
+		}
+		s.Fields = append(s.Fields, f)
+	}
+	return s
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by parse_graphql gen go. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/tmc/parse"
+{{range .Structs}}
+// {{.Name}} is an object of the Parse class {{.ClassName}}.
+type {{.Name}} struct {
+	{{.Embedded}}
+{{range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.JSONName}}{{if .OmitEmpty}},omitempty{{end}}"` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+{{- range .Relations}}
+
+	// {{.Comment}}
+{{- end}}
+}
+
+// ParseClassName returns the name of the Parse class of {{.Name}} objects.
+func (*{{.Name}}) ParseClassName() string {
+	return "{{.ClassName}}"
+}
+
+// Pointer returns a Pointer to o.
+func (o *{{.Name}}) Pointer() *parse.Pointer {
+	return &parse.Pointer{ClassName: "{{.ClassName}}", ObjectID: o.ObjectID()}
+}
+
+// Get{{.Name}} fetches the {{.Name}} with the given object ID.
+func Get{{.Name}}(client *parse.Client, objectID string) (*{{.Name}}, error) {
+	o := &{{.Name}}{}
+	if err := client.GetClass("{{.ClassName}}", objectID, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Query{{.Name}} returns the {{.Name}} objects matching options.
+func Query{{.Name}}(client *parse.Client, options *parse.QueryOptions) ([]*{{.Name}}, error) {
+	var results []*{{.Name}}
+	if err := client.QueryClass("{{.ClassName}}", options, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Count{{.Name}} returns the number of {{.Name}} objects matching the where clause of
+// options.
+func Count{{.Name}}(client *parse.Client, options *parse.QueryOptions) (int, error) {
+	return client.CountClass("{{.ClassName}}", options)
+}
+{{end}}`))
@@ -0,0 +1,317 @@
+package codegen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/parser"
+	"github.com/tmc/graphql/schema"
+)
+
+// scalarTypes are the GraphQL types of leaf values. Fields whose type is not known
+// statically are leaves too, and hold arbitrary JSON.
+var scalarTypes = map[string]bool{
+	"String":  true,
+	"Float":   true,
+	"Int":     true,
+	"Boolean": true,
+	"Date":    true,
+}
+
+// typenameSpec describes the __typename field the executor adds to every object.
+var typenameSpec = &schema.GraphQLFieldSpec{Name: "__typename", Type: "String"}
+
+// Operation is a named GraphQL operation read from a .graphql file.
+type Operation struct {
+	Path      string
+	Source    string
+	Operation *graphql.Operation
+	// Selections are the typed root selections of the operation, set by Validate.
+	Selections []*Selection
+}
+
+// Selection is a field selected by an operation along with its GraphQL type.
+type Selection struct {
+	Name string
+	// Key is the key of the field in results: its alias, or its name.
+	Key string
+	// Type is the GraphQL type of the field, without list brackets, and empty if the
+	// field holds arbitrary JSON.
+	Type string
+	List bool
+	// Fields are the selections of an object field, and nil for leaves.
+	Fields []*Selection
+
+	typeName string // name of the generated type of an object field
+}
+
+// ReadOperation reads the operation in the file at path. Every file must hold exactly
+// one named query or mutation, which may declare variables; fragments are not supported.
+func ReadOperation(path string) (*Operation, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parser.ParseDocument(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(doc.Operations) != 1 {
+		return nil, fmt.Errorf("%s: expected one operation, found %d", path, len(doc.Operations))
+	}
+	if len(doc.FragmentDefinitions) > 0 {
+		return nil, fmt.Errorf("%s: fragments are not supported", path)
+	}
+	op := &doc.Operations[0]
+	switch {
+	case op.Name == "":
+		return nil, fmt.Errorf("%s: operations must be named", path)
+	case op.Type == graphql.OperationSubscription:
+		return nil, fmt.Errorf("%s: subscription operations are not supported", path)
+	}
+	return &Operation{
+		Path:      path,
+		Source:    strings.TrimSpace(string(src)),
+		Operation: op,
+	}, nil
+}
+
+// ValidationError lists the problems found in an operation.
+type ValidationError struct {
+	Path   string
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, msg := range e.Errors {
+		lines[i] = e.Path + ": " + msg
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks that every field selected by op exists in s, accepts the arguments it
+// is given, which only reference declared variables, and has a selection set if and only
+// if it is an object, and sets the Selections of op. It returns a *ValidationError
+// listing every problem found.
+func Validate(s *schema.Schema, op *Operation) error {
+	v := &validator{types: s.RegisteredTypes(), variables: map[string]bool{}}
+	for _, def := range op.Operation.VariableDefinitions {
+		v.variables[def.Variable.Name] = true
+	}
+	op.Selections = nil
+	for _, selection := range op.Operation.SelectionSet {
+		field := v.field(selection, "")
+		if field == nil {
+			continue
+		}
+		spec, ok := s.RootFields()[field.Name]
+		if !ok {
+			v.errorf("root field '%s' does not exist", field.Name)
+			continue
+		}
+		op.Selections = append(op.Selections, v.selection(field, spec, ""))
+	}
+	if len(v.errors) > 0 {
+		op.Selections = nil
+		return &ValidationError{Path: op.Path, Errors: v.errors}
+	}
+	return nil
+}
+
+type validator struct {
+	types     map[string]schema.GraphQLTypeInfo
+	variables map[string]bool // declared by the operation, with their '$'
+	errors    []string
+}
+
+func (v *validator) errorf(format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf(format, args...))
+}
+
+// field returns the field of selection, reporting fragments, which are not supported.
+func (v *validator) field(selection graphql.Selection, path string) *graphql.Field {
+	if selection.Field == nil {
+		v.errorf("fragments are not supported (in '%s')", path)
+		return nil
+	}
+	return selection.Field
+}
+
+// selection checks field, described by spec, and its selection set. path is the path of
+// the parent of field.
+func (v *validator) selection(field *graphql.Field, spec *schema.GraphQLFieldSpec, path string) *Selection {
+	if path != "" {
+		path += "."
+	}
+	path += field.Name
+	result := &Selection{Name: field.Name, Key: field.Name}
+	if field.Alias != "" {
+		result.Key = field.Alias
+	}
+	result.Type = spec.Type
+	if strings.HasPrefix(result.Type, "[") && strings.HasSuffix(result.Type, "]") {
+		result.Type = result.Type[1 : len(result.Type)-1]
+		result.List = true
+	}
+
+	for _, arg := range field.Arguments {
+		if !hasArgument(spec, arg.Name) {
+			v.errorf("unknown argument '%s' on field '%s'", arg.Name, path)
+		}
+		v.checkVariables(arg.Value, path)
+	}
+
+	typeInfo, isObject := v.types[result.Type]
+	if scalarTypes[result.Type] || !isObject {
+		if !isObject && !scalarTypes[result.Type] {
+			result.Type = ""
+		}
+		if len(field.SelectionSet) > 0 && result.Type != "" {
+			v.errorf("field '%s' of type '%s' cannot have a selection set", path, result.Type)
+		}
+		return result
+	}
+	if len(field.SelectionSet) == 0 {
+		v.errorf("field '%s' of type '%s' must have a selection set", path, result.Type)
+		return result
+	}
+	result.Fields = []*Selection{}
+	for _, selection := range field.SelectionSet {
+		child := v.field(selection, path)
+		if child == nil {
+			continue
+		}
+		childSpec, ok := typeInfo.Fields[child.Name]
+		if child.Name == "__typename" {
+			childSpec, ok = typenameSpec, true
+		}
+		if !ok {
+			v.errorf("field '%s' does not exist on type '%s' (in '%s')", child.Name, result.Type, path)
+			continue
+		}
+		result.Fields = append(result.Fields, v.selection(child, childSpec, path))
+	}
+	return result
+}
+
+// checkVariables reports the variables referenced by the argument value of the field at
+// path that the operation does not declare.
+func (v *validator) checkVariables(value interface{}, path string) {
+	switch value := value.(type) {
+	case graphql.Variable:
+		if !v.variables[value.Name] {
+			v.errorf("variable '%s' is not declared (in '%s')", value.Name, path)
+		}
+	case []interface{}:
+		for _, e := range value {
+			v.checkVariables(e, path)
+		}
+	case map[string]interface{}:
+		for _, e := range value {
+			v.checkVariables(e, path)
+		}
+	}
+}
+
+func hasArgument(spec *schema.GraphQLFieldSpec, name string) bool {
+	for _, arg := range spec.Arguments {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedClientNames are the identifiers declared by every generated client, which the
+// types generated for operations must not reuse.
+var reservedClientNames = []string{
+	"Client", "Error", "RequestError", "RequestOptions", "GraphQLError", "ParseDate",
+}
+
+// objectType is a type generated for the object fields selected at some path of an
+// operation.
+type objectType struct {
+	Name   string
+	Fields []*Selection
+}
+
+// operationType describes the types generated for an operation.
+type operationType struct {
+	*Operation
+	Kind    graphql.OperationType
+	Name    string // name of the operation as written in its file
+	GoName  string // exported name of the operation
+	Result  string // name of the result type
+	Objects []objectType
+	// VariablesType names the type of the variables of the operation, if it declares any.
+	VariablesType string
+	Variables     []variable
+}
+
+// variable is a variable declared by an operation.
+type variable struct {
+	Name   string // without its '$'
+	GoName string // name of the field of the variables type
+	Type   string // GraphQL type
+}
+
+// operationTypes names the types generated for ops, which must have been validated.
+func operationTypes(ops []*Operation) ([]*operationType, error) {
+	taken := map[string]bool{}
+	for _, name := range reservedClientNames {
+		taken[name] = true
+	}
+	takenOps := map[string]string{}
+	for _, name := range []string{"Endpoint", "HTTPClient", "Header"} {
+		takenOps[name] = "the generated Client"
+	}
+	var result []*operationType
+	for _, op := range ops {
+		if op.Selections == nil && len(op.Operation.SelectionSet) > 0 {
+			return nil, fmt.Errorf("codegen: %s: operation was not validated", op.Path)
+		}
+		name := exportedName(op.Operation.Name)
+		if other, ok := takenOps[name]; ok {
+			return nil, fmt.Errorf("codegen: %s: operation %s is already declared in %s", op.Path, op.Operation.Name, other)
+		}
+		takenOps[name] = op.Path
+		t := &operationType{
+			Operation: op,
+			Kind:      op.Operation.Type,
+			Name:      op.Operation.Name,
+			GoName:    name,
+			Result:    uniqueName(name+"Result", "Type", taken),
+		}
+		t.addObjects(name, op.Selections, taken)
+		if defs := op.Operation.VariableDefinitions; len(defs) > 0 {
+			t.VariablesType = uniqueName(name+"Variables", "Type", taken)
+			takenFields := map[string]bool{}
+			for _, def := range defs {
+				name := strings.TrimPrefix(def.Variable.Name, "$")
+				t.Variables = append(t.Variables, variable{
+					Name:   name,
+					GoName: uniqueName(exportedName(name), "Field", takenFields),
+					Type:   def.Type.Name,
+				})
+			}
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// addObjects names the types of the object fields among selections, prefixing them with
+// prefix.
+func (t *operationType) addObjects(prefix string, selections []*Selection, taken map[string]bool) {
+	for _, s := range selections {
+		if s.Fields == nil {
+			continue
+		}
+		s.typeName = uniqueName(prefix+exportedName(s.Key), "Type", taken)
+		t.Objects = append(t.Objects, objectType{Name: s.typeName, Fields: s.Fields})
+		t.addObjects(s.typeName, s.Fields, taken)
+	}
+}
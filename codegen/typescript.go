@@ -0,0 +1,157 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// tsScalarTypes maps GraphQL leaf types to TypeScript types. Fields holding arbitrary
+// JSON are unknown.
+var tsScalarTypes = map[string]string{
+	"String":  "string",
+	"Float":   "number",
+	"Int":     "number",
+	"Boolean": "boolean",
+	"Date":    "ParseDate",
+}
+
+// TypeScript generates a TypeScript module declaring the result types of ops and a
+// function executing each of them. The operations must have been validated.
+func TypeScript(ops []*Operation) ([]byte, error) {
+	types, err := operationTypes(ops)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tsTemplate.Execute(&buf, types); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tsType returns the TypeScript type of the field selected by s.
+func tsType(s *Selection) string {
+	t := "unknown"
+	switch {
+	case s.typeName != "":
+		t = s.typeName
+	case tsScalarTypes[s.Type] != "":
+		t = tsScalarTypes[s.Type]
+	}
+	if s.List {
+		t = "Array<" + t + ">"
+	}
+	if t == "unknown" {
+		return t
+	}
+	return t + " | null"
+}
+
+// tsVariableType returns the TypeScript type of the variable v.
+func tsVariableType(v variable) string {
+	if t := tsScalarTypes[v.Type]; t != "" {
+		return t + " | null"
+	}
+	return "unknown"
+}
+
+// tsString returns a TypeScript template literal of s.
+func tsString(s string) string {
+	r := strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${")
+	return "`" + r.Replace(s) + "`"
+}
+
+// tsFuncName returns the name of the function executing the operation named name.
+func tsFuncName(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+var tsTemplate = template.Must(template.New("typescript").Funcs(template.FuncMap{
+	"tsType":         tsType,
+	"tsVariableType": tsVariableType,
+	"tsString":       tsString,
+	"tsFuncName":     tsFuncName,
+}).Parse(`// Code generated by parse_graphql gen client. DO NOT EDIT.
+
+/** A Parse Date: an ISO 8601 string for createdAt and updatedAt, an object otherwise. */
+export type ParseDate = string | { __type: "Date"; iso: string };
+
+/** An error returned by the server. */
+export interface GraphQLError {
+  message: string;
+  extensions?: { [key: string]: unknown };
+}
+
+/** RequestError is thrown by operations the server failed to execute. */
+export class RequestError extends Error {
+  extensions?: { [key: string]: unknown };
+
+  constructor(error: GraphQLError) {
+    super(error.message);
+    this.extensions = error.extensions;
+  }
+}
+
+/** RequestOptions describe how operations are sent to a parse_graphql server. */
+export interface RequestOptions {
+  /** The URL of the GraphQL endpoint. */
+  endpoint: string;
+  /** Headers added to every request, for example X-Parse-Session-Token. */
+  headers?: { [name: string]: string };
+  /** Performs requests, the global fetch if unset. */
+  fetch?: typeof fetch;
+}
+
+/** execute runs query with variables and returns the value of each of its root fields. */
+async function execute(options: RequestOptions, query: string, variables?: object): Promise<any[]> {
+  const response = await (options.fetch || fetch)(options.endpoint, {
+    method: "POST",
+    headers: { ...options.headers, "Content-Type": "application/json" },
+    body: JSON.stringify({ query, variables }),
+  });
+  const result = await response.json();
+  if (result.error) {
+    throw new RequestError(result.error);
+  }
+  return result.data;
+}
+{{range .}}{{$op := .}}
+/** The result of the {{.Name}} {{.Kind}}. */
+export interface {{.Result}} {
+{{- range .Selections}}
+  {{.Key}}: {{tsType .}};
+{{- end}}
+}
+{{range .Objects}}
+/** An object selected by the {{$op.Name}} {{$op.Kind}}. */
+export interface {{.Name}} {
+{{- range .Fields}}
+  {{.Key}}: {{tsType .}};
+{{- end}}
+}
+{{end}}
+{{- if .VariablesType}}
+/** The variables of the {{.Name}} {{.Kind}}. Missing variables take their default value, or null. */
+export interface {{.VariablesType}} {
+{{- range .Variables}}
+  {{.Name}}?: {{tsVariableType .}};
+{{- end}}
+}
+{{end}}
+/** The document of the {{.Name}} {{.Kind}}, declared in {{.Path}}. */
+export const {{.GoName}}Document = {{tsString .Source}};
+
+/** Executes the {{.Name}} {{.Kind}}. */
+export async function {{tsFuncName .GoName}}(options: RequestOptions{{if .VariablesType}}, variables: {{.VariablesType}}{{end}}): Promise<{{.Result}}> {
+  const data = await execute(options, {{.GoName}}Document{{if .VariablesType}}, variables{{end}});
+  return {
+{{- range $i, $s := .Selections}}
+    {{$s.Key}}: data[{{$i}}],
+{{- end}}
+  };
+}
+{{end}}`))
@@ -0,0 +1,40 @@
+package parse_graphql_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tmc/graphql/handler"
+)
+
+func TestCORS(t *testing.T) {
+	e := newEndpoint(t, newParse(t), nil, func(h *handler.ExecutorHandler) {
+		h.CORS = &handler.CORS{AllowedOrigins: []string{"https://app.example.com"}}
+	})
+	host := strings.TrimPrefix(e.URL, "http://")
+	from := func(origin string) map[string]string { return map[string]string{"Origin": origin} }
+
+	for name, origin := range map[string]string{
+		"no origin":   "",
+		"same origin": "http://" + host,
+		"allowed":     "https://app.example.com",
+	} {
+		r := e.post(t, from(origin), `{ _RoleCount }`)
+		if r.status != http.StatusOK {
+			t.Errorf("%s: got %d (error %v), want 200", name, r.status, r.Error)
+		}
+		if vary := r.header.Values("Vary"); len(vary) == 0 || vary[0] != "Origin" {
+			t.Errorf("%s: got Vary %v, want Origin", name, vary)
+		}
+	}
+	for name, origin := range map[string]string{
+		"other origin": "https://evil.example.com",
+		// a page served over https on the same host is another origin
+		"other scheme": "https://" + host,
+	} {
+		if r := e.post(t, from(origin), `{ _RoleCount }`); r.status != http.StatusForbidden {
+			t.Errorf("%s: got %d, want 403", name, r.status)
+		}
+	}
+}
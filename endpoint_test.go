@@ -0,0 +1,167 @@
+package parse_graphql_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/tmc/graphql/executor"
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/graphql/schema"
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/parsetest"
+)
+
+func TestMain(m *testing.M) {
+	// the handler logs every query
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// endpoint is a GraphQL endpoint serving the schema of a parsetest server.
+type endpoint struct {
+	*httptest.Server
+	parse   *parsetest.Server
+	handler *handler.ExecutorHandler
+}
+
+// newEndpoint serves the schema of p, restricted by policy if not nil, through a handler
+// configured by setup, if not nil.
+func newEndpoint(t *testing.T, p *parsetest.Server, policy *parse_graphql.Policy, setup func(h *handler.ExecutorHandler)) *endpoint {
+	t.Helper()
+	return newSubscriptionEndpoint(t, p, policy, nil, setup)
+}
+
+// newSubscriptionEndpoint is newEndpoint with subscriptions fed by changes.
+func newSubscriptionEndpoint(t *testing.T, p *parsetest.Server, policy *parse_graphql.Policy, changes parse_graphql.ChangeSource, setup func(h *handler.ExecutorHandler)) *endpoint {
+	t.Helper()
+	h := newHandler(t, p.Client(), policy, changes)
+	if setup != nil {
+		setup(h)
+	}
+	e := &endpoint{Server: httptest.NewServer(h), parse: p, handler: h}
+	t.Cleanup(e.Close)
+	return e
+}
+
+// newHandler returns a handler serving the schema of the Parse app of client, restricted
+// by policy if not nil, with subscriptions fed by changes.
+func newHandler(t *testing.T, client *parse.Client, policy *parse_graphql.Policy, changes parse_graphql.ChangeSource) *handler.ExecutorHandler {
+	t.Helper()
+	return newSchemaHandler(t, client, changes, func(ps *parse_graphql.ParseSchema) {
+		if policy == nil {
+			return
+		}
+		if err := policy.Validate(ps.Schema); err != nil {
+			t.Fatal(err)
+		}
+		ps.Policy = policy
+	})
+}
+
+// newSchemaEndpoint serves the schema of p configured by configure.
+func newSchemaEndpoint(t *testing.T, p *parsetest.Server, configure func(ps *parse_graphql.ParseSchema)) *endpoint {
+	t.Helper()
+	h := newSchemaHandler(t, p.Client(), nil, configure)
+	e := &endpoint{Server: httptest.NewServer(h), parse: p, handler: h}
+	t.Cleanup(e.Close)
+	return e
+}
+
+// newSchemaHandler returns a handler serving the schema of the Parse app of client,
+// configured by configure before it is registered, with subscriptions fed by changes.
+func newSchemaHandler(t *testing.T, client *parse.Client, changes parse_graphql.ChangeSource, configure func(ps *parse_graphql.ParseSchema)) *handler.ExecutorHandler {
+	t.Helper()
+	classes, err := client.WithMasterKey(parsetest.MasterKey).GetFullSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := parse_graphql.NewParseSchema(client, classes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure(ps)
+	s := schema.New()
+	if err := parse_graphql.RegisterSchema(s, client, ps, changes); err != nil {
+		t.Fatal(err)
+	}
+	h := handler.New(executor.New(s))
+	h.ErrorExtensions = parse_graphql.ErrorExtensions
+	return h
+}
+
+// response is the decoded response to a GraphQL request.
+type response struct {
+	status int
+	header http.Header
+	Data   []interface{}  `json:"data"` // the results of the root fields, in order
+	Error  *handler.Error `json:"error"`
+}
+
+// post sends query to e with header, and decodes the response.
+func (e *endpoint) post(t *testing.T, header map[string]string, query string) *response {
+	t.Helper()
+	return e.postBody(t, header, map[string]interface{}{"query": query})
+}
+
+// postBody sends the request body to e with header, and decodes the response.
+func (e *endpoint) postBody(t *testing.T, header map[string]string, request map[string]interface{}) *response {
+	t.Helper()
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", e.URL, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := &response{status: resp.StatusCode, header: resp.Header}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		t.Fatalf("%s: decoding response: %v", body, err)
+	}
+	return r
+}
+
+// field returns the result of the i-th root field of r.
+func (r *response) field(t *testing.T, i int) interface{} {
+	t.Helper()
+	if i >= len(r.Data) {
+		t.Fatalf("no result for root field %d: %v (error %v)", i, r.Data, r.Error)
+	}
+	return r.Data[i]
+}
+
+// objects returns the objects the i-th root field of r resolved to.
+func (r *response) objects(t *testing.T, i int) []map[string]interface{} {
+	t.Helper()
+	list, ok := r.field(t, i).([]interface{})
+	if !ok {
+		t.Fatalf("root field %d is not a list: %#v", i, r.Data[i])
+	}
+	var objects []map[string]interface{}
+	for _, o := range list {
+		objects = append(objects, o.(map[string]interface{}))
+	}
+	return objects
+}
+
+// newParse starts a parsetest server stopped at the end of the test.
+func newParse(t *testing.T) *parsetest.Server {
+	p := parsetest.NewServer()
+	t.Cleanup(p.Close)
+	return p
+}
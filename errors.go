@@ -0,0 +1,123 @@
+package parse_graphql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse"
+)
+
+// Error codes reported in the 'code' extension of GraphQL errors.
+const (
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeBadUserInput    = "BAD_USER_INPUT"
+	CodeRateLimited     = "RATE_LIMITED"
+	CodeUnavailable     = "UNAVAILABLE"
+	CodeInternal        = "INTERNAL"
+)
+
+// parseErrorCodes maps Parse error codes to the error code reported to clients. Codes
+// missing from it are reported as CodeInternal.
+var parseErrorCodes = map[int]string{
+	parse.ErrInvalidSessionToken:               CodeUnauthenticated,
+	parse.ErrInvalidLinkedSession:              CodeUnauthenticated,
+	parse.ErrOperationForbidden:                CodeForbidden,
+	parse.ErrUserCannotBeAlteredWithoutSession: CodeForbidden,
+	parse.ErrObjectNotFound:                    CodeNotFound,
+	parse.ErrUserWithEmailNotFound:             CodeNotFound,
+	parse.ErrInvalidQuery:                      CodeBadUserInput,
+	parse.ErrInvalidClassName:                  CodeBadUserInput,
+	parse.ErrMissingObjectID:                   CodeBadUserInput,
+	parse.ErrInvalidKeyName:                    CodeBadUserInput,
+	parse.ErrInvalidPointer:                    CodeBadUserInput,
+	parse.ErrInvalidJSON:                       CodeBadUserInput,
+	parse.ErrIncorrectType:                     CodeBadUserInput,
+	parse.ErrObjectTooLarge:                    CodeBadUserInput,
+	parse.ErrInvalidNestedKey:                  CodeBadUserInput,
+	parse.ErrInvalidFileName:                   CodeBadUserInput,
+	parse.ErrInvalidACL:                        CodeBadUserInput,
+	parse.ErrInvalidEmailAddress:               CodeBadUserInput,
+	parse.ErrDuplicateValue:                    CodeBadUserInput,
+	parse.ErrInvalidRoleName:                   CodeBadUserInput,
+	parse.ErrValidationError:                   CodeBadUserInput,
+	parse.ErrUsernameMissing:                   CodeBadUserInput,
+	parse.ErrUserPasswordMissing:               CodeBadUserInput,
+	parse.ErrUsernameTaken:                     CodeBadUserInput,
+	parse.ErrUserEmailTaken:                    CodeBadUserInput,
+	parse.ErrUserEmailMissing:                  CodeBadUserInput,
+	parse.ErrAccountAlreadyLinked:              CodeBadUserInput,
+	parse.ErrExceededQuota:                     CodeRateLimited,
+	parse.ErrRequestLimitExceeded:              CodeRateLimited,
+	parse.ErrTimeout:                           CodeUnavailable,
+	parse.ErrConnectionFailed:                  CodeUnavailable,
+}
+
+// ArgumentError reports an invalid GraphQL argument.
+type ArgumentError struct {
+	Message string
+}
+
+func (e *ArgumentError) Error() string {
+	return e.Message
+}
+
+func argumentErrorf(format string, args ...interface{}) error {
+	return &ArgumentError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ErrorExtensions describes err as the extensions of a GraphQL error: 'code' is one of
+// the Code constants and, for errors returned by Parse, 'parseCode' and 'httpStatus' are
+// the Parse error code and the HTTP status of the response. It matches the signature of
+// handler.ExecutorHandler's ErrorExtensions hook.
+func ErrorExtensions(err error) map[string]interface{} {
+	var (
+		parseErr *parse.Error
+		argErr   *ArgumentError
+	)
+	switch {
+	case errors.As(err, &parseErr):
+		code, ok := parseErrorCodes[parseErr.Code]
+		if !ok {
+			code = statusErrorCode(parseErr.StatusCode)
+		}
+		extensions := map[string]interface{}{"code": code}
+		if parseErr.Code != 0 {
+			extensions["parseCode"] = parseErr.Code
+		}
+		if parseErr.StatusCode != 0 {
+			extensions["httpStatus"] = parseErr.StatusCode
+		}
+		return extensions
+	case errors.Is(err, parse.ErrUnauthorized):
+		return map[string]interface{}{"code": CodeUnauthenticated, "httpStatus": 401}
+	case errors.Is(err, handler.ErrRateLimited), errors.Is(err, tracer.ErrQueryLimit):
+		return map[string]interface{}{"code": CodeRateLimited, "httpStatus": 429}
+	case errors.Is(err, parse.ErrRequiresMasterKey):
+		return map[string]interface{}{"code": CodeForbidden}
+	case errors.As(err, &argErr):
+		return map[string]interface{}{"code": CodeBadUserInput}
+	}
+	return map[string]interface{}{"code": CodeInternal}
+}
+
+// statusErrorCode maps the HTTP status of a Parse response without a known error code to
+// an error code.
+func statusErrorCode(status int) string {
+	switch {
+	case status == 401:
+		return CodeUnauthenticated
+	case status == 403:
+		return CodeForbidden
+	case status == 404:
+		return CodeNotFound
+	case status == 429:
+		return CodeRateLimited
+	case status == 502, status == 503, status == 504:
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
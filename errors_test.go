@@ -0,0 +1,53 @@
+package parse_graphql_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+)
+
+func TestErrorExtensions(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want map[string]interface{}
+	}{
+		{&parse.Error{Code: parse.ErrInvalidSessionToken, StatusCode: 400}, map[string]interface{}{"code": "UNAUTHENTICATED", "parseCode": 209, "httpStatus": 400}},
+		{&parse.Error{Code: parse.ErrOperationForbidden, StatusCode: 400}, map[string]interface{}{"code": "FORBIDDEN", "parseCode": 119, "httpStatus": 400}},
+		{&parse.Error{Code: parse.ErrDuplicateValue, StatusCode: 400}, map[string]interface{}{"code": "BAD_USER_INPUT", "parseCode": 137, "httpStatus": 400}},
+		{&parse.Error{Code: parse.ErrRequestLimitExceeded, StatusCode: 429}, map[string]interface{}{"code": "RATE_LIMITED", "parseCode": 155, "httpStatus": 429}},
+		{&parse.Error{Code: parse.ErrTimeout, StatusCode: 500}, map[string]interface{}{"code": "UNAVAILABLE", "parseCode": 124, "httpStatus": 500}},
+		// codes missing from the mapping fall back to the HTTP status
+		{&parse.Error{Code: 999, StatusCode: 503}, map[string]interface{}{"code": "UNAVAILABLE", "parseCode": 999, "httpStatus": 503}},
+		{&parse.Error{Code: 999, StatusCode: 500}, map[string]interface{}{"code": "INTERNAL", "parseCode": 999, "httpStatus": 500}},
+		{&parse.Error{StatusCode: 404}, map[string]interface{}{"code": "NOT_FOUND", "httpStatus": 404}},
+		{fmt.Errorf("session: %w", parse.ErrUnauthorized), map[string]interface{}{"code": "UNAUTHENTICATED", "httpStatus": 401}},
+		{handler.ErrRateLimited, map[string]interface{}{"code": "RATE_LIMITED", "httpStatus": 429}},
+		{parse.ErrRequiresMasterKey, map[string]interface{}{"code": "FORBIDDEN"}},
+		{errors.New("boom"), map[string]interface{}{"code": "INTERNAL"}},
+	} {
+		if got := parse_graphql.ErrorExtensions(test.err); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestErrorExtensionsReported(t *testing.T) {
+	p, _ := blog(t)
+	e := newEndpoint(t, p, nil, nil)
+	r := e.post(t, nil, `mutation u { updatePost(objectId: "missing", title: "x") { objectId } }`)
+	if r.Error == nil {
+		t.Fatalf("got %v, want an error", r.Data)
+	}
+	want := map[string]interface{}{"code": "NOT_FOUND", "parseCode": float64(101), "httpStatus": float64(404)}
+	if !reflect.DeepEqual(r.Error.Extensions, want) {
+		t.Errorf("got %v, want %v", r.Error.Extensions, want)
+	}
+	if r := e.post(t, nil, `{ Post(skip: "x") { title } }`); r.Error == nil || r.Error.Extensions["code"] != "BAD_USER_INPUT" {
+		t.Errorf("invalid argument: got %v, want BAD_USER_INPUT", r.Error)
+	}
+}
@@ -0,0 +1,147 @@
+package parse_graphql
+
+import (
+	"mime"
+	"net/url"
+	"path"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/graphql/schema"
+	"golang.org/x/net/context"
+)
+
+// File is the value of a Parse File field.
+type File struct {
+	Name        string
+	URL         string
+	ContentType string
+}
+
+// newFile returns the File stored as value in an object, or nil if value is not a File.
+func newFile(value interface{}) *File {
+	m, ok := value.(map[string]interface{})
+	if !ok || m["__type"] != "File" {
+		return nil
+	}
+	f := &File{}
+	f.Name, _ = m["name"].(string)
+	f.URL, _ = m["url"].(string)
+	f.ContentType = fileContentType(f.Name)
+	return f
+}
+
+// fileContentType guesses the content type of a file from the extension of its name,
+// as Parse does not return the content type of files.
+func fileContentType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func (file *File) GraphQLTypeInfo() schema.GraphQLTypeInfo {
+	return schema.GraphQLTypeInfo{
+		Name:        "File",
+		Description: "Parse File",
+		Fields: schema.GraphQLFieldSpecMap{
+			"name": {
+				Name:        "name",
+				Description: "The name of the file, as stored by Parse.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return file.Name, nil
+				},
+				Type: "String",
+			},
+			"url": {
+				Name:        "url",
+				Description: "The URL of the contents of the file.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return file.URL, nil
+				},
+				Type: "String",
+			},
+			"contentType": {
+				Name:        "contentType",
+				Description: "The content type of the file: the type it was uploaded with, or one guessed from its name.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return file.ContentType, nil
+				},
+				Type: "String",
+			},
+		},
+	}
+}
+
+// uploadFile uploads the file of a multipart request and, if className, objectId and
+// field are set, stores it in the field of that object.
+func (s *ParseSchema) uploadFile(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	value, _ := f.Arguments.Get("file")
+	upload, ok := value.(*handler.Upload)
+	if !ok {
+		return nil, argumentErrorf("'file' must be a variable set to a file sent in a multipart request.")
+	}
+	name := upload.Filename
+	if value, ok := f.Arguments.Get("name"); ok {
+		if name, ok = value.(string); !ok || name == "" {
+			return nil, argumentErrorf("'name' must be a non-empty string.")
+		}
+	}
+
+	var target [3]string
+	set := 0
+	for i, arg := range []string{"className", "objectId", "field"} {
+		value, ok := f.Arguments.Get(arg)
+		if !ok {
+			continue
+		}
+		if target[i], ok = value.(string); !ok {
+			return nil, argumentErrorf("'%s' must be a string.", arg)
+		}
+		set++
+	}
+	className, objectID, fieldName := target[0], target[1], target[2]
+	switch set {
+	case 0:
+	case 3:
+		class, ok := s.Schema[className]
+		if !ok {
+			return nil, argumentErrorf("class '%s' does not exist.", className)
+		}
+		if class.Fields[fieldName].Type != "File" {
+			return nil, argumentErrorf("'%s' is not a File field of class '%s'.", fieldName, className)
+		}
+	default:
+		return nil, argumentErrorf("'className', 'objectId' and 'field' must be set together.")
+	}
+
+	contentType := upload.ContentType
+	if contentType == "" {
+		contentType = fileContentType(name)
+	}
+	contents, err := upload.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer contents.Close()
+	c := tracedClient(ctx, s.authedClient(ctx))
+	t, traced := tracer.FromContext(ctx)
+	if traced {
+		t.IncQueries(1)
+	}
+	uploaded, err := c.UploadFile(url.PathEscape(name), contents, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if set > 0 {
+		if traced {
+			t.IncQueries(1)
+		}
+		if _, err := c.UpdateClass(className, objectID, map[string]interface{}{fieldName: uploaded}); err != nil {
+			return nil, err
+		}
+	}
+	return &File{Name: uploaded.Name, URL: uploaded.URL, ContentType: contentType}, nil
+}
@@ -0,0 +1,72 @@
+package parse_graphql_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	"github.com/tmc/parse"
+)
+
+// postUpload sends query to e in a multipart request setting its $file variable to a
+// file named name holding data.
+func (e *endpoint) postUpload(t *testing.T, query, name, data string) *response {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	operations, _ := json.Marshal(map[string]interface{}{"query": query, "variables": map[string]interface{}{"file": nil}})
+	w.WriteField("operations", string(operations))
+	w.WriteField("map", `{"0": ["variables.file"]}`)
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="0"; filename="` + name + `"`},
+		"Content-Type":        {"text/plain"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(data))
+	w.Close()
+	resp, err := http.Post(e.URL, w.FormDataContentType(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := &response{status: resp.StatusCode, header: resp.Header}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return r
+}
+
+func TestUploadFile(t *testing.T) {
+	p, ids := blog(t)
+	p.AddClass("Post", map[string]parse.SchemaField{"cover": {Type: "File"}})
+	e := newEndpoint(t, p, nil, nil)
+
+	r := e.postUpload(t, `mutation u($file: Upload) { uploadFile(file: $file, className: "Post", objectId: "`+ids["a"]+`", field: "cover") { name, contentType } }`, "cover.txt", "hello")
+	file, _ := r.field(t, 0).(map[string]interface{})
+	name, _ := file["name"].(string)
+	if name == "" || file["contentType"] != "text/plain" {
+		t.Fatalf("got %v (error %v), want the uploaded file", file, r.Error)
+	}
+	if data, _, ok := p.File(name); !ok || string(data) != "hello" {
+		t.Errorf("stored contents: got %q, want hello", data)
+	}
+	if cover, _ := p.Object("Post", ids["a"])["cover"].(map[string]interface{}); cover["__type"] != "File" || cover["name"] != name {
+		t.Errorf("cover of post a: got %v, want the uploaded file", cover)
+	}
+
+	for _, args := range []string{
+		`className: "Post", objectId: "` + ids["a"] + `"`,
+		`className: "Missing", objectId: "` + ids["a"] + `", field: "cover"`,
+		`className: "Post", objectId: "` + ids["a"] + `", field: "title"`,
+	} {
+		r := e.postUpload(t, `mutation u($file: Upload) { uploadFile(file: $file, `+args+`) { name } }`, "cover.txt", "hello")
+		if r.Error == nil || r.Error.Extensions["code"] != "BAD_USER_INPUT" {
+			t.Errorf("%s: got %v (error %v), want BAD_USER_INPUT", args, r.Data, r.Error)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package parse_graphql
+
+import (
+	"math"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/schema"
+	"golang.org/x/net/context"
+)
+
+// Earth radii used by Parse to convert distances to radians.
+const (
+	earthRadiusKilometers = 6371.0
+	earthRadiusMiles      = 3958.8
+)
+
+// geoArguments are the arguments filtering objects by the position of GeoPoint fields.
+// Each names the field it applies to:
+//
+//	near: {location: {latitude: 37.77, longitude: -122.41}}
+//	withinKilometers: 10 (or withinMiles), with near
+//	withinBox: {location: [{latitude: 37.7, longitude: -122.5}, {latitude: 37.8, longitude: -122.3}]}
+//	withinPolygon: {location: [{latitude: 37.7, longitude: -122.5}, ...]}
+var geoArguments = []string{"near", "withinKilometers", "withinMiles", "withinBox", "withinPolygon"}
+
+// GeoPoint is the value of a Parse GeoPoint field.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+	// Distance is the distance in kilometers to the point objects were searched near, if
+	// the query producing the object had a 'near' argument for this field.
+	Distance *float64
+}
+
+// newGeoPoint returns the GeoPoint stored as value in an object, or nil if value is not
+// a GeoPoint.
+func newGeoPoint(value interface{}) *GeoPoint {
+	m, ok := value.(map[string]interface{})
+	if !ok || m["__type"] != "GeoPoint" {
+		return nil
+	}
+	g := &GeoPoint{}
+	g.Latitude, _ = m["latitude"].(float64)
+	g.Longitude, _ = m["longitude"].(float64)
+	return g
+}
+
+// distanceTo returns the great-circle distance in kilometers between g and o.
+func (g *GeoPoint) distanceTo(o *GeoPoint) float64 {
+	lat1, lat2 := g.Latitude*math.Pi/180, o.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (o.Longitude - g.Longitude) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKilometers * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func (g *GeoPoint) parseValue() map[string]interface{} {
+	return map[string]interface{}{"__type": "GeoPoint", "latitude": g.Latitude, "longitude": g.Longitude}
+}
+
+func (g *GeoPoint) GraphQLTypeInfo() schema.GraphQLTypeInfo {
+	return schema.GraphQLTypeInfo{
+		Name:        "GeoPoint",
+		Description: "Parse GeoPoint",
+		Fields: schema.GraphQLFieldSpecMap{
+			"latitude": {
+				Name:        "latitude",
+				Description: "Latitude of the point, in degrees.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return g.Latitude, nil
+				},
+				Type: "Float",
+			},
+			"longitude": {
+				Name:        "longitude",
+				Description: "Longitude of the point, in degrees.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return g.Longitude, nil
+				},
+				Type: "Float",
+			},
+			"distance": {
+				Name:        "distance",
+				Description: "Distance to the point given to the 'near' argument of the query, in kilometers or in the 'unit' argument ('km' or 'mi').",
+				Func:        g.distance,
+				Arguments:   []graphql.Argument{{Name: "unit"}},
+				Type:        "Float",
+			},
+		},
+	}
+}
+
+func (g *GeoPoint) distance(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	if g.Distance == nil {
+		return nil, nil
+	}
+	unit, ok := f.Arguments.Get("unit")
+	if !ok {
+		unit = "km"
+	}
+	switch unit {
+	case "km":
+		return *g.Distance, nil
+	case "mi":
+		return *g.Distance * earthRadiusMiles / earthRadiusKilometers, nil
+	}
+	return nil, argumentErrorf("'unit' must be \"km\" or \"mi\", got %#v", unit)
+}
+
+// geoNear is the origin of a query with a 'near' argument.
+type geoNear struct {
+	field  string
+	origin *GeoPoint
+}
+
+// geoConstraints builds the where clause constraints described by the geo arguments of
+// a query on p's class, and returns the origin of its 'near' argument, if any.
+func (p *ParseClass) geoConstraints(args graphql.Arguments) (map[string]interface{}, *geoNear, error) {
+	constraints := map[string]interface{}{}
+	// constraint returns the GeoPoint field named by the value of arg, the value given
+	// for the field, and the operators constraining the field.
+	constraint := func(arg string, value interface{}) (string, interface{}, map[string]interface{}, error) {
+		m, ok := value.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return "", nil, nil, argumentErrorf("'%s' must map one GeoPoint field to its value", arg)
+		}
+		var (
+			field string
+			v     interface{}
+		)
+		for field, v = range m {
+		}
+		if p.class.Fields[field].Type != "GeoPoint" {
+			return "", nil, nil, argumentErrorf("'%s' is not a GeoPoint field of class '%s'", field, p.class.ClassName)
+		}
+		ops, _ := constraints[field].(map[string]interface{})
+		if ops == nil {
+			ops = map[string]interface{}{}
+			constraints[field] = ops
+		}
+		return field, v, ops, nil
+	}
+
+	var near *geoNear
+	if value, ok := args.Get("near"); ok {
+		field, v, ops, err := constraint("near", value)
+		if err != nil {
+			return nil, nil, err
+		}
+		origin, err := geoPointArgument("near", v)
+		if err != nil {
+			return nil, nil, err
+		}
+		ops["$nearSphere"] = origin.parseValue()
+		near = &geoNear{field: field, origin: origin}
+	}
+	for arg, op := range map[string]string{
+		"withinKilometers": "$maxDistanceInKilometers",
+		"withinMiles":      "$maxDistanceInMiles",
+	} {
+		value, ok := args.Get(arg)
+		if !ok {
+			continue
+		}
+		if near == nil {
+			return nil, nil, argumentErrorf("'%s' requires 'near'", arg)
+		}
+		distance, ok := toFloat(value)
+		if !ok || distance < 0 {
+			return nil, nil, argumentErrorf("'%s' must be a non-negative number, got %#v", arg, value)
+		}
+		constraints[near.field].(map[string]interface{})[op] = distance
+	}
+	if value, ok := args.Get("withinBox"); ok {
+		_, v, ops, err := constraint("withinBox", value)
+		if err != nil {
+			return nil, nil, err
+		}
+		points, err := geoPointsArgument("withinBox", v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(points) != 2 {
+			return nil, nil, argumentErrorf("'withinBox' takes the southwest and northeast corners of the box")
+		}
+		ops["$within"] = map[string]interface{}{"$box": points}
+	}
+	if value, ok := args.Get("withinPolygon"); ok {
+		_, v, ops, err := constraint("withinPolygon", value)
+		if err != nil {
+			return nil, nil, err
+		}
+		points, err := geoPointsArgument("withinPolygon", v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(points) < 3 {
+			return nil, nil, argumentErrorf("'withinPolygon' takes at least 3 points")
+		}
+		ops["$geoWithin"] = map[string]interface{}{"$polygon": points}
+	}
+	return constraints, near, nil
+}
+
+// geoPointArgument converts a {latitude, longitude} argument value into a GeoPoint.
+func geoPointArgument(arg string, value interface{}) (*GeoPoint, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, argumentErrorf("'%s' points must be {latitude, longitude} objects, got %#v", arg, value)
+	}
+	lat, latOK := toFloat(m["latitude"])
+	lng, lngOK := toFloat(m["longitude"])
+	if !latOK || !lngOK || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, argumentErrorf("'%s' points must have a latitude within [-90, 90] and a longitude within [-180, 180]", arg)
+	}
+	return &GeoPoint{Latitude: lat, Longitude: lng}, nil
+}
+
+// geoPointsArgument converts a list of {latitude, longitude} argument values into Parse
+// GeoPoints.
+func geoPointsArgument(arg string, value interface{}) ([]interface{}, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, argumentErrorf("'%s' must be a list of points, got %#v", arg, value)
+	}
+	points := make([]interface{}, len(list))
+	for i, v := range list {
+		point, err := geoPointArgument(arg, v)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = point.parseValue()
+	}
+	return points, nil
+}
+
+// toFloat converts a numeric argument value, which the parser returns as an int or a
+// float64, into a float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
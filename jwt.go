@@ -0,0 +1,256 @@
+package parse_graphql
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// jwtHashes are the hashes of the supported JWT algorithms, minus their HS or RS prefix.
+var jwtHashes = map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+
+// JWTVerifier verifies JSON Web Tokens signed with local HMAC (HS256, HS384, HS512) or
+// RSA (RS256, RS384, RS512) keys.
+type JWTVerifier struct {
+	// Issuer, if set, is the required 'iss' claim of tokens.
+	Issuer string
+	// Audience, if set, must be one of the 'aud' claims of tokens.
+	Audience string
+	// Leeway is the clock skew tolerated when checking the 'exp' and 'nbf' claims.
+	Leeway time.Duration
+	// AllowNoExpiry accepts tokens without an 'exp' claim, which are otherwise rejected
+	// as they would be valid forever.
+	AllowNoExpiry bool
+
+	keys []jwtKey
+}
+
+type jwtKey struct {
+	id  string
+	key interface{} // []byte or *rsa.PublicKey
+}
+
+// AddHMACKey adds an HMAC secret tokens may be signed with, under the key ID kid.
+func (v *JWTVerifier) AddHMACKey(kid string, secret []byte) {
+	v.keys = append(v.keys, jwtKey{id: kid, key: secret})
+}
+
+// AddRSAKey adds an RSA public key tokens may be signed with, under the key ID kid.
+func (v *JWTVerifier) AddRSAKey(kid string, key *rsa.PublicKey) {
+	v.keys = append(v.keys, jwtKey{id: kid, key: key})
+}
+
+// LoadKeys adds the keys of a JWKS file (a JSON object with a 'keys' list of RSA and
+// 'oct' keys), or the RSA public keys and certificates of a PEM file, which get no key ID.
+func (v *JWTVerifier) LoadKeys(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "{") {
+		return v.loadJWKS(b)
+	}
+	found := false
+	for {
+		var block *pem.Block
+		if block, b = pem.Decode(b); block == nil {
+			break
+		}
+		key, err := pemPublicKey(block)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		v.AddRSAKey("", key)
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("%s: no JWKS or PEM keys found", path)
+	}
+	return nil
+}
+
+func pemPublicKey(block *pem.Block) (*rsa.PublicKey, error) {
+	var key interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		var err error
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = cert.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported %T key", key)
+	}
+	return rsaKey, nil
+}
+
+func (v *JWTVerifier) loadJWKS(b []byte) error {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return fmt.Errorf("invalid JWKS: %v", err)
+	}
+	for _, k := range jwks.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return fmt.Errorf("invalid JWKS key %q: %v", k.Kid, err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return fmt.Errorf("invalid JWKS key %q: %v", k.Kid, err)
+			}
+			v.AddRSAKey(k.Kid, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())})
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return fmt.Errorf("invalid JWKS key %q: %v", k.Kid, err)
+			}
+			v.AddHMACKey(k.Kid, secret)
+		default:
+			return fmt.Errorf("unsupported JWKS key type %q", k.Kty)
+		}
+	}
+	return nil
+}
+
+// Verify checks the signature and the 'exp', 'nbf', 'iss' and 'aud' claims of token, and
+// returns its claims. Tokens naming a key ID are checked against the keys with that ID
+// and the keys without one, other tokens against every key.
+func (v *JWTVerifier) Verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed JWT signature")
+	}
+	if !v.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature) {
+		return nil, fmt.Errorf("invalid JWT signature")
+	}
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, v.checkClaims(claims)
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("malformed JWT")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.New("malformed JWT")
+	}
+	return nil
+}
+
+// verifySignature reports whether signature signs signed with alg and one of the keys of
+// ID kid. HMAC algorithms only accept HMAC keys and RSA ones RSA keys.
+func (v *JWTVerifier) verifySignature(alg, kid, signed string, signature []byte) bool {
+	if len(alg) != 5 {
+		return false
+	}
+	hash, ok := jwtHashes[alg[2:]]
+	if !ok {
+		return false
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	for _, k := range v.keys {
+		if kid != "" && k.id != "" && k.id != kid {
+			continue
+		}
+		switch key := k.key.(type) {
+		case []byte:
+			if alg[:2] != "HS" {
+				continue
+			}
+			mac := hmac.New(hash.New, key)
+			mac.Write([]byte(signed))
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if alg[:2] == "RS" && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *JWTVerifier) checkClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok && !v.AllowNoExpiry {
+		return errors.New("JWT has no 'exp' claim")
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(v.Leeway)) {
+		return errors.New("JWT expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-v.Leeway)) {
+		return errors.New("JWT not valid yet")
+	}
+	if v.Issuer != "" && claims["iss"] != v.Issuer {
+		return fmt.Errorf("JWT issuer is not %q", v.Issuer)
+	}
+	if v.Audience == "" {
+		return nil
+	}
+	switch aud := claims["aud"].(type) {
+	case string:
+		if aud == v.Audience {
+			return nil
+		}
+	case []interface{}:
+		for _, a := range aud {
+			if a == v.Audience {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("JWT audience is not %q", v.Audience)
+}
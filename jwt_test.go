@@ -0,0 +1,163 @@
+package parse_graphql_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/parsetest"
+)
+
+const jwtSecret = "jwt-secret"
+
+// signJWT returns an HS256 JWT of claims signed with secret.
+func signJWT(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := encode(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	p := newParse(t)
+	annID, _ := p.AddUser("ann", "pw", nil)
+	bobID, _ := p.AddUser("bob", "pw", nil)
+	e := newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) {
+		v := &parse_graphql.JWTVerifier{Issuer: "https://auth.example.com"}
+		v.AddHMACKey("", []byte(jwtSecret))
+		h.Authenticator = &parse_graphql.ParseAuthenticator{
+			Client:       p.Client(),
+			MasterClient: p.Client().WithMasterKey(parsetest.MasterKey),
+			JWT:          v,
+		}
+	})
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{"sub": annID, "iss": "https://auth.example.com", "exp": time.Now().Add(time.Hour).Unix()}
+		for k, v := range overrides {
+			claims[k] = v
+		}
+		return claims
+	}
+	bearer := func(token string) map[string]string { return map[string]string{"Authorization": "Bearer " + token} }
+
+	sessions := len(p.Objects("_Session"))
+	for i := 0; i < 2; i++ {
+		r := e.post(t, bearer(signJWT(t, jwtSecret, claims(nil))), `{ me { username } }`)
+		if me, _ := r.field(t, 0).(map[string]interface{}); me["username"] != "ann" {
+			t.Fatalf("me: got %v (error %v), want ann", r.Data, r.Error)
+		}
+	}
+	if created := len(p.Objects("_Session")) - sessions; created != 1 {
+		t.Errorf("got %d new sessions, want one reused by both requests", created)
+	}
+
+	// concurrent requests of a user wait for the session the first one creates
+	sessions = len(p.Objects("_Session"))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := e.post(t, bearer(signJWT(t, jwtSecret, claims(map[string]interface{}{"sub": bobID}))), `{ me { username } }`)
+			if me, _ := r.field(t, 0).(map[string]interface{}); me["username"] != "bob" {
+				t.Errorf("me: got %v (error %v), want bob", r.Data, r.Error)
+			}
+		}()
+	}
+	wg.Wait()
+	if created := len(p.Objects("_Session")) - sessions; created != 1 {
+		t.Errorf("got %d new sessions, want one shared by the concurrent requests", created)
+	}
+
+	for name, token := range map[string]string{
+		"no expiry":      signJWT(t, jwtSecret, claims(map[string]interface{}{"exp": nil})),
+		"expired":        signJWT(t, jwtSecret, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"wrong issuer":   signJWT(t, jwtSecret, claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+		"wrong key":      signJWT(t, "other-secret", claims(nil)),
+		"unknown user":   signJWT(t, jwtSecret, claims(map[string]interface{}{"sub": "nobody"})),
+		"not yet valid":  signJWT(t, jwtSecret, claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})),
+		"algorithm none": "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"`+annID+`"}`)) + ".",
+	} {
+		if r := e.post(t, bearer(token), `{ me { username } }`); r.status != http.StatusUnauthorized {
+			t.Errorf("%s: got %d %v, want 401", name, r.status, r.Data)
+		}
+	}
+}
+
+// jwtSessions returns the sessions created for JWT users, by objectId.
+func jwtSessions(p *parsetest.Server) map[string]bool {
+	ids := map[string]bool{}
+	for _, session := range p.Objects("_Session") {
+		if createdWith, _ := session["createdWith"].(map[string]interface{}); createdWith["authProvider"] == "jwt" {
+			ids[session["objectId"].(string)] = true
+		}
+	}
+	return ids
+}
+
+func TestJWTSessions(t *testing.T) {
+	p := newParse(t)
+	annID, _ := p.AddUser("ann", "pw", nil)
+	bobID, _ := p.AddUser("bob", "pw", nil)
+	serve := func(a *parse_graphql.ParseAuthenticator) *endpoint {
+		return newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) {
+			a.Client = p.Client()
+			a.MasterClient = p.Client().WithMasterKey(parsetest.MasterKey)
+			a.JWT = &parse_graphql.JWTVerifier{}
+			a.JWT.AddHMACKey("", []byte(jwtSecret))
+			h.Authenticator = a
+		})
+	}
+	me := func(e *endpoint, userID string, exp time.Duration) {
+		t.Helper()
+		token := signJWT(t, jwtSecret, map[string]interface{}{"sub": userID, "exp": time.Now().Add(exp).Unix()})
+		r := e.post(t, map[string]string{"Authorization": "Bearer " + token}, `{ me { objectId } }`)
+		if me, _ := r.field(t, 0).(map[string]interface{}); me["objectId"] != userID {
+			t.Fatalf("me: got %v (error %v), want %s", r.Data, r.Error, userID)
+		}
+	}
+
+	// sessions outlive tokens about to expire, instead of being created for each request
+	e := serve(&parse_graphql.ParseAuthenticator{})
+	for i := 0; i < 3; i++ {
+		me(e, annID, 30*time.Second)
+	}
+	if sessions := jwtSessions(p); len(sessions) != 1 {
+		t.Errorf("got %d sessions, want one reused by every request", len(sessions))
+	}
+
+	// sessions about to expire are replaced and revoked
+	e = serve(&parse_graphql.ParseAuthenticator{SessionLifetime: 30 * time.Second})
+	before := jwtSessions(p)
+	for i := 0; i < 3; i++ {
+		me(e, annID, time.Hour)
+	}
+	if sessions := jwtSessions(p); len(sessions) != len(before)+1 {
+		t.Errorf("got %d sessions, want the replaced ones revoked", len(sessions)-len(before))
+	}
+
+	// sessions of users evicted by others are revoked
+	e = serve(&parse_graphql.ParseAuthenticator{MaxSessions: 1})
+	before = jwtSessions(p)
+	me(e, annID, time.Hour)
+	me(e, bobID, time.Hour)
+	me(e, annID, time.Hour)
+	if sessions := jwtSessions(p); len(sessions) != len(before)+1 {
+		t.Errorf("got %d sessions, want the evicted ones revoked", len(sessions)-len(before))
+	}
+}
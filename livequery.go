@@ -0,0 +1,104 @@
+package parse_graphql
+
+import (
+	"fmt"
+
+	"github.com/tmc/graphql/websocket"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// LiveQueryChangeSource is a ChangeSource backed by a Parse LiveQuery server. Each
+// subscription opens its own LiveQuery connection, authenticated with the subscriber's
+// session token when one is present.
+type LiveQueryChangeSource struct {
+	// URL is the LiveQuery server websocket URL, e.g. wss://example.com/parse.
+	URL           string
+	ApplicationID string
+	RESTAPIKey    string
+}
+
+// maxLiveQueryMessage bounds the size of messages read from LiveQuery servers, which
+// carry whole objects.
+const maxLiveQueryMessage = 1 << 24
+
+type liveQueryMessage struct {
+	Op            string                 `json:"op"`
+	ApplicationID string                 `json:"applicationId,omitempty"`
+	RESTAPIKey    string                 `json:"restAPIKey,omitempty"`
+	SessionToken  string                 `json:"sessionToken,omitempty"`
+	RequestID     int                    `json:"requestId,omitempty"`
+	Query         *liveQuery             `json:"query,omitempty"`
+	Object        map[string]interface{} `json:"object,omitempty"`
+	Code          int                    `json:"code,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+type liveQuery struct {
+	ClassName string                 `json:"className"`
+	Where     map[string]interface{} `json:"where"`
+}
+
+func (s *LiveQueryChangeSource) Changes(ctx context.Context, className string, where map[string]interface{}) (<-chan interface{}, error) {
+	conn, err := websocket.Dial(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	conn.MaxMessageSize = maxLiveQueryMessage
+	token := sessionToken(ctx)
+	err = conn.WriteJSON(liveQueryMessage{
+		Op:            "connect",
+		ApplicationID: s.ApplicationID,
+		RESTAPIKey:    s.RESTAPIKey,
+		SessionToken:  token,
+	})
+	if err == nil {
+		err = conn.WriteJSON(liveQueryMessage{
+			Op:           "subscribe",
+			RequestID:    1,
+			SessionToken: token,
+			Query:        &liveQuery{ClassName: className, Where: where},
+		})
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	out := make(chan interface{})
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go func() {
+		defer close(out)
+		defer conn.Close()
+		for {
+			var msg liveQueryMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				if ctx.Err() == nil {
+					select {
+					case out <- fmt.Errorf("live query connection lost: %w", err):
+					case <-ctx.Done():
+					}
+				}
+				return
+			}
+			var change interface{}
+			switch msg.Op {
+			case "create", "enter", "update":
+				change = msg.Object
+			case "error":
+				change = &parse.Error{Code: msg.Code, Message: msg.Error}
+			default:
+				continue
+			}
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package parse_graphql_test
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmc/parse_graphql"
+	"golang.org/x/net/context"
+)
+
+// liveQueryServer accepts a LiveQuery connection and sends messages, each split into
+// frames of fragment bytes with a ping between them.
+func liveQueryServer(t *testing.T, fragment int, messages ...string) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+		for _, message := range messages {
+			for opcode := byte(0x1); len(message) > 0; opcode = 0x0 {
+				n := fragment
+				if n >= len(message) {
+					n = len(message)
+					opcode |= 0x80
+				}
+				brw.Write([]byte{opcode, byte(n)})
+				brw.WriteString(message[:n])
+				message = message[n:]
+				brw.Write([]byte{0x89, 0})
+			}
+		}
+		brw.Flush()
+		// wait for the client to hang up
+		brw.ReadByte()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestLiveQueryChangeSourceFragmentedMessages(t *testing.T) {
+	s := liveQueryServer(t, 7,
+		`{"op": "connected"}`,
+		`{"op": "create", "object": {"objectId": "a", "title": "first"}}`,
+		`{"op": "error", "code": 141, "error": "boom"}`,
+	)
+	source := &parse_graphql.LiveQueryChangeSource{URL: "ws" + strings.TrimPrefix(s.URL, "http")}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := source.Changes(ctx, "Post", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object, _ := (<-changes).(map[string]interface{}); object["title"] != "first" {
+		t.Errorf("got %v, want the created object", object)
+	}
+	if err, _ := (<-changes).(error); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("got %v, want the LiveQuery error", err)
+	}
+}
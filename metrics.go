@@ -0,0 +1,312 @@
+package parse_graphql
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram buckets.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// maxOperationNames bounds the number of distinct operation names tracked; further names
+// are counted under "other" as they are chosen by clients.
+const maxOperationNames = 200
+
+// Metrics collects request and Parse API statistics and serves them in the Prometheus
+// text exposition format.
+type Metrics struct {
+	mu              sync.Mutex
+	requests        map[requestKey]int64
+	requestDuration map[string]*histogram
+	parseQueries    map[string]int64
+	parseRequests   map[parseRequestKey]int64
+	parseDuration   map[string]*histogram
+	parseErrors     map[string]int64
+	parseRetries    map[string]int64
+}
+
+type requestKey struct {
+	operation, operationType, status string
+}
+
+type parseRequestKey struct {
+	class, endpoint, method string
+}
+
+type histogram struct {
+	counts []int64 // per bucket, not cumulative
+	count  int64
+	sum    float64
+}
+
+func (h *histogram) observe(v float64) {
+	if h.counts == nil {
+		h.counts = make([]int64, len(latencyBuckets))
+	}
+	for i, bound := range latencyBuckets {
+		if v <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// NewMetrics returns an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:        map[requestKey]int64{},
+		requestDuration: map[string]*histogram{},
+		parseQueries:    map[string]int64{},
+		parseRequests:   map[parseRequestKey]int64{},
+		parseDuration:   map[string]*histogram{},
+		parseErrors:     map[string]int64{},
+		parseRetries:    map[string]int64{},
+	}
+}
+
+// ObserveOperation records an executed GraphQL operation measured by t. It matches the
+// signature of handler.ExecutorHandler's Observe hook.
+func (m *Metrics) ObserveOperation(operation *graphql.Operation, t *tracer.Tracer, err error) {
+	name := operation.Name
+	if name == "" {
+		name = "anonymous"
+	}
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	var duration time.Duration
+	var queries int
+	t.WithLock(func(t *tracer.Tracer) { duration, queries = t.Duration, t.Queries })
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, seen := m.requestDuration[name]; !seen && len(m.requestDuration) >= maxOperationNames {
+		name = "other"
+	}
+	m.requests[requestKey{name, string(operation.Type), status}]++
+	h, ok := m.requestDuration[name]
+	if !ok {
+		h = &histogram{}
+		m.requestDuration[name] = h
+	}
+	h.observe(duration.Seconds())
+	m.parseQueries[name] += int64(queries)
+}
+
+// ObserveParseRequest records a request made to the Parse API. It satisfies
+// parse.RequestObserver.
+func (m *Metrics) ObserveParseRequest(method, endpoint string, statusCode int, err error, duration time.Duration) {
+	class, kind := parseEndpoint(endpoint)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parseRequests[parseRequestKey{class, kind, method}]++
+	h, ok := m.parseDuration[kind]
+	if !ok {
+		h = &histogram{}
+		m.parseDuration[kind] = h
+	}
+	h.observe(duration.Seconds())
+	if err != nil {
+		m.parseErrors[parseErrorCode(err, statusCode)]++
+	}
+}
+
+// ObserveParseRetry records a retried request to the Parse API. It satisfies
+// parse.RetryObserver.
+func (m *Metrics) ObserveParseRetry(method, endpoint string, attempt int, err error, delay time.Duration) {
+	_, kind := parseEndpoint(endpoint)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parseRetries[kind]++
+}
+
+// parseEndpoint splits a Parse API request URI such as '/1/classes/Post/abc?limit=5' into
+// the class it concerns, if any, and the kind of endpoint ('classes').
+func parseEndpoint(endpoint string) (class, kind string) {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
+	if len(parts) > 0 && parts[0] == "1" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return "", "unknown"
+	}
+	kind = parts[0]
+	switch kind {
+	case "classes", "schemas":
+		if len(parts) > 1 {
+			class = parts[1]
+		}
+	case "users", "login", "logout":
+		class = "_User"
+	case "installations":
+		class = "_Installation"
+	case "roles":
+		class = "_Role"
+	case "sessions":
+		class = "_Session"
+	}
+	return class, kind
+}
+
+// parseErrorCode labels err with its Parse error code, falling back to the HTTP status.
+func parseErrorCode(err error, statusCode int) string {
+	var perr *parse.Error
+	if errors.As(err, &perr) {
+		return fmt.Sprint(perr.Code)
+	}
+	if statusCode != 0 {
+		return fmt.Sprintf("http_%d", statusCode)
+	}
+	return "transport"
+}
+
+// ServeHTTP writes the collected metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the collected metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw.header("parse_graphql_requests_total", "counter", "GraphQL operations executed.")
+	for _, k := range sortedRequestKeys(m.requests) {
+		cw.sample("parse_graphql_requests_total", labels("operation", k.operation, "type", k.operationType, "status", k.status), float64(m.requests[k]))
+	}
+	cw.header("parse_graphql_request_duration_seconds", "histogram", "GraphQL operation latency.")
+	for _, name := range sortedKeys(m.requestDuration) {
+		cw.histogram("parse_graphql_request_duration_seconds", "operation", name, m.requestDuration[name])
+	}
+	cw.header("parse_graphql_request_parse_queries_total", "counter", "Parse queries issued while executing GraphQL operations.")
+	for _, name := range sortedKeys(m.parseQueries) {
+		cw.sample("parse_graphql_request_parse_queries_total", labels("operation", name), float64(m.parseQueries[name]))
+	}
+	cw.header("parse_graphql_parse_requests_total", "counter", "Requests made to the Parse API.")
+	for _, k := range sortedParseRequestKeys(m.parseRequests) {
+		cw.sample("parse_graphql_parse_requests_total", labels("class", k.class, "endpoint", k.endpoint, "method", k.method), float64(m.parseRequests[k]))
+	}
+	cw.header("parse_graphql_parse_request_duration_seconds", "histogram", "Parse API request latency.")
+	for _, kind := range sortedKeys(m.parseDuration) {
+		cw.histogram("parse_graphql_parse_request_duration_seconds", "endpoint", kind, m.parseDuration[kind])
+	}
+	cw.header("parse_graphql_parse_errors_total", "counter", "Failed Parse API requests by Parse error code.")
+	for _, code := range sortedKeys(m.parseErrors) {
+		cw.sample("parse_graphql_parse_errors_total", labels("code", code), float64(m.parseErrors[code]))
+	}
+	cw.header("parse_graphql_parse_retries_total", "counter", "Retried Parse API requests by endpoint kind.")
+	for _, kind := range sortedKeys(m.parseRetries) {
+		cw.sample("parse_graphql_parse_retries_total", labels("endpoint", kind), float64(m.parseRetries[kind]))
+	}
+
+	active, started := executor.GoroutineStats()
+	cw.header("parse_graphql_executor_goroutines", "gauge", "Executor field resolution goroutines currently running.")
+	cw.sample("parse_graphql_executor_goroutines", "", float64(active))
+	cw.header("parse_graphql_executor_goroutines_started_total", "counter", "Executor field resolution goroutines started.")
+	cw.sample("parse_graphql_executor_goroutines_started_total", "", float64(started))
+	cw.header("go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	cw.sample("go_goroutines", "", float64(runtime.NumGoroutine()))
+	return cw.n, cw.err
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) printf(format string, args ...interface{}) {
+	if c.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(c.w, format, args...)
+	c.n += int64(n)
+	c.err = err
+}
+
+func (c *countingWriter) header(name, kind, help string) {
+	c.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (c *countingWriter) sample(name, labels string, value float64) {
+	c.printf("%s%s %v\n", name, labels, value)
+}
+
+func (c *countingWriter) histogram(name, labelName, labelValue string, h *histogram) {
+	var cumulative int64
+	for i, bound := range latencyBuckets {
+		cumulative += h.counts[i]
+		c.sample(name+"_bucket", labels(labelName, labelValue, "le", fmt.Sprint(bound)), float64(cumulative))
+	}
+	c.sample(name+"_bucket", labels(labelName, labelValue, "le", "+Inf"), float64(h.count))
+	c.sample(name+"_sum", labels(labelName, labelValue), h.sum)
+	c.sample(name+"_count", labels(labelName, labelValue), float64(h.count))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats alternating label names and values as a Prometheus label set.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]int64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedRequestKeys(m map[requestKey]int64) []requestKey {
+	keys := make([]requestKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
+}
+
+func sortedParseRequestKeys(m map[parseRequestKey]int64) []parseRequestKey {
+	keys := make([]parseRequestKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
+}
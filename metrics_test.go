@@ -0,0 +1,72 @@
+package parse_graphql_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/parse_graphql"
+)
+
+// scrape returns the metrics served at url.
+func scrape(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestMetrics(t *testing.T) {
+	p := newParse(t)
+	p.AddObject("_Role", map[string]interface{}{"name": "Admin"})
+	metrics := parse_graphql.NewMetrics()
+	client := p.Client()
+	client.Observe(metrics.ObserveParseRequest)
+	h := newHandler(t, client, nil, idleChanges{})
+	h.Observe = metrics.ObserveOperation
+	mux := http.NewServeMux()
+	mux.Handle("/", h)
+	mux.Handle("/metrics", metrics)
+	e := &endpoint{Server: httptest.NewServer(mux), parse: p, handler: h}
+	defer e.Close()
+
+	if r := e.post(t, nil, `query roles { _Role { name } }`); len(r.objects(t, 0)) != 1 {
+		t.Fatalf("got %v (error %v), want the role", r.Data, r.Error)
+	}
+	got := scrape(t, e.URL+"/metrics")
+	for _, want := range []string{
+		`parse_graphql_requests_total{operation="roles",type="query",status="ok"} 1`,
+		`parse_graphql_request_duration_seconds_count{operation="roles"} 1`,
+		`parse_graphql_request_parse_queries_total{operation="roles"} 1`,
+		`parse_graphql_parse_requests_total{class="_Role",endpoint="classes",method="GET"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in:\n%s", want, got)
+		}
+	}
+
+	conn := dialGraphQLWS(t, e, nil)
+	startWS(t, conn, "1", `subscription feed { _RoleChanged { name } }`, nil)
+	conn.WriteJSON(wsMessage{ID: "1", Type: "stop"})
+	if msg := readWS(t, conn); msg.Type != "complete" {
+		t.Fatalf("got %s %s, want the subscription to complete", msg.Type, msg.Payload)
+	}
+	// subscriptions are observed once their completion is sent
+	want := `parse_graphql_requests_total{operation="feed",type="subscription",status="ok"} 1`
+	for deadline := time.Now().Add(5 * time.Second); !strings.Contains(scrape(t, e.URL+"/metrics"), want); {
+		if time.Now().After(deadline) {
+			t.Fatalf("missing %s", want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,185 @@
+package parse_graphql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/graphql/schema"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// parseTimeFormat is the format of the timestamps of Parse objects and Dates.
+const parseTimeFormat = "2006-01-02T15:04:05.000Z"
+
+// readOnlyFields are the fields Parse maintains itself.
+var readOnlyFields = map[string]bool{"objectId": true, "createdAt": true, "updatedAt": true}
+
+// inputArguments describes the arguments of the mutations of p's class: one per field
+// that can be set, named after it.
+func (p *ParseClass) inputArguments() []graphql.Argument {
+	var names []string
+	for name, field := range p.class.Fields {
+		if readOnlyFields[name] || p.policy.fieldHidden(p.schema, p.class.ClassName, name) {
+			continue
+		}
+		switch field.Type {
+		case "ReversePointer", "HookFunction", "Relation":
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]graphql.Argument, len(names))
+	for i, name := range names {
+		args[i] = graphql.Argument{Name: name}
+	}
+	return args
+}
+
+// mutationFields returns the root fields creating and updating objects of p's class.
+// Users are created by signUp, and sessions by logging in.
+func (p *ParseClass) mutationFields() map[string]*schema.GraphQLFieldSpec {
+	className := p.class.ClassName
+	fields := map[string]*schema.GraphQLFieldSpec{}
+	if className == "_Session" {
+		return fields
+	}
+	if className != "_User" {
+		fields["create"+className] = &schema.GraphQLFieldSpec{
+			Name:        "create" + className,
+			Description: fmt.Sprintf("Root field to create a %s object from the arguments named after its fields", className),
+			Func:        p.create,
+			Arguments:   p.inputArguments(),
+			IsRoot:      true,
+			Type:        className,
+		}
+	}
+	fields["update"+className] = &schema.GraphQLFieldSpec{
+		Name:        "update" + className,
+		Description: fmt.Sprintf("Root field to set the fields of the %s object 'objectId' named by the other arguments; null deletes a field", className),
+		Func:        p.update,
+		Arguments:   append([]graphql.Argument{{Name: "objectId"}}, p.inputArguments()...),
+		IsRoot:      true,
+		Type:        className,
+	}
+	return fields
+}
+
+func (p *ParseClass) create(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	if err := p.authorizeClass(ctx); err != nil {
+		return nil, err
+	}
+	fields, err := p.inputFields(f.Arguments, false)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	objectID, createdAt, err := tracedClient(ctx, requestClient(ctx, p.client)).CreateClass(p.class.ClassName, fields)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := p.newClass(p.class.ClassName)
+	if err != nil {
+		return nil, err
+	}
+	pc.Data = fields
+	pc.Data["objectId"] = objectID
+	pc.Data["createdAt"] = createdAt.UTC().Format(parseTimeFormat)
+	pc.Data["updatedAt"] = pc.Data["createdAt"]
+	return pc, nil
+}
+
+func (p *ParseClass) update(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	value, _ := f.Arguments.Get("objectId")
+	objectID, ok := value.(string)
+	if !ok || objectID == "" {
+		return nil, argumentErrorf("'objectId' must be the ID of the object to update.")
+	}
+	if err := p.authorizeClass(ctx); err != nil {
+		return nil, err
+	}
+	var args graphql.Arguments
+	for _, arg := range f.Arguments {
+		if arg.Name != "objectId" {
+			args = append(args, arg)
+		}
+	}
+	fields, err := p.inputFields(args, true)
+	if err != nil {
+		return nil, err
+	}
+	c := tracedClient(ctx, requestClient(ctx, p.client))
+	t, traced := tracer.FromContext(ctx)
+	if traced {
+		t.IncQueries(2)
+	}
+	if _, err := c.UpdateClass(p.class.ClassName, objectID, fields); err != nil {
+		return nil, err
+	}
+	pc, err := p.newClass(p.class.ClassName)
+	if err != nil {
+		return nil, err
+	}
+	return pc, c.GetClass(p.class.ClassName, objectID, &pc.Data)
+}
+
+// inputFields converts the arguments of a mutation into the fields of an object as
+// Parse represents them. Null arguments delete fields of updated objects.
+func (p *ParseClass) inputFields(args graphql.Arguments, update bool) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		field, ok := p.class.Fields[arg.Name]
+		if !ok || readOnlyFields[arg.Name] || p.policy.fieldHidden(p.schema, p.class.ClassName, arg.Name) {
+			return nil, argumentErrorf("'%s' is not a field of class '%s' that can be set.", arg.Name, p.class.ClassName)
+		}
+		if arg.Value == nil {
+			if update {
+				fields[arg.Name] = map[string]interface{}{"__op": "Delete"}
+			}
+			continue
+		}
+		value, err := inputValue(arg.Name, field, arg.Value)
+		if err != nil {
+			return nil, err
+		}
+		fields[arg.Name] = value
+	}
+	return fields, nil
+}
+
+// inputValue converts the argument value given for a field into its Parse
+// representation: Pointers are given as the objectId of their target, Dates as RFC 3339
+// timestamps, GeoPoints as {latitude, longitude} objects and ACLs as lists of entries.
+func inputValue(arg string, field parse.SchemaField, value interface{}) (interface{}, error) {
+	switch field.Type {
+	case "ACL":
+		return aclArgument(arg, value)
+	case "Pointer":
+		objectID, ok := value.(string)
+		if !ok {
+			return nil, argumentErrorf("'%s' must be the objectId of a %s object, got %#v", arg, field.TargetClass, value)
+		}
+		return pointer(field.TargetClass, objectID), nil
+	case "Date":
+		s, _ := value.(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, argumentErrorf("'%s' must be an RFC 3339 timestamp, got %#v", arg, value)
+		}
+		return map[string]interface{}{"__type": "Date", "iso": t.UTC().Format(parseTimeFormat)}, nil
+	case "GeoPoint":
+		point, err := geoPointArgument(arg, value)
+		if err != nil {
+			return nil, err
+		}
+		return point.parseValue(), nil
+	}
+	return value, nil
+}
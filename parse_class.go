@@ -3,9 +3,8 @@ package parse_graphql
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"time"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/tmc/graphql"
 	"github.com/tmc/graphql/executor/resolver"
 	"github.com/tmc/graphql/executor/tracer"
@@ -21,6 +20,14 @@ type ParseClass struct {
 	class  *parse.Schema
 	schema map[string]*parse.Schema
 	Data   map[string]interface{}
+	// near is the origin of the 'near' argument of the query that produced Data, if any.
+	near *geoNear
+	// Changes, if set, exposes a '<className>Changed' subscription root field fed by it.
+	Changes ChangeSource
+	// policy, if set, restricts the fields exposed and who may read them.
+	policy *Policy
+	// self is set on the user a request logs in as, which reads its own fields.
+	self bool
 }
 
 func NewParseClass(client *parse.Client, className string, schema map[string]*parse.Schema) (*ParseClass, error) {
@@ -35,6 +42,17 @@ func NewParseClass(client *parse.Client, className string, schema map[string]*pa
 	}, nil
 }
 
+// newClass returns an object of the class className sharing the client, schema and
+// policy of p.
+func (p *ParseClass) newClass(className string) (*ParseClass, error) {
+	pc, err := NewParseClass(p.client, className, p.schema)
+	if err != nil {
+		return nil, err
+	}
+	pc.policy = p.policy
+	return pc, nil
+}
+
 func (p *ParseClass) GraphQLTypeInfo() schema.GraphQLTypeInfo {
 	className := p.class.ClassName
 	ti := schema.GraphQLTypeInfo{
@@ -45,15 +63,80 @@ func (p *ParseClass) GraphQLTypeInfo() schema.GraphQLTypeInfo {
 				Name:        p.class.ClassName,
 				Description: fmt.Sprintf("Root field to fetch %s", className),
 				Func:        p.get,
+				Arguments:   queryArguments,
 				IsRoot:      true,
+				Type:        "[" + className + "]",
+			},
+			className + "Count": &schema.GraphQLFieldSpec{
+				Name:        className + "Count",
+				Description: fmt.Sprintf("Root field to count %s objects", className),
+				Func:        p.count,
+				Arguments:   countArguments,
+				IsRoot:      true,
+				Type:        "Int",
+			},
+			className + "Connection": &schema.GraphQLFieldSpec{
+				Name:        className + "Connection",
+				Description: fmt.Sprintf("Root field to fetch %s along with the total count", className),
+				Func:        p.getConnection,
+				Arguments:   queryArguments,
+				IsRoot:      true,
+				Type:        className + "Connection",
 			},
 		},
 	}
 
+	for name, field := range p.mutationFields() {
+		ti.Fields[name] = field
+	}
+	if className == "_User" {
+		if _, exists := p.class.Fields["sessionToken"]; !exists {
+			ti.Fields["sessionToken"] = &schema.GraphQLFieldSpec{
+				Name:        "sessionToken",
+				Description: "The session token of the user, returned by logIn, signUp, logInWith and me",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					if token, _ := p.Data["sessionToken"].(string); token != "" {
+						return token, nil
+					}
+					return nil, nil
+				},
+				Type: "String",
+			}
+		}
+		if _, ok := p.schema["_Role"]; ok && !p.policy.classHidden("_Role") {
+			if _, exists := p.class.Fields["myRoles"]; !exists {
+				ti.Fields["myRoles"] = &schema.GraphQLFieldSpec{
+					Name:        "myRoles",
+					Description: "The roles the user belongs to, directly or through the roles of its roles",
+					Func:        p.myRoles,
+					Type:        "[_Role]",
+				}
+			}
+		}
+	}
+
+	if p.Changes != nil {
+		ti.Fields[className+"Changed"] = &schema.GraphQLFieldSpec{
+			Name:        className + "Changed",
+			Description: fmt.Sprintf("Subscription root field delivering %s objects as they are created or updated", className),
+			Func:        p.changed,
+			Arguments:   []graphql.Argument{{Name: "where"}},
+			IsRoot:      true,
+			Type:        className,
+		}
+	}
+
 	// generate basic value accessors
 	for fieldName, fieldSchema := range p.class.Fields {
 		fn := fieldName
+		if p.policy.fieldHidden(p.schema, className, fieldName) {
+			continue
+		}
 
+		var args []graphql.Argument
+		if fieldSchema.Type == "ReversePointer" {
+			args = queryArguments
+		}
 		ti.Fields[fieldName] = &schema.GraphQLFieldSpec{
 			Name:        fn,
 			Description: fmt.Sprintf("Accessor for %s field (%v)", fn, fieldSchema.Type),
@@ -64,20 +147,87 @@ func (p *ParseClass) GraphQLTypeInfo() schema.GraphQLTypeInfo {
 				}
 				return r.Resolve(ctx, partial, f)
 			},
+			Arguments: args,
+			Type:      graphQLType(fieldSchema),
+		}
+		if t, ok := builtinFieldTypes[className][fieldName]; ok {
+			ti.Fields[fieldName].Type = t
+		}
+		if fieldSchema.Type == "ReversePointer" {
+			ti.Fields[fieldName+"Connection"] = &schema.GraphQLFieldSpec{
+				Name:        fn + "Connection",
+				Description: fmt.Sprintf("Accessor for %s field along with the total count", fn),
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					partial, err := p.resolveReversePointerConnection(ctx, fn, f)
+					if err != nil {
+						return nil, err
+					}
+					return r.Resolve(ctx, partial, f)
+				},
+				Arguments: queryArguments,
+				Type:      fieldSchema.TargetClass + "Connection",
+			}
 		}
 	}
 	return ti
 }
 
+// graphQLType returns the GraphQL type of the values of a Parse field, or an empty
+// string for values without a static type such as Objects or hook function results.
+func graphQLType(field parse.SchemaField) string {
+	switch field.Type {
+	case "String", "Boolean", "Date", "File", "GeoPoint", "ACL":
+		return field.Type
+	case "Number":
+		return "Float"
+	case "Pointer":
+		return field.TargetClass
+	case "ReversePointer":
+		return "[" + field.TargetClass + "]"
+	}
+	return ""
+}
+
+// builtinFieldTypes are the GraphQL types of fields of built-in classes that Parse
+// declares with a looser type, such as Array or Number.
+var builtinFieldTypes = map[string]map[string]string{
+	"_Installation": {
+		"channels": "[String]",
+		"badge":    "Int",
+	},
+}
+
 func (p *ParseClass) resolve(ctx context.Context, r resolver.Resolver, field *graphql.Field) (interface{}, error) {
-	log.Println("resolving ===============================================")
+	if ok, err := p.readable(ctx, field.Name); !ok || err != nil {
+		return nil, err
+	}
 	fieldInfo := p.class.Fields[field.Name]
 	if fieldInfo.Type == "Pointer" {
 		return p.resolvePointer(ctx, r, field)
 	} else if fieldInfo.Type == "ReversePointer" {
 		return p.resolveReversePointer(ctx, r, field)
+	} else if fieldInfo.Type == "GeoPoint" {
+		point := newGeoPoint(p.Data[field.Name])
+		if point == nil {
+			return nil, nil
+		}
+		if p.near != nil && p.near.field == field.Name {
+			distance := point.distanceTo(p.near.origin)
+			point.Distance = &distance
+		}
+		return point, nil
+	} else if fieldInfo.Type == "File" {
+		if file := newFile(p.Data[field.Name]); file != nil {
+			return file, nil
+		}
+		return nil, nil
+	} else if fieldInfo.Type == "ACL" {
+		if acl := newACL(p.Data[field.Name]); acl != nil {
+			return acl, nil
+		}
+		return nil, nil
 	} else if fieldInfo.Type == "HookFunction" {
-		return mkHookFieldFunc(p.client, p.schema, p.class.ClassName+"_"+field.Name, p.Data)(ctx, r, field)
+		return mkHookFieldFunc(p.client, p.schema, p.policy, p.class.ClassName+"_"+field.Name, p.Data)(ctx, r, field)
 	} else {
 		return p.Data[field.Name], nil
 	}
@@ -86,7 +236,7 @@ func (p *ParseClass) resolve(ctx context.Context, r resolver.Resolver, field *gr
 func (p *ParseClass) resolvePointer(ctx context.Context, r resolver.Resolver, field *graphql.Field) (interface{}, error) {
 	fieldName := field.Name
 	fieldInfo := p.class.Fields[fieldName]
-	pc, err := NewParseClass(p.client, fieldInfo.TargetClass, p.schema)
+	pc, err := p.newClass(fieldInfo.TargetClass)
 	if err != nil {
 		return nil, err
 	}
@@ -110,100 +260,303 @@ func (p *ParseClass) resolvePointer(ctx context.Context, r resolver.Resolver, fi
 	if t, ok := tracer.FromContext(ctx); ok {
 		t.IncQueries(1)
 	}
-	err = pc.client.GetClass(fieldInfo.TargetClass, objectID, &pc.Data)
+	err = tracedClient(ctx, requestClient(ctx, pc.client)).GetClass(fieldInfo.TargetClass, objectID, &pc.Data)
 	return pc, err
 }
 
 func (p *ParseClass) resolveReversePointer(ctx context.Context, r resolver.Resolver, field *graphql.Field) (interface{}, error) {
-	fieldInfo := p.class.Fields[field.Name]
-	pc, err := NewParseClass(p.client, fieldInfo.TargetClass, p.schema)
+	pc, constraints, err := p.reversePointerQuery(field.Name)
 	if err != nil {
 		return nil, err
 	}
-	fieldName := field.Name[len(fieldInfo.TargetClass)+1:]
-	// TODO(tmc): optimize
-	query := []byte(fmt.Sprintf(`{"__type":"Pointer","className":"%s","objectId":"%s"}`,
-		p.class.ClassName,
-		p.Data["objectId"]))
-	queryEncoded := json.RawMessage(query)
-	return pc.get(ctx, r, &graphql.Field{
-		Arguments: []graphql.Argument{
-			{
-				Name:  fieldName,
-				Value: &queryEncoded,
-			},
-		},
-	})
+	return pc.query(ctx, field.Arguments, constraints)
+}
+
+func (p *ParseClass) resolveReversePointerConnection(ctx context.Context, fieldName string, field *graphql.Field) (interface{}, error) {
+	pc, constraints, err := p.reversePointerQuery(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	return pc.connection(ctx, field.Arguments, constraints)
+}
+
+// reversePointerQuery returns the class listed by the reverse field fieldName and the
+// where constraints selecting the objects that point at p.
+func (p *ParseClass) reversePointerQuery(fieldName string) (*ParseClass, map[string]interface{}, error) {
+	fieldInfo := p.class.Fields[fieldName]
+	pc, err := p.newClass(fieldInfo.TargetClass)
+	if err != nil {
+		return nil, nil, err
+	}
+	pointerField, ok := reversePointerField(p.schema, fieldInfo.TargetClass, p.class.ClassName, fieldName)
+	if !ok {
+		return nil, nil, fmt.Errorf("no pointer field on %s backs reverse field '%s'", fieldInfo.TargetClass, fieldName)
+	}
+	pointer := map[string]interface{}{
+		"__type":    "Pointer",
+		"className": p.class.ClassName,
+		"objectId":  p.Data["objectId"],
+	}
+	return pc, map[string]interface{}{pointerField: pointer}, nil
+}
+
+// reversePointerField finds the Pointer field on className targeting targetClass that
+// reverseFieldName was derived from.
+func reversePointerField(schema map[string]*parse.Schema, className, targetClass, reverseFieldName string) (string, bool) {
+	class, ok := schema[className]
+	if !ok {
+		return "", false
+	}
+	for fieldName, fieldInfo := range class.Fields {
+		if fieldInfo.Type != "Pointer" || fieldInfo.TargetClass != targetClass {
+			continue
+		}
+		if ReverseFieldName(className, fieldName) == reverseFieldName {
+			return fieldName, true
+		}
+	}
+	return "", false
 }
 
 var specialFields = []string{"order", "limit", "skip", "keys", "include", "where"}
 var specialFieldsSet map[string]bool
 
+// queryArguments describes the special arguments accepted by fields that list objects.
+var queryArguments = []graphql.Argument{
+	{Name: "where"}, {Name: "limit"}, {Name: "skip"}, {Name: "order"},
+	{Name: "near"}, {Name: "withinKilometers"}, {Name: "withinMiles"}, {Name: "withinBox"}, {Name: "withinPolygon"},
+}
+
+// countArguments describes the arguments accepted by fields that count objects.
+var countArguments = []graphql.Argument{
+	{Name: "where"},
+	{Name: "near"}, {Name: "withinKilometers"}, {Name: "withinMiles"}, {Name: "withinBox"}, {Name: "withinPolygon"},
+}
+
 func (p *ParseClass) get(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	return p.query(ctx, f.Arguments, nil)
+}
+
+// query fetches the objects of the class matching args. Entries in constraints are
+// applied on top of the where clause the arguments describe and take precedence over
+// user supplied values for the same keys.
+func (p *ParseClass) query(ctx context.Context, args graphql.Arguments, constraints map[string]interface{}) ([]*ParseClass, error) {
+	var results []map[string]interface{}
+
+	if err := p.authorizeQuery(ctx, args); err != nil {
+		return nil, err
+	}
+	query, near, err := p.queryOptions(args, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	if err := tracedClient(ctx, requestClient(ctx, p.client)).QueryClass(p.class.ClassName, query, &results); err != nil {
+		return nil, err
+	}
+	return p.wrap(results, near)
+}
+
+func (p *ParseClass) getConnection(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	return p.connection(ctx, f.Arguments, nil)
+}
+
+// connection fetches the objects of the class matching args together with the total
+// number of matching objects.
+func (p *ParseClass) connection(ctx context.Context, args graphql.Arguments, constraints map[string]interface{}) (*ParseClassConnection, error) {
 	var results []map[string]interface{}
 
+	if err := p.authorizeQuery(ctx, args); err != nil {
+		return nil, err
+	}
+	query, near, err := p.queryOptions(args, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	count, err := tracedClient(ctx, requestClient(ctx, p.client)).QueryClassCount(p.class.ClassName, query, &results)
+	if err != nil {
+		return nil, err
+	}
+	typedResults, err := p.wrap(results, near)
+	if err != nil {
+		return nil, err
+	}
+	return &ParseClassConnection{
+		className:  p.class.ClassName,
+		TotalCount: count,
+		Results:    typedResults,
+	}, nil
+}
+
+func (p *ParseClass) count(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	if err := p.authorizeQuery(ctx, f.Arguments); err != nil {
+		return nil, err
+	}
+	query, _, err := p.queryOptions(f.Arguments, nil)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return tracedClient(ctx, requestClient(ctx, p.client)).CountClass(p.class.ClassName, query)
+}
+
+// tracedClient returns client canceled with ctx and recording its retries in the tracer
+// of ctx, if any.
+func tracedClient(ctx context.Context, client *parse.Client) *parse.Client {
+	client = client.WithContext(ctx)
+	t, ok := tracer.FromContext(ctx)
+	if !ok {
+		return client
+	}
+	return client.WithRetryObserver(func(method, endpoint string, attempt int, err error, delay time.Duration) {
+		t.IncRetries(1)
+	})
+}
+
+// queryOptions builds the parse query described by args, including its geo arguments,
+// and returns the origin of its 'near' argument, if any.
+func (p *ParseClass) queryOptions(args graphql.Arguments, constraints map[string]interface{}) (*parse.QueryOptions, *geoNear, error) {
+	geo, near, err := p.geoConstraints(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range constraints {
+		geo[k] = v
+	}
+	query, err := queryOptions(args, geo)
+	return query, near, err
+}
+
+// wrap converts raw query results into ParseClass values of the same class as p, found
+// near the origin of a 'near' argument if not nil.
+func (p *ParseClass) wrap(results []map[string]interface{}, near *geoNear) ([]*ParseClass, error) {
+	typedResults := make([]*ParseClass, 0, len(results))
+	for _, r := range results {
+		pc, err := p.newClass(p.class.ClassName)
+		if err != nil {
+			return nil, err
+		}
+		pc.Data = r
+		pc.near = near
+		typedResults = append(typedResults, pc)
+	}
+	return typedResults, nil
+}
+
+// whereClause builds the where clause described by the user defined and explicit 'where'
+// arguments of a field. Entries in constraints take precedence over user supplied values.
+func whereClause(args graphql.Arguments, constraints map[string]interface{}) (map[string]interface{}, error) {
 	// TODO(tmc): handle overlap between special fields and user defined fields on a class elegantly
 	whereClause := make(map[string]interface{})
-	for _, a := range f.Arguments {
+	for _, a := range args {
 		// only populate where clause if the field isn't in out special field list
 		if !specialFieldsSet[a.Name] {
 			whereClause[a.Name] = a.Value
 		}
 	}
-	if explicitWhere, ok := f.Arguments.Get("where"); ok {
+	if explicitWhere, ok := args.Get("where"); ok {
 		asMap, ok := explicitWhere.(map[string]interface{})
 		if !ok {
-			return nil, fmt.Errorf("explicit where fields must be maps, got '%T'", explicitWhere)
+			return nil, argumentErrorf("explicit where fields must be maps, got '%T'", explicitWhere)
 		}
-		whereClause = asMap
+		// copy so constraints never leak into the parsed arguments
+		whereClause = make(map[string]interface{}, len(asMap)+len(constraints))
+		for k, v := range asMap {
+			whereClause[k] = v
+		}
+	}
+	for k, v := range constraints {
+		whereClause[k] = v
 	}
-	whereJSON, err := json.Marshal(whereClause)
+	return whereClause, nil
+}
+
+// queryOptions builds the parse query described by the special and user defined
+// arguments of a field, merging in constraints.
+func queryOptions(args graphql.Arguments, constraints map[string]interface{}) (*parse.QueryOptions, error) {
+	where, err := whereClause(args, constraints)
+	if err != nil {
+		return nil, err
+	}
+	whereJSON, err := json.Marshal(where)
 	if err != nil {
 		return nil, err
 	}
 	// limit
 	limit := DefaultLimit
-	if l, ok := f.Arguments.Get("limit"); ok {
+	if l, ok := args.Get("limit"); ok {
 		if lim, ok := l.(int); ok {
 			limit = lim
 		} else {
-			return nil, fmt.Errorf("'limit' argument should be an integer. Got %#v", l)
+			return nil, argumentErrorf("'limit' argument should be an integer. Got %#v", l)
+		}
+	}
+
+	// skip
+	skip := 0
+	if s, ok := args.Get("skip"); ok {
+		if sk, ok := s.(int); ok {
+			skip = sk
+		} else {
+			return nil, argumentErrorf("'skip' argument should be an integer. Got %#v", s)
 		}
 	}
 
 	// order
 	order := ""
-	if o, ok := f.Arguments.Get("order"); ok {
+	if o, ok := args.Get("order"); ok {
 		if orderStr, ok := o.(string); ok {
 			order = orderStr
 		} else {
-			return nil, fmt.Errorf("'order' argument should be a string. Got %#v", o)
+			return nil, argumentErrorf("'order' argument should be a string. Got %#v", o)
 		}
 	}
-	spew.Dump("ORDER:", order, f.Arguments)
-	query := &parse.QueryOptions{
+	return &parse.QueryOptions{
 		Where: string(whereJSON),
 		Limit: limit,
 		Order: order,
-	}
-	if t, ok := tracer.FromContext(ctx); ok {
-		t.IncQueries(1)
-	}
-	if err := p.client.QueryClass(p.class.ClassName, query, &results); err != nil {
-		return nil, err
-	}
-	typedResults := make([]*ParseClass, 0, len(results))
+		Skip:  skip,
+	}, nil
+}
 
-	for _, r := range results {
-		pc, err := NewParseClass(p.client, p.class.ClassName, p.schema)
-		if err != nil {
-			return nil, err
-		}
-		pc.Data = r
-		typedResults = append(typedResults, pc)
-	}
+// ParseClassConnection is a page of objects of a class along with the total number of
+// objects matching the query that produced it.
+type ParseClassConnection struct {
+	className  string
+	TotalCount int
+	Results    []*ParseClass
+}
 
-	return typedResults, err
+func (c *ParseClassConnection) GraphQLTypeInfo() schema.GraphQLTypeInfo {
+	return schema.GraphQLTypeInfo{
+		Name:        c.className + "Connection",
+		Description: fmt.Sprintf("A page of %s objects", c.className),
+		Fields: schema.GraphQLFieldSpecMap{
+			"totalCount": {
+				Name:        "totalCount",
+				Description: "Total number of objects matching the query, ignoring limit and skip.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return c.TotalCount, nil
+				},
+				Type: "Int",
+			},
+			"results": {
+				Name:        "results",
+				Description: "The objects in this page.",
+				Func: func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+					return r.Resolve(ctx, c.Results, f)
+				},
+				Type: "[" + c.className + "]",
+			},
+		},
+	}
 }
 
 func init() {
@@ -211,4 +564,7 @@ func init() {
 	for _, f := range specialFields {
 		specialFieldsSet[f] = true
 	}
+	for _, f := range geoArguments {
+		specialFieldsSet[f] = true
+	}
 }
@@ -3,19 +3,37 @@ package parse_graphql
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/tmc/graphql"
 	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
 	"github.com/tmc/graphql/schema"
 	"github.com/tmc/parse"
 	"golang.org/x/net/context"
 )
 
+// ReverseFieldName names the field added to a pointer's target class that lists the
+// objects of className pointing at it through fieldName. The default scheme is
+// '<className>_<fieldName>'; it must be set before calling NewParseSchema.
+var ReverseFieldName = func(className, fieldName string) string {
+	return fmt.Sprintf("%s_%s", className, fieldName)
+}
+
 type ParseSchema struct {
 	client *parse.Client
 	Schema map[string]*parse.Schema
+	// AuthProviders verify the authData of the providers they are keyed by before
+	// logInWith and linkWith send it to Parse.
+	AuthProviders map[string]AuthProvider
+	// RequireAuthProviders rejects the authData of providers missing from AuthProviders.
+	RequireAuthProviders bool
+	// Policy, if set, restricts the classes and fields exposed and who may read them.
+	// It must be set before the schema is registered.
+	Policy *Policy
+	// SessionCache, if set, caches the users of session tokens for me and the other
+	// fields acting on the current user.
+	SessionCache *SessionCache
 	// hooks that don't appear to be associated with a class
 	// based on the naming scheme '<className>_Foobar'
 	hooks []*parse.HookFunction
@@ -25,9 +43,10 @@ type ParseSchema struct {
 // attaches appropriately named hook functions.
 func NewParseSchema(client *parse.Client, schema map[string]*parse.Schema, hooks []*parse.HookFunction) (*ParseSchema, error) {
 	result := &ParseSchema{
-		client: client,
-		Schema: make(map[string]*parse.Schema, len(schema)),
-		hooks:  make([]*parse.HookFunction, 0),
+		client:        client,
+		Schema:        make(map[string]*parse.Schema, len(schema)),
+		AuthProviders: map[string]AuthProvider{"anonymous": AnonymousAuth},
+		hooks:         make([]*parse.HookFunction, 0),
 	}
 	classHooks := map[string][]string{}
 	for _, hook := range hooks {
@@ -51,7 +70,7 @@ func NewParseSchema(client *parse.Client, schema map[string]*parse.Schema, hooks
 		for fieldName, fieldInfo := range classInfo.Fields {
 			if fieldInfo.Type == "Pointer" {
 				fields := schema[fieldInfo.TargetClass].Fields
-				reverseFieldName := fmt.Sprintf("%s_%s", className, fieldName)
+				reverseFieldName := ReverseFieldName(className, fieldName)
 				if _, alreadyPresent := fields[reverseFieldName]; alreadyPresent {
 					return nil, fmt.Errorf("Cannot create reverse pointer for %s on %v - field already present",
 						reverseFieldName, fieldInfo.TargetClass)
@@ -70,30 +89,245 @@ func NewParseSchema(client *parse.Client, schema map[string]*parse.Schema, hooks
 			}
 		}
 	}
+	if err := checkGeneratedNames(result.Schema); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// checkGeneratedNames reports the names generated for the root fields, connection types
+// and reverse pointer connections of classes that are already taken by a class or field.
+func checkGeneratedNames(schema map[string]*parse.Schema) error {
+	for className, classInfo := range schema {
+		for _, name := range []string{className + "Count", className + "Connection"} {
+			if _, alreadyPresent := schema[name]; alreadyPresent {
+				return fmt.Errorf("Cannot generate %s for class %s - class already present", name, className)
+			}
+			if _, alreadyPresent := classInfo.Fields[name]; alreadyPresent {
+				return fmt.Errorf("Cannot generate %s for class %s - field already present", name, className)
+			}
+		}
+		for fieldName, fieldInfo := range classInfo.Fields {
+			if fieldInfo.Type != "ReversePointer" {
+				continue
+			}
+			if _, alreadyPresent := classInfo.Fields[fieldName+"Connection"]; alreadyPresent {
+				return fmt.Errorf("Cannot generate %sConnection on %s - field already present", fieldName, className)
+			}
+		}
+	}
+	return nil
+}
+
+// RegisterSchema registers in s the GraphQL types of parseSchema: a type for every class
+// along with the root fields to query it, the connection type of every class, the File
+// GeoPoint and ACL types, and the top-level fields of parseSchema itself. Subscriptions
+// are fed by changes, if not nil.
+func RegisterSchema(s *schema.Schema, client *parse.Client, parseSchema *ParseSchema, changes ChangeSource) error {
+	for _, class := range parseSchema.Schema {
+		if parseSchema.Policy.classHidden(class.ClassName) {
+			continue
+		}
+		parseClass, err := parseSchema.newClass(client, class.ClassName)
+		if err != nil {
+			return err
+		}
+		parseClass.Changes = changes
+		s.Register(parseClass)
+		s.Register(&ParseClassConnection{className: class.ClassName})
+	}
+	s.Register(&File{})
+	s.Register(&GeoPoint{})
+	s.Register(&ACL{})
+	s.Register(&ACLEntry{})
+	s.Register(parseSchema)
+	return nil
+}
+
+// newClass returns an object of the class className using client, the schema of s and its
+// policy.
+func (s *ParseSchema) newClass(client *parse.Client, className string) (*ParseClass, error) {
+	pc, err := NewParseClass(client, className, s.Schema)
+	if err != nil {
+		return nil, err
+	}
+	pc.policy = s.Policy
+	return pc, nil
+}
+
+// exposed reports whether the class className exists and is not hidden by s.Policy.
+func (s *ParseSchema) exposed(className string) bool {
+	_, ok := s.Schema[className]
+	return ok && !s.Policy.classHidden(className)
+}
+
 func (s *ParseSchema) GraphQLTypeInfo() schema.GraphQLTypeInfo {
 	ti := schema.GraphQLTypeInfo{
 		Name:        "ParseSchema",
 		Description: "Parse schema object",
 		Fields: map[string]*schema.GraphQLFieldSpec{
-			"signUp": {"signUp", "Sign up a new user.", s.signUp, []graphql.Argument{
-				{Name: "username"}, {Name: "password"}, {Name: "email"},
-			}, true},
-			"logIn": {"logIn", "Authenticate as a user.", s.logIn, []graphql.Argument{
-				{Name: "username"}, {Name: "password"},
-			}, true},
-			"me": {"me", "Return the currently authenticated user.", s.me, nil, true},
+			"signUp": {
+				Name:        "signUp",
+				Description: "Sign up a new user with a username, a password and any other _User fields.",
+				Func:        s.signUp,
+				Type:        "_User",
+				Arguments:   s.userArguments(),
+				IsRoot:      true,
+			},
+			"logIn": {
+				Name:        "logIn",
+				Description: "Authenticate as a user.",
+				Func:        s.logIn,
+				Type:        "_User",
+				Arguments: []graphql.Argument{
+					{Name: "username"}, {Name: "password"},
+				},
+				IsRoot: true,
+			},
+			"logInWith": {
+				Name:        "logInWith",
+				Description: "Authenticate with the authData of a third-party provider, such as \"anonymous\" or \"facebook\", signing up a new user if needed.",
+				Func:        s.logInWith,
+				Type:        "_User",
+				Arguments:   []graphql.Argument{{Name: "provider"}, {Name: "authData"}},
+				IsRoot:      true,
+			},
+			"linkWith": {
+				Name:        "linkWith",
+				Description: "Link the account of a third-party provider, given by its authData, to the current user.",
+				Func:        s.linkWith,
+				Type:        "_User",
+				Arguments:   []graphql.Argument{{Name: "provider"}, {Name: "authData"}},
+				IsRoot:      true,
+			},
+			"unlinkWith": {
+				Name:        "unlinkWith",
+				Description: "Unlink the account of a third-party provider from the current user.",
+				Func:        s.unlinkWith,
+				Type:        "_User",
+				Arguments:   []graphql.Argument{{Name: "provider"}},
+				IsRoot:      true,
+			},
+			"me": {
+				Name:        "me",
+				Description: "Return the currently authenticated user.",
+				Func:        s.me,
+				Type:        "_User",
+				IsRoot:      true,
+			},
+			"logOut": {
+				Name:        "logOut",
+				Description: "Revoke the session of the current user.",
+				Func:        s.logOut,
+				Type:        "Boolean",
+				IsRoot:      true,
+			},
+			"updateMe": {
+				Name:        "updateMe",
+				Description: "Set the fields of the current user named by the arguments; null deletes a field.",
+				Func:        s.updateMe,
+				Type:        "_User",
+				Arguments:   s.userArguments(),
+				IsRoot:      true,
+			},
+			"deleteMe": {
+				Name:        "deleteMe",
+				Description: "Delete the current user.",
+				Func:        s.deleteMe,
+				Type:        "Boolean",
+				IsRoot:      true,
+			},
+			"requestPasswordReset": {
+				Name:        "requestPasswordReset",
+				Description: "Send a password reset email to 'email', or to the current user.",
+				Func:        s.requestPasswordReset,
+				Type:        "Boolean",
+				Arguments:   []graphql.Argument{{Name: "email"}},
+				IsRoot:      true,
+			},
+			"requestEmailVerification": {
+				Name:        "requestEmailVerification",
+				Description: "Send an email address verification email to 'email', or to the current user.",
+				Func:        s.requestEmailVerification,
+				Type:        "Boolean",
+				Arguments:   []graphql.Argument{{Name: "email"}},
+				IsRoot:      true,
+			},
+			"uploadFile": {
+				Name:        "uploadFile",
+				Description: "Upload a file sent in a multipart request, optionally storing it in a File field of an object.",
+				Func:        s.uploadFile,
+				Type:        "File",
+				Arguments: []graphql.Argument{
+					{Name: "file"}, {Name: "name"}, {Name: "className"}, {Name: "objectId"}, {Name: "field"},
+				},
+				IsRoot: true,
+			},
+			"sendPush": {
+				Name:        "sendPush",
+				Description: "Send a push notification to the installations subscribed to 'channels' or matching 'where'. Requires the master key. Returns the ID of the _PushStatus object tracking it, if any.",
+				Func:        s.sendPush,
+				Type:        "String",
+				Arguments: []graphql.Argument{
+					{Name: "channels"}, {Name: "where"}, {Name: "data"},
+					{Name: "pushTime"}, {Name: "expirationTime"}, {Name: "expirationInterval"},
+				},
+				IsRoot: true,
+			},
 		},
 	}
 
+	if s.exposed("_Role") {
+		ti.Fields["grantRole"] = &schema.GraphQLFieldSpec{
+			Name:        "grantRole",
+			Description: "Add the user 'userId' to the users of the role named 'role'.",
+			Func:        s.grantRole,
+			Type:        "_Role",
+			Arguments:   []graphql.Argument{{Name: "role"}, {Name: "userId"}},
+			IsRoot:      true,
+		}
+		ti.Fields["revokeRole"] = &schema.GraphQLFieldSpec{
+			Name:        "revokeRole",
+			Description: "Remove the user 'userId' from the users of the role named 'role'.",
+			Func:        s.revokeRole,
+			Type:        "_Role",
+			Arguments:   []graphql.Argument{{Name: "role"}, {Name: "userId"}},
+			IsRoot:      true,
+		}
+	}
+
+	if s.exposed("_Session") {
+		ti.Fields["mySessions"] = &schema.GraphQLFieldSpec{
+			Name:        "mySessions",
+			Description: "Return the sessions of the current user.",
+			Func:        s.mySessions,
+			Type:        "[_Session]",
+			Arguments:   []graphql.Argument{{Name: "where"}, {Name: "limit"}, {Name: "skip"}, {Name: "order"}},
+			IsRoot:      true,
+		}
+		ti.Fields["revokeSession"] = &schema.GraphQLFieldSpec{
+			Name:        "revokeSession",
+			Description: "Revoke the session 'id' of the current user.",
+			Func:        s.revokeSession,
+			Type:        "Boolean",
+			Arguments:   []graphql.Argument{{Name: "id"}},
+			IsRoot:      true,
+		}
+		ti.Fields["revokeAllOtherSessions"] = &schema.GraphQLFieldSpec{
+			Name:        "revokeAllOtherSessions",
+			Description: "Revoke the sessions of the current user but the one of the request, returning how many were revoked.",
+			Func:        s.revokeAllOtherSessions,
+			Type:        "Int",
+			IsRoot:      true,
+		}
+	}
+
 	for _, hookFunction := range s.hooks {
 		hookName := hookFunction.FunctionName
 		ti.Fields[hookName] = &schema.GraphQLFieldSpec{
 			Name:        hookName,
 			Description: fmt.Sprintf("Cloud Code function %s", hookName),
-			Func:        mkHookFieldFunc(s.client, s.Schema, hookName, nil),
+			Func:        mkHookFieldFunc(s.client, s.Schema, s.Policy, hookName, nil),
 			IsRoot:      true,
 		}
 	}
@@ -102,95 +336,121 @@ func (s *ParseSchema) GraphQLTypeInfo() schema.GraphQLTypeInfo {
 }
 
 func (s *ParseSchema) signUp(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
-	var u parse.ParseUser
-	// username
-	userName, ok := f.Arguments.Get("username")
-	if !ok {
-		return nil, fmt.Errorf("'username' field is required.")
-	}
-	u.Username, ok = userName.(string)
-	if !ok {
-		return nil, fmt.Errorf("'username' field must be a string.")
+	fields, err := s.userFields(f.Arguments, false)
+	if err != nil {
+		return nil, err
 	}
-
-	// password
-	password, ok := f.Arguments.Get("password")
-	if !ok {
-		return nil, fmt.Errorf("'password' field is required.")
+	for _, arg := range []string{"username", "password"} {
+		if value, _ := fields[arg].(string); value == "" {
+			return nil, argumentErrorf("'%s' field is required.", arg)
+		}
 	}
-	u.Password, ok = password.(string)
-	if !ok {
-		return nil, fmt.Errorf("'password' field must be a string.")
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
 	}
-
-	// email
-	email, ok := f.Arguments.Get("email")
-	if !ok {
-		return nil, fmt.Errorf("'email' field is required.")
+	created, err := tracedClient(ctx, s.client).CreateUser(fields)
+	if err != nil {
+		return nil, err
 	}
-	u.Email, ok = email.(string)
-	if !ok {
-		return nil, fmt.Errorf("'email' field must be a string.")
+	// Parse only returns the objectId, createdAt and sessionToken of new users
+	user := make(map[string]interface{}, len(fields)+3)
+	for k, v := range fields {
+		if k != "password" {
+			user[k] = v
+		}
 	}
-
-	return s.client.CreateUser(u)
+	user["objectId"], user["createdAt"], user["sessionToken"] = created.ID, created.CreatedAt, created.SessionToken
+	return s.loggedInUser(user)
 }
 
 func (s *ParseSchema) logIn(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
 	// username
 	usernamei, ok := f.Arguments.Get("username")
 	if !ok {
-		return nil, fmt.Errorf("'username' field is required.")
+		return nil, argumentErrorf("'username' field is required.")
 	}
 	username, ok := usernamei.(string)
 	if !ok {
-		return nil, fmt.Errorf("'username' field must be a string.")
+		return nil, argumentErrorf("'username' field must be a string.")
 	}
 
 	// password
 	passwordi, ok := f.Arguments.Get("password")
 	if !ok {
-		return nil, fmt.Errorf("'password' field is required.")
+		return nil, argumentErrorf("'password' field is required.")
 	}
 	password, ok := passwordi.(string)
 	if !ok {
-		return nil, fmt.Errorf("'password' field must be a string.")
+		return nil, argumentErrorf("'password' field must be a string.")
 	}
 
-	var u parse.ParseUser
-	err := s.client.LoginUser(username, password, &u)
-	return u, err
+	var user userData
+	if err := s.client.LoginUser(username, password, &user); err != nil {
+		return nil, err
+	}
+	return s.loggedInUser(user)
 }
 
-// client returns a *parse.Client that may be authed as a user if the provided context
-// contains an http request with the X-Parse-Session-Token header set.
+// userData is a user as returned by Parse.
+type userData map[string]interface{}
+
+func (u userData) ObjectID() string {
+	id, _ := u["objectId"].(string)
+	return id
+}
+
+// loggedInUser returns the user a logIn, signUp or logInWith request authenticated as,
+// whose fields are read as its own, as the caller of the request has no session yet.
+func (s *ParseSchema) loggedInUser(user map[string]interface{}) (*ParseClass, error) {
+	pc, err := s.newClass(s.client, "_User")
+	if err != nil {
+		return nil, err
+	}
+	pc.Data = user
+	pc.self = true
+	return pc, nil
+}
+
+// authedClient returns the client of s authed as the caller of the request in ctx (see
+// requestClient).
 func (s *ParseSchema) authedClient(ctx context.Context) *parse.Client {
-	r := ctx.Value("http_request")
-	if r != nil {
-		request, ok := r.(*http.Request)
-		if ok && request.Header.Get("X-Parse-Session-Token") != "" {
-			return s.client.WithSessionToken(request.Header.Get("X-Parse-Session-Token"))
-		}
+	return requestClient(ctx, s.client)
+}
+
+// requestClient returns the client authed as the caller of the request in ctx: the one an
+// authenticator attached to ctx or, without one, client authed with the master key of the
+// request's X-Parse-Master-Key header, which Parse checks, and the session token of its
+// X-Parse-Session-Token or 'Authorization: Bearer' header.
+func requestClient(ctx context.Context, client *parse.Client) *parse.Client {
+	if auth, ok := ctx.Value(requestAuthKey).(*requestAuth); ok {
+		return auth.client
 	}
-	return s.client
+	return requestIdentity(ctx).authed(client)
+}
+
+// sessionToken returns the session token of the caller of the request in ctx, if any.
+func sessionToken(ctx context.Context) string {
+	return requestIdentity(ctx).SessionToken
+}
+
+// isMaster reports whether the caller of the request in ctx was verified to hold the
+// master key. Master keys merely sent along, as requests without an authenticator do,
+// do not count.
+func isMaster(ctx context.Context) bool {
+	return requestIdentity(ctx).Master
 }
 
 func (s *ParseSchema) me(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
 	c := s.authedClient(ctx)
-	var user parse.ParseUser
-	err := c.CurrentUser(&user)
+	pc, err := s.newClass(c, "_User")
 	if err != nil {
 		return nil, err
 	}
-	pc, err := NewParseClass(c, "_User", s.Schema)
-	if err != nil {
-		return nil, err
-	}
-	pc.Data, err = tomap(user)
+	pc.Data, err = s.currentUser(ctx, tracedClient(ctx, c))
 	return pc, err
 }
 
-func mkHookFieldFunc(client *parse.Client, schema map[string]*parse.Schema, hookName string, data map[string]interface{}) schema.GraphQLFieldFunc {
+func mkHookFieldFunc(client *parse.Client, schema map[string]*parse.Schema, policy *Policy, hookName string, data map[string]interface{}) schema.GraphQLFieldFunc {
 	return func(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
 		output, err := client.CallCloudFunction(hookName, data)
 		if err != nil {
@@ -219,11 +479,15 @@ func mkHookFieldFunc(client *parse.Client, schema map[string]*parse.Schema, hook
 					result = append(result, obj)
 					continue
 				}
+				if policy.classHidden(cn) {
+					continue
+				}
 				pc, err := NewParseClass(client, cn, schema)
-				pc.Data = mapobj
 				if err != nil {
 					result = append(result, obj)
 				} else {
+					pc.Data = mapobj
+					pc.policy = policy
 					result = append(result, pc)
 				}
 			}
@@ -233,13 +497,3 @@ func mkHookFieldFunc(client *parse.Client, schema map[string]*parse.Schema, hook
 
 	}
 }
-
-// tomap attempts to convert a value to a map[string]interface via encoding/json
-func tomap(value interface{}) (map[string]interface{}, error) {
-	asjson, err := json.Marshal(value)
-	if err != nil {
-		return nil, err
-	}
-	var asmap map[string]interface{}
-	return asmap, json.Unmarshal(asjson, &asmap)
-}
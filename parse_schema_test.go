@@ -0,0 +1,196 @@
+package parse_graphql_test
+
+import (
+	"testing"
+
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/parsetest"
+)
+
+// blog returns a parsetest server holding posts and their comments.
+func blog(t *testing.T) (*parsetest.Server, map[string]string) {
+	p := newParse(t)
+	p.AddClass("Post", map[string]parse.SchemaField{"title": {Type: "String"}, "published": {Type: "Boolean"}})
+	p.AddClass("Comment", map[string]parse.SchemaField{"body": {Type: "String"}, "post": {Type: "Pointer", TargetClass: "Post"}})
+	ids := map[string]string{}
+	for _, post := range []struct {
+		title     string
+		published bool
+	}{{"a", true}, {"b", false}, {"c", true}} {
+		ids[post.title] = p.AddObject("Post", map[string]interface{}{"title": post.title, "published": post.published})
+	}
+	for _, body := range []string{"first", "second"} {
+		p.AddObject("Comment", map[string]interface{}{"body": body, "post": map[string]interface{}{"__type": "Pointer", "className": "Post", "objectId": ids["a"]}})
+	}
+	return p, ids
+}
+
+func titles(objects []map[string]interface{}) []interface{} {
+	var titles []interface{}
+	for _, o := range objects {
+		titles = append(titles, o["title"])
+	}
+	return titles
+}
+
+func TestClassQueries(t *testing.T) {
+	p, _ := blog(t)
+	e := newEndpoint(t, p, nil, nil)
+
+	r := e.post(t, nil, `{ Post(where: {published: true}, order: "-title") { title }, PostCount(where: {published: false}) }`)
+	if got := titles(r.objects(t, 0)); len(got) != 2 || got[0] != "c" || got[1] != "a" {
+		t.Errorf("published posts: got %v, want [c a]", got)
+	}
+	if count := r.field(t, 1); count != float64(1) {
+		t.Errorf("unpublished count: got %v, want 1", count)
+	}
+
+	r = e.post(t, nil, `{ PostConnection(limit: 1, skip: 1, order: "title") { totalCount, results { title } } }`)
+	page, _ := r.field(t, 0).(map[string]interface{})
+	if results, _ := page["results"].([]interface{}); page["totalCount"] != float64(3) || len(results) != 1 || results[0].(map[string]interface{})["title"] != "b" {
+		t.Errorf("connection: got %v, want a total of 3 and post b", page)
+	}
+}
+
+func TestCountGeoArguments(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Place", map[string]parse.SchemaField{"location": {Type: "GeoPoint"}})
+	for _, lat := range []float64{37.75, 37.78, 40.7} {
+		p.AddObject("Place", map[string]interface{}{"location": map[string]interface{}{"__type": "GeoPoint", "latitude": lat, "longitude": -122.4}})
+	}
+	e := newEndpoint(t, p, nil, nil)
+	box := `withinBox: {location: [{latitude: 37.7, longitude: -122.5}, {latitude: 37.8, longitude: -122.3}]}`
+	r := e.post(t, nil, `{ PlaceCount(`+box+`), PlaceConnection(`+box+`) { totalCount } }`)
+	if count := r.field(t, 0); count != float64(2) {
+		t.Errorf("count: got %v (error %v), want 2", count, r.Error)
+	}
+	if page, _ := r.field(t, 1).(map[string]interface{}); page["totalCount"] != float64(2) {
+		t.Errorf("connection: got %v, want a total of 2", page)
+	}
+}
+
+func TestGeoNearDistance(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Place", map[string]parse.SchemaField{"name": {Type: "String"}, "location": {Type: "GeoPoint"}})
+	for name, lat := range map[string]float64{"far": 38.77, "near": 37.77, "away": 40.7} {
+		p.AddObject("Place", map[string]interface{}{"name": name, "location": map[string]interface{}{"__type": "GeoPoint", "latitude": lat, "longitude": -122.41}})
+	}
+	e := newEndpoint(t, p, nil, nil)
+	r := e.post(t, nil, `{ Place(near: {location: {latitude: 37.77, longitude: -122.41}}, withinKilometers: 200) { name, location { km: distance, mi: distance(unit: "mi") } } }`)
+	places := r.objects(t, 0)
+	if len(places) != 2 || places[0]["name"] != "near" || places[1]["name"] != "far" {
+		t.Fatalf("got %v (error %v), want near then far", places, r.Error)
+	}
+	// one degree of latitude is about 111.2 km
+	location, _ := places[1]["location"].(map[string]interface{})
+	km, _ := location["km"].(float64)
+	mi, _ := location["mi"].(float64)
+	if km < 111 || km > 111.4 || mi < 69 || mi > 69.2 {
+		t.Errorf("distance of far: got %v km and %v mi, want about 111.2 km and 69.1 mi", location["km"], location["mi"])
+	}
+	if location, _ := places[0]["location"].(map[string]interface{}); location["km"] != float64(0) {
+		t.Errorf("distance of near: got %v, want 0", location["km"])
+	}
+
+	// points from queries without 'near' have no distance
+	r = e.post(t, nil, `{ Place(where: {name: "far"}) { location { distance } } }`)
+	if location, _ := r.objects(t, 0)[0]["location"].(map[string]interface{}); location["distance"] != nil {
+		t.Errorf("without near: got %v, want null", location["distance"])
+	}
+	if r := e.post(t, nil, `{ Place(near: {location: {latitude: 37.77, longitude: -122.41}}) { location { distance(unit: "ft") } } }`); r.Error == nil {
+		t.Errorf("invalid unit: got %v, want an error", r.Data)
+	}
+}
+
+func TestGeneratedNameCollisions(t *testing.T) {
+	for name, classes := range map[string]map[string]*parse.Schema{
+		"count class": {
+			"Post":      {ClassName: "Post", Fields: map[string]parse.SchemaField{}},
+			"PostCount": {ClassName: "PostCount", Fields: map[string]parse.SchemaField{}},
+		},
+		"connection class": {
+			"Post":           {ClassName: "Post", Fields: map[string]parse.SchemaField{}},
+			"PostConnection": {ClassName: "PostConnection", Fields: map[string]parse.SchemaField{}},
+		},
+		"count field": {
+			"Post": {ClassName: "Post", Fields: map[string]parse.SchemaField{"PostCount": {Type: "Number"}}},
+		},
+		"reverse pointer connection": {
+			"Post": {ClassName: "Post", Fields: map[string]parse.SchemaField{"Comment_postConnection": {Type: "String"}}},
+			"Comment": {ClassName: "Comment", Fields: map[string]parse.SchemaField{
+				"post": {Type: "Pointer", TargetClass: "Post"},
+			}},
+		},
+	} {
+		if _, err := parse_graphql.NewParseSchema(nil, classes, nil); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
+
+func TestReversePointers(t *testing.T) {
+	p, _ := blog(t)
+	e := newEndpoint(t, p, nil, nil)
+	r := e.post(t, nil, `{ Post(where: {title: "a"}) { title, Comment_post(order: "-body", limit: 1) { body, post { title } } } }`)
+	posts := r.objects(t, 0)
+	if len(posts) != 1 {
+		t.Fatalf("got %v, want post a", posts)
+	}
+	comments, _ := posts[0]["Comment_post"].([]interface{})
+	if len(comments) != 1 {
+		t.Fatalf("comments: got %v, want one", posts[0]["Comment_post"])
+	}
+	comment := comments[0].(map[string]interface{})
+	if post, _ := comment["post"].(map[string]interface{}); comment["body"] != "second" || post["title"] != "a" {
+		t.Errorf("comment: got %v, want the second comment of post a", comment)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	p, ids := blog(t)
+	e := newEndpoint(t, p, nil, nil)
+	r := e.post(t, nil, `mutation c { createComment(body: "third", post: "`+ids["c"]+`") { objectId, body } }`)
+	created, _ := r.field(t, 0).(map[string]interface{})
+	id, _ := created["objectId"].(string)
+	if id == "" || created["body"] != "third" {
+		t.Fatalf("created: got %v (error %v)", created, r.Error)
+	}
+	if post, _ := p.Object("Comment", id)["post"].(map[string]interface{}); post["objectId"] != ids["c"] {
+		t.Errorf("stored pointer: got %v, want post c", post)
+	}
+
+	e.post(t, nil, `mutation u { updatePost(objectId: "`+ids["b"]+`", title: "b2", published: null) { objectId } }`)
+	post := p.Object("Post", ids["b"])
+	if _, published := post["published"]; post["title"] != "b2" || published {
+		t.Errorf("updated post: got %v, want title b2 and no published field", post)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	p := newParse(t)
+	e := newEndpoint(t, p, nil, nil)
+	r := e.post(t, nil, `mutation s { signUp(username: "ann", password: "pw", email: "ann@example.com") { objectId, sessionToken } }`)
+	if user, _ := r.field(t, 0).(map[string]interface{}); user["sessionToken"] == nil {
+		t.Fatalf("signUp: got %v (error %v)", r.Data, r.Error)
+	}
+
+	r = e.post(t, nil, `mutation l { logIn(username: "ann", password: "wrong") { sessionToken } }`)
+	if r.Error == nil {
+		t.Errorf("logIn with a wrong password: got %v, want an error", r.Data)
+	}
+	r = e.post(t, nil, `mutation l { logIn(username: "ann", password: "pw") { sessionToken } }`)
+	user, _ := r.field(t, 0).(map[string]interface{})
+	token, _ := user["sessionToken"].(string)
+	if token == "" {
+		t.Fatalf("logIn: got %v (error %v)", r.Data, r.Error)
+	}
+
+	r = e.post(t, map[string]string{"Authorization": "Bearer " + token}, `{ me { username, email } }`)
+	if me, _ := r.field(t, 0).(map[string]interface{}); me["username"] != "ann" || me["email"] != "ann@example.com" {
+		t.Errorf("me: got %v, want ann", me)
+	}
+	if r := e.post(t, map[string]string{"X-Parse-Session-Token": "r:invalid"}, `{ me { username } }`); r.Error == nil || r.Error.Extensions["code"] != "UNAUTHENTICATED" {
+		t.Errorf("me with an invalid session: got %v, want UNAUTHENTICATED", r.Error)
+	}
+}
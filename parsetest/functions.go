@@ -0,0 +1,476 @@
+package parsetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tmc/parse"
+)
+
+// FunctionRequest describes a call to a Cloud Code function.
+type FunctionRequest struct {
+	Params map[string]interface{}
+	// User is the calling user, nil for anonymous calls.
+	User   map[string]interface{}
+	Master bool
+}
+
+// Function implements a Cloud Code function. Errors other than *parse.Error are reported
+// to callers as ErrScriptError.
+type Function func(req *FunctionRequest) (interface{}, error)
+
+// HandleFunction defines the Cloud Code function name.
+func (s *Server) HandleFunction(name string, fn Function) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.functions[name] = fn
+}
+
+// HandleJob defines the Cloud Code job name. Jobs run synchronously when started.
+func (s *Server) HandleJob(name string, fn Function) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = fn
+}
+
+// AddHookFunction registers a webhook implementing the Cloud Code function name, as if
+// created through /1/hooks/functions.
+func (s *Server) AddHookFunction(name, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setHook(&parse.HookFunction{FunctionName: name, URL: url})
+}
+
+func (s *Server) setHook(hook *parse.HookFunction) {
+	for i, h := range s.hooks {
+		if h.FunctionName == hook.FunctionName {
+			s.hooks[i] = hook
+			return
+		}
+	}
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *Server) hook(name string) *parse.HookFunction {
+	for _, h := range s.hooks {
+		if h.FunctionName == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// callFunction runs a Cloud Code function, either defined with HandleFunction or by
+// calling its webhook. It is called without holding the server lock.
+func (s *Server) callFunction(r *request, path []string) (interface{}, error) {
+	if len(path) != 1 || r.Method != "POST" {
+		return nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+	}
+	name := path[0]
+	params := map[string]interface{}{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			return nil, errorf(parse.ErrInvalidJSON, "invalid JSON: %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	fn, hook := s.functions[name], s.hook(name)
+	s.mu.Unlock()
+	var result interface{}
+	switch {
+	case fn != nil:
+		result, err = fn(&FunctionRequest{Params: params, User: r.user, Master: r.master})
+	case hook != nil:
+		result, err = callWebhook(hook.URL, params, r)
+	default:
+		return nil, errorf(parse.ErrScriptError, "Invalid function: \"%s\"", name)
+	}
+	if err != nil {
+		if _, ok := err.(*parse.Error); !ok {
+			err = errorf(parse.ErrScriptError, "%v", err)
+		}
+		return nil, err
+	}
+	return map[string]interface{}{"result": result}, nil
+}
+
+// runJob runs a Cloud Code job defined with HandleJob, which requires the master key.
+// It is called without holding the server lock.
+func (s *Server) runJob(r *request, path []string) (interface{}, error) {
+	if !r.master {
+		return nil, errorf(parse.ErrOperationForbidden, "unauthorized: master key is required")
+	}
+	if len(path) != 1 || r.Method != "POST" {
+		return nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+	}
+	params := map[string]interface{}{}
+	if err := decodeBody(r, &params); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	fn := s.jobs[path[0]]
+	statusID := s.newID()
+	s.mu.Unlock()
+	if fn == nil {
+		return nil, errorf(parse.ErrScriptError, "Invalid job: \"%s\"", path[0])
+	}
+	if _, err := fn(&FunctionRequest{Params: params, User: r.user, Master: true}); err != nil {
+		if _, ok := err.(*parse.Error); !ok {
+			err = errorf(parse.ErrScriptError, "%v", err)
+		}
+		return nil, err
+	}
+	return withHeader{name: "X-Parse-Job-Status-Id", value: statusID, body: map[string]interface{}{}}, nil
+}
+
+// callWebhook calls a Cloud Code webhook the way Parse does.
+func callWebhook(url string, params map[string]interface{}, r *request) (interface{}, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"params": params,
+		"user":   r.user,
+		"master": r.master,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("calling webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	var response struct {
+		Success interface{} `json:"success"`
+		Error   interface{} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid webhook response: %v", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%v", response.Error)
+	}
+	return response.Success, nil
+}
+
+// serveHooks serves the /1/hooks/functions endpoints.
+func (s *Server) serveHooks(r *request, path []string) (int, interface{}, error) {
+	if !r.master {
+		return 0, nil, errorf(parse.ErrOperationForbidden, "unauthorized: master key is required")
+	}
+	if len(path) == 0 || path[0] != "functions" {
+		return 0, nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+	}
+	switch {
+	case len(path) == 1 && r.Method == "GET":
+		hooks := append([]*parse.HookFunction{}, s.hooks...)
+		sort.Slice(hooks, func(i, j int) bool { return hooks[i].FunctionName < hooks[j].FunctionName })
+		return http.StatusOK, map[string]interface{}{"results": hooks}, nil
+	case len(path) == 1 && r.Method == "POST":
+		var hook parse.HookFunction
+		if err := decodeBody(r, &hook); err != nil {
+			return 0, nil, err
+		}
+		if hook.FunctionName == "" || hook.URL == "" {
+			return 0, nil, errorf(parse.ErrScriptError, "functionName and url are required")
+		}
+		if s.hook(hook.FunctionName) != nil {
+			return 0, nil, errorf(parse.ErrScriptError, "function name: %s already exists", hook.FunctionName)
+		}
+		s.setHook(&hook)
+		return http.StatusCreated, &hook, nil
+	case len(path) == 2:
+		hook := s.hook(path[1])
+		if hook == nil {
+			return 0, nil, errorf(parse.ErrScriptError, "no function named: %s is defined", path[1])
+		}
+		switch r.Method {
+		case "GET":
+			return http.StatusOK, hook, nil
+		case "PUT":
+			var update map[string]interface{}
+			if err := decodeBody(r, &update); err != nil {
+				return 0, nil, err
+			}
+			if op, _ := update["__op"].(string); op == "Delete" {
+				s.deleteHook(path[1])
+				return http.StatusOK, map[string]interface{}{}, nil
+			}
+			if url, ok := update["url"].(string); ok {
+				s.setHook(&parse.HookFunction{FunctionName: path[1], URL: url})
+			}
+			return http.StatusOK, s.hook(path[1]), nil
+		case "DELETE":
+			s.deleteHook(path[1])
+			return http.StatusOK, map[string]interface{}{}, nil
+		}
+	}
+	return 0, nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+}
+
+func (s *Server) deleteHook(name string) {
+	for i, h := range s.hooks {
+		if h.FunctionName == name {
+			s.hooks = append(s.hooks[:i], s.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// serveSchemas serves the /1/schemas endpoints. Class-level permissions are stored but
+// not enforced.
+func (s *Server) serveSchemas(r *request, path []string) (interface{}, error) {
+	if !r.master {
+		return nil, errorf(parse.ErrOperationForbidden, "unauthorized: master key is required")
+	}
+	className := ""
+	if len(path) > 0 {
+		className = path[0]
+	}
+	switch {
+	case r.Method == "GET" && className != "":
+		schema, ok := s.schemas[className]
+		if !ok {
+			return nil, errorf(parse.ErrInvalidClassName, "class %s does not exist", className)
+		}
+		return schema, nil
+	case r.Method == "GET":
+		names := make([]string, 0, len(s.schemas))
+		for name := range s.schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		results := make([]*parse.Schema, 0, len(names))
+		for _, name := range names {
+			results = append(results, s.schemas[name])
+		}
+		return map[string]interface{}{"results": results}, nil
+	case className == "":
+	case r.Method == "POST":
+		return s.createSchema(r, className)
+	case r.Method == "PUT":
+		return s.updateSchema(r, className)
+	case r.Method == "DELETE":
+		return s.deleteSchema(className)
+	}
+	return nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+}
+
+// schemaBody is the body of requests writing a class schema. Fields are either a field
+// definition or a {"__op": "Delete"} operation.
+type schemaBody struct {
+	ClassName             string                            `json:"className"`
+	Fields                map[string]map[string]interface{} `json:"fields"`
+	ClassLevelPermissions map[string]interface{}            `json:"classLevelPermissions"`
+}
+
+func (s *Server) decodeSchemaBody(r *request, className string) (*schemaBody, error) {
+	body := &schemaBody{}
+	if err := decodeBody(r, body); err != nil {
+		return nil, err
+	}
+	if body.ClassName != "" && body.ClassName != className {
+		return nil, errorf(parse.ErrInvalidClassName, "class name mismatch between %s and %s", body.ClassName, className)
+	}
+	return body, nil
+}
+
+func (s *Server) createSchema(r *request, className string) (interface{}, error) {
+	body, err := s.decodeSchemaBody(r, className)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.schemas[className]; ok {
+		return nil, errorf(parse.ErrInvalidClassName, "class %s already exists", className)
+	}
+	if !validSchemaName.MatchString(className) {
+		return nil, errorf(parse.ErrInvalidClassName, "invalid classname: %s", className)
+	}
+	fields := map[string]parse.SchemaField{}
+	for name, def := range body.Fields {
+		field, err := s.schemaField(name, def)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := defaultFields[name]; ok {
+			return nil, errorf(parse.ErrInvalidKeyName, "field %s exists, cannot update", name)
+		}
+		fields[name] = field
+	}
+	schema := s.addClass(className, fields)
+	schema.ClassLevelPermissions = body.ClassLevelPermissions
+	return schema, nil
+}
+
+func (s *Server) updateSchema(r *request, className string) (interface{}, error) {
+	body, err := s.decodeSchemaBody(r, className)
+	if err != nil {
+		return nil, err
+	}
+	schema, ok := s.schemas[className]
+	if !ok {
+		return nil, errorf(parse.ErrInvalidClassName, "class %s does not exist", className)
+	}
+	added := map[string]parse.SchemaField{}
+	var deleted []string
+	for name, def := range body.Fields {
+		_, exists := schema.Fields[name]
+		if def["__op"] == "Delete" {
+			if _, ok := defaultFields[name]; ok {
+				return nil, errorf(parse.ErrInvalidKeyName, "field %s cannot be changed", name)
+			}
+			if !exists {
+				return nil, errorf(parse.ErrClassNotEmpty, "field %s does not exist, cannot delete", name)
+			}
+			deleted = append(deleted, name)
+			continue
+		}
+		if exists {
+			return nil, errorf(parse.ErrClassNotEmpty, "field %s exists, cannot update", name)
+		}
+		field, err := s.schemaField(name, def)
+		if err != nil {
+			return nil, err
+		}
+		added[name] = field
+	}
+	for _, name := range deleted {
+		delete(schema.Fields, name)
+		for _, obj := range s.objects[className] {
+			delete(obj, name)
+		}
+	}
+	s.addClass(className, added)
+	if body.ClassLevelPermissions != nil {
+		schema.ClassLevelPermissions = body.ClassLevelPermissions
+	}
+	return schema, nil
+}
+
+func (s *Server) deleteSchema(className string) (interface{}, error) {
+	if _, ok := s.schemas[className]; !ok {
+		return map[string]interface{}{}, nil
+	}
+	if strings.HasPrefix(className, "_") {
+		return nil, errorf(parse.ErrInvalidClassName, "cannot delete system class %s", className)
+	}
+	if n := len(s.objects[className]); n > 0 {
+		return nil, errorf(parse.ErrClassNotEmpty, "class %s is not empty, contains %d objects, cannot drop schema", className, n)
+	}
+	delete(s.schemas, className)
+	delete(s.objects, className)
+	return map[string]interface{}{}, nil
+}
+
+// validSchemaName matches the names of the classes and fields schemas may add.
+var validSchemaName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// schemaTypes are the field types schemas may add, and whether they need a target class.
+var schemaTypes = map[string]bool{
+	"String": false, "Number": false, "Boolean": false, "Date": false, "Object": false,
+	"Array": false, "GeoPoint": false, "File": false, "Bytes": false, "Polygon": false,
+	"Pointer": true, "Relation": true,
+}
+
+// schemaField returns the field name defined by def, a {"type", "targetClass"} object.
+func (s *Server) schemaField(name string, def map[string]interface{}) (parse.SchemaField, error) {
+	if !validSchemaName.MatchString(name) {
+		return parse.SchemaField{}, errorf(parse.ErrInvalidKeyName, "invalid field name: %s", name)
+	}
+	field := parse.SchemaField{}
+	field.Type, _ = def["type"].(string)
+	field.TargetClass, _ = def["targetClass"].(string)
+	targeted, ok := schemaTypes[field.Type]
+	switch {
+	case !ok:
+		return field, errorf(parse.ErrIncorrectType, "invalid field type: %v", def["type"])
+	case targeted && field.TargetClass == "":
+		return field, errorf(parse.ErrMissingObjectID, "type %s needs a class name", field.Type)
+	case targeted && s.schemas[field.TargetClass] == nil:
+		return field, errorf(parse.ErrInvalidClassName, "class %s does not exist", field.TargetClass)
+	case !targeted && field.TargetClass != "":
+		return field, errorf(parse.ErrIncorrectType, "type %s takes no class name", field.Type)
+	}
+	return field, nil
+}
+
+type file struct {
+	contentType string
+	data        []byte
+}
+
+// File returns the contents and content type of an uploaded file.
+func (s *Server) File(name string) (data []byte, contentType string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[name]
+	if !ok {
+		return nil, "", false
+	}
+	return f.data, f.contentType, true
+}
+
+// serveFiles serves the /1/files endpoints, which upload and delete files.
+func (s *Server) serveFiles(r *request, path []string) (int, interface{}, error) {
+	if len(path) != 1 || path[0] == "" {
+		return 0, nil, errorf(parse.ErrInvalidFileName, "Filename is required.")
+	}
+	switch r.Method {
+	case "POST":
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return 0, nil, err
+		}
+		prefix := make([]byte, 8)
+		rand.Read(prefix)
+		name := hex.EncodeToString(prefix) + "_" + path[0]
+		s.files[name] = &file{contentType: r.Header.Get("Content-Type"), data: data}
+		url := s.fileURL(name)
+		return http.StatusCreated, created{
+			location: url,
+			body:     map[string]interface{}{"url": url, "name": name},
+		}, nil
+	case "DELETE":
+		if !r.master {
+			return 0, nil, errorf(parse.ErrOperationForbidden, "unauthorized: master key is required")
+		}
+		if _, ok := s.files[path[0]]; !ok {
+			return 0, nil, errorf(parse.ErrFileDeleteFailure, "Could not delete file.")
+		}
+		delete(s.files, path[0])
+		return http.StatusOK, map[string]interface{}{}, nil
+	}
+	return 0, nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+}
+
+func (s *Server) fileURL(name string) string {
+	return fmt.Sprintf("%s/files/%s/%s", s.URL, s.AppID, name)
+}
+
+// serveFileContents serves the contents of uploaded files at their URL.
+func (s *Server) serveFileContents(w http.ResponseWriter, r *http.Request, path []string) {
+	if len(path) != 2 || path[0] != s.AppID {
+		http.NotFound(w, r)
+		return
+	}
+	data, contentType, ok := s.File(path[1])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if contentType != "" && !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Write(data)
+}
@@ -0,0 +1,152 @@
+package parsetest
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tmc/parse"
+)
+
+// Earth radii Parse converts distances to radians with.
+const (
+	earthRadiusKilometers = 6371.0
+	earthRadiusMiles      = 3958.8
+)
+
+type point struct {
+	lat, lng float64
+}
+
+// geoPoint returns the position of a GeoPoint value.
+func geoPoint(v interface{}) (point, bool) {
+	m, _ := v.(map[string]interface{})
+	if m == nil || m["__type"] != "GeoPoint" {
+		return point{}, false
+	}
+	lat, latOK := m["latitude"].(float64)
+	lng, lngOK := m["longitude"].(float64)
+	return point{lat, lng}, latOK && lngOK
+}
+
+func geoPoints(v interface{}) ([]point, bool) {
+	list, _ := v.([]interface{})
+	points := make([]point, len(list))
+	for i, e := range list {
+		p, ok := geoPoint(e)
+		if !ok {
+			return nil, false
+		}
+		points[i] = p
+	}
+	return points, list != nil
+}
+
+// radians returns the great-circle distance between a and b in radians.
+func radians(a, b point) float64 {
+	lat1, lat2 := a.lat*math.Pi/180, b.lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.lng - a.lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// matchGeo evaluates the geo query operators.
+func matchGeo(value interface{}, op string, arg interface{}, ops map[string]interface{}) (bool, error) {
+	switch op {
+	case "$maxDistance", "$maxDistanceInRadians", "$maxDistanceInKilometers", "$maxDistanceInMiles":
+		if _, ok := ops["$nearSphere"]; !ok {
+			return false, errorf(parse.ErrInvalidQuery, "%s requires $nearSphere", op)
+		}
+		return true, nil
+	case "$nearSphere":
+		origin, ok := geoPoint(arg)
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "$nearSphere requires a GeoPoint")
+		}
+		p, ok := geoPoint(value)
+		if !ok {
+			return false, nil
+		}
+		max := math.Inf(1)
+		if d, ok := ops["$maxDistance"].(float64); ok {
+			max = d
+		}
+		if d, ok := ops["$maxDistanceInRadians"].(float64); ok {
+			max = d
+		}
+		if d, ok := ops["$maxDistanceInKilometers"].(float64); ok {
+			max = d / earthRadiusKilometers
+		}
+		if d, ok := ops["$maxDistanceInMiles"].(float64); ok {
+			max = d / earthRadiusMiles
+		}
+		return radians(origin, p) <= max, nil
+	case "$within":
+		box, _ := arg.(map[string]interface{})
+		corners, ok := geoPoints(box["$box"])
+		if !ok || len(corners) != 2 {
+			return false, errorf(parse.ErrInvalidQuery, "$within requires a $box of 2 GeoPoints")
+		}
+		p, ok := geoPoint(value)
+		sw, ne := corners[0], corners[1]
+		return ok && p.lat >= sw.lat && p.lat <= ne.lat && p.lng >= sw.lng && p.lng <= ne.lng, nil
+	case "$geoWithin":
+		within, _ := arg.(map[string]interface{})
+		if polygon, ok := geoPoints(within["$polygon"]); ok {
+			if len(polygon) < 3 {
+				return false, errorf(parse.ErrInvalidQuery, "$polygon requires at least 3 GeoPoints")
+			}
+			p, ok := geoPoint(value)
+			return ok && inPolygon(p, polygon), nil
+		}
+		if sphere, _ := within["$centerSphere"].([]interface{}); len(sphere) == 2 {
+			center, ok := geoPoint(sphere[0])
+			if !ok {
+				if coords, _ := sphere[0].([]interface{}); len(coords) == 2 {
+					lng, _ := coords[0].(float64)
+					lat, _ := coords[1].(float64)
+					center, ok = point{lat, lng}, true
+				}
+			}
+			distance, distanceOK := sphere[1].(float64)
+			if ok && distanceOK {
+				p, ok := geoPoint(value)
+				return ok && radians(center, p) <= distance, nil
+			}
+		}
+		return false, errorf(parse.ErrInvalidQuery, "$geoWithin requires a $polygon or a $centerSphere")
+	}
+	return false, errorf(parse.ErrInvalidQuery, "bad constraint: %s", op)
+}
+
+// inPolygon reports whether p lies within polygon, by ray casting.
+func inPolygon(p point, polygon []point) bool {
+	in := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.lat > p.lat) != (b.lat > p.lat) && p.lng < (b.lng-a.lng)*(p.lat-a.lat)/(b.lat-a.lat)+a.lng {
+			in = !in
+		}
+	}
+	return in
+}
+
+// sortByDistance sorts objects by increasing distance to the $nearSphere origin of a top
+// level field of where, if any, as Parse does for queries without an order.
+func sortByDistance(objects []map[string]interface{}, where map[string]interface{}) {
+	for key, cond := range where {
+		ops, _ := cond.(map[string]interface{})
+		origin, ok := geoPoint(ops["$nearSphere"])
+		if !ok {
+			continue
+		}
+		distance := func(obj map[string]interface{}) float64 {
+			p, _ := geoPoint(obj[key])
+			return radians(origin, p)
+		}
+		sort.SliceStable(objects, func(i, j int) bool {
+			return distance(objects[i]) < distance(objects[j])
+		})
+		return
+	}
+}
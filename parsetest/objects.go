@@ -0,0 +1,547 @@
+package parsetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/parse"
+)
+
+// defaultFields are the fields every class has.
+var defaultFields = map[string]parse.SchemaField{
+	"objectId":  {Type: "String"},
+	"createdAt": {Type: "Date"},
+	"updatedAt": {Type: "Date"},
+	"ACL":       {Type: "ACL"},
+}
+
+var userFields = map[string]parse.SchemaField{
+	"username":      {Type: "String"},
+	"password":      {Type: "String"},
+	"email":         {Type: "String"},
+	"emailVerified": {Type: "Boolean"},
+	"authData":      {Type: "Object"},
+}
+
+var roleFields = map[string]parse.SchemaField{
+	"name":  {Type: "String"},
+	"users": {Type: "Relation", TargetClass: "_User"},
+	"roles": {Type: "Relation", TargetClass: "_Role"},
+}
+
+var sessionFields = map[string]parse.SchemaField{
+	"sessionToken":   {Type: "String"},
+	"user":           {Type: "Pointer", TargetClass: "_User"},
+	"createdWith":    {Type: "Object"},
+	"restricted":     {Type: "Boolean"},
+	"expiresAt":      {Type: "Date"},
+	"installationId": {Type: "String"},
+}
+
+// AddClass adds a class with the given fields, in addition to the default ones, to the
+// schema of the server. Adding fields to an existing class extends it.
+func (s *Server) AddClass(className string, fields map[string]parse.SchemaField) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addClass(className, fields)
+}
+
+func (s *Server) addClass(className string, fields map[string]parse.SchemaField) *parse.Schema {
+	schema, ok := s.schemas[className]
+	if !ok {
+		schema = &parse.Schema{ClassName: className, Fields: map[string]parse.SchemaField{}}
+		for name, field := range defaultFields {
+			schema.Fields[name] = field
+		}
+		s.schemas[className] = schema
+		s.objects[className] = map[string]map[string]interface{}{}
+	}
+	for name, field := range fields {
+		schema.Fields[name] = field
+	}
+	return schema
+}
+
+// AddObject stores object in className, bypassing ACLs and adding the class and any
+// missing fields to the schema, and returns the ID of the new object. It panics if
+// object does not match the schema.
+func (s *Server) AddObject(className string, object map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, err := s.create(className, object)
+	if err != nil {
+		panic(fmt.Sprintf("parsetest: adding %s object: %v", className, err))
+	}
+	return obj["objectId"].(string)
+}
+
+// AddUser signs up a user and returns its ID and a session token for it.
+func (s *Server) AddUser(username, password string, fields map[string]interface{}) (objectID, sessionToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body := map[string]interface{}{}
+	for k, v := range fields {
+		body[k] = v
+	}
+	body["username"], body["password"] = username, password
+	user, err := s.signUp(body)
+	if err != nil {
+		panic(fmt.Sprintf("parsetest: adding user %s: %v", username, err))
+	}
+	return user["objectId"].(string), user["sessionToken"].(string)
+}
+
+// Object returns a copy of an object stored by the server, or nil if there is none.
+func (s *Server) Object(className, objectID string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[className][objectID]
+	if !ok {
+		return nil
+	}
+	return copyValue(obj).(map[string]interface{})
+}
+
+// Objects returns copies of the objects of className, in creation order.
+func (s *Server) Objects(className string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var results []map[string]interface{}
+	for _, obj := range s.sortedObjects(className) {
+		results = append(results, copyValue(obj).(map[string]interface{}))
+	}
+	return results
+}
+
+func (s *Server) sortedObjects(className string) []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0, len(s.objects[className]))
+	for _, obj := range s.objects[className] {
+		objects = append(objects, obj)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i]["objectId"].(string) < objects[j]["objectId"].(string)
+	})
+	return objects
+}
+
+func (s *Server) serveClasses(r *request, path []string) (int, interface{}, error) {
+	if len(path) == 0 {
+		return 0, nil, errorf(parse.ErrInvalidClassName, "missing class name")
+	}
+	if path[0] == "_User" {
+		return s.serveUsers(r, path[1:])
+	}
+	if path[0] == "_Installation" {
+		return s.serveInstallations(r, path[1:])
+	}
+	return s.serveClassPath(r, path[0], path[1:])
+}
+
+// serveClassPath serves the /1/classes/<className> and /1/classes/<className>/<id>
+// endpoints and their aliases.
+func (s *Server) serveClassPath(r *request, className string, path []string) (int, interface{}, error) {
+	switch {
+	case len(path) == 0 && r.Method == "GET":
+		result, err := s.query(r, className)
+		return http.StatusOK, result, err
+	case len(path) == 0 && r.Method == "POST":
+		if className == "_Session" && !r.master {
+			return 0, nil, errorf(parse.ErrOperationForbidden, "sessions are created by signing up or logging in")
+		}
+		var body map[string]interface{}
+		if err := decodeBody(r, &body); err != nil {
+			return 0, nil, err
+		}
+		obj, err := s.create(className, body)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusCreated, s.created(className, obj, nil), nil
+	case len(path) == 1:
+		obj, err := s.find(r, className, path[0])
+		if err != nil {
+			return 0, nil, err
+		}
+		switch r.Method {
+		case "GET":
+			return http.StatusOK, s.output(className, obj), nil
+		case "PUT":
+			if !s.allowed(r, obj, "write") {
+				return 0, nil, errorf(parse.ErrObjectNotFound, "Object not found.")
+			}
+			var body map[string]interface{}
+			if err := decodeBody(r, &body); err != nil {
+				return 0, nil, err
+			}
+			if err := s.update(className, obj, body); err != nil {
+				return 0, nil, err
+			}
+			return http.StatusOK, map[string]interface{}{"updatedAt": obj["updatedAt"]}, nil
+		case "DELETE":
+			if !s.allowed(r, obj, "write") {
+				return 0, nil, errorf(parse.ErrObjectNotFound, "Object not found.")
+			}
+			delete(s.objects[className], path[0])
+			return http.StatusOK, map[string]interface{}{}, nil
+		}
+	}
+	return 0, nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+}
+
+// created describes the creation of obj in className to the client, adding extra to
+// the response body.
+func (s *Server) created(className string, obj map[string]interface{}, extra map[string]interface{}) created {
+	body := map[string]interface{}{
+		"objectId":  obj["objectId"],
+		"createdAt": obj["createdAt"],
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	return created{
+		location: fmt.Sprintf("%s/1/classes/%s/%s", s.URL, className, obj["objectId"]),
+		body:     body,
+	}
+}
+
+// find returns the object className/objectID if the caller may read it.
+func (s *Server) find(r *request, className, objectID string) (map[string]interface{}, error) {
+	obj, ok := s.objects[className][objectID]
+	if !ok || !s.allowed(r, obj, "read") {
+		return nil, errorf(parse.ErrObjectNotFound, "Object not found.")
+	}
+	return obj, nil
+}
+
+// create stores a new object in className built from body.
+func (s *Server) create(className string, body map[string]interface{}) (map[string]interface{}, error) {
+	if className == "" || strings.ContainsAny(className, "/ ") {
+		return nil, errorf(parse.ErrInvalidClassName, "invalid class name: %s", className)
+	}
+	for _, reserved := range []string{"objectId", "createdAt", "updatedAt"} {
+		if _, ok := body[reserved]; ok {
+			return nil, errorf(parse.ErrInvalidKeyName, "%s is an invalid field name.", reserved)
+		}
+	}
+	if className == "_Role" {
+		if name, ok := body["name"].(string); !ok || name == "" {
+			return nil, errorf(parse.ErrInvalidRoleName, "A role's name must be set.")
+		}
+	}
+	now := timestamp(s.Now())
+	obj := map[string]interface{}{
+		"objectId":  s.newID(),
+		"createdAt": now,
+		"updatedAt": now,
+	}
+	if err := s.apply(className, obj, body); err != nil {
+		return nil, err
+	}
+	if _, ok := s.objects[className]; !ok {
+		s.addClass(className, nil)
+	}
+	s.objects[className][obj["objectId"].(string)] = obj
+	return obj, nil
+}
+
+// update applies body to obj.
+func (s *Server) update(className string, obj, body map[string]interface{}) error {
+	for _, reserved := range []string{"objectId", "createdAt", "updatedAt"} {
+		delete(body, reserved)
+	}
+	updated := copyValue(obj).(map[string]interface{})
+	if err := s.apply(className, updated, body); err != nil {
+		return err
+	}
+	updated["updatedAt"] = timestamp(s.Now())
+	for k := range obj {
+		delete(obj, k)
+	}
+	for k, v := range updated {
+		obj[k] = v
+	}
+	return nil
+}
+
+// apply sets the fields in body, which may be field operations, on obj and records new
+// fields in the schema of className.
+func (s *Server) apply(className string, obj, body map[string]interface{}) error {
+	schema := s.schemas[className]
+	newFields := map[string]parse.SchemaField{}
+	for name, value := range body {
+		if name == "" || strings.HasPrefix(name, "_") || strings.ContainsAny(name, ". $") {
+			return errorf(parse.ErrInvalidKeyName, "invalid field name: %s", name)
+		}
+		op, isOp := value.(map[string]interface{})
+		if isOp && op["__op"] != nil {
+			var err error
+			if value, err = applyOp(obj[name], op); err != nil {
+				return err
+			}
+			if value == nil {
+				delete(obj, name)
+				continue
+			}
+		}
+		fieldType := typeOf(value, op)
+		if name == "ACL" {
+			if _, ok := value.(map[string]interface{}); !ok {
+				return errorf(parse.ErrInvalidACL, "invalid ACL")
+			}
+			fieldType = parse.SchemaField{Type: "ACL"}
+		}
+		if schema != nil {
+			if existing, ok := schema.Fields[name]; ok {
+				if fieldType.Type != "" && !compatible(existing, fieldType) {
+					return errorf(parse.ErrIncorrectType, "schema mismatch for %s.%s; expected %s but got %s",
+						className, name, existing.Type, fieldType.Type)
+				}
+				obj[name] = value
+				continue
+			}
+		}
+		if fieldType.Type != "" {
+			newFields[name] = fieldType
+		}
+		obj[name] = value
+	}
+	if len(newFields) > 0 {
+		s.addClass(className, newFields)
+	}
+	return nil
+}
+
+func compatible(field, value parse.SchemaField) bool {
+	if field.Type == "Relation" && value.Type == "Array" {
+		return true
+	}
+	return field.Type == value.Type && (value.TargetClass == "" || field.TargetClass == value.TargetClass)
+}
+
+// applyOp returns the result of applying a field operation such as Increment to current.
+// A nil result deletes the field.
+func applyOp(current interface{}, op map[string]interface{}) (interface{}, error) {
+	objects, _ := op["objects"].([]interface{})
+	existing, _ := current.([]interface{})
+	switch op["__op"] {
+	case "Delete":
+		return nil, nil
+	case "Increment":
+		amount, ok := op["amount"].(float64)
+		if !ok {
+			return nil, errorf(parse.ErrInvalidJSON, "Increment requires a numeric amount")
+		}
+		n, _ := current.(float64)
+		return n + amount, nil
+	case "Add":
+		return append(existing, objects...), nil
+	case "AddUnique", "AddRelation":
+		result := existing
+		for _, o := range objects {
+			if !containsValue(result, o) {
+				result = append(result, o)
+			}
+		}
+		if result == nil {
+			result = []interface{}{}
+		}
+		return result, nil
+	case "Remove", "RemoveRelation":
+		result := []interface{}{}
+		for _, e := range existing {
+			if !containsValue(objects, e) {
+				result = append(result, e)
+			}
+		}
+		return result, nil
+	}
+	return nil, errorf(parse.ErrInvalidJSON, "unknown operation %v", op["__op"])
+}
+
+// typeOf returns the schema type of a field value, or of the field an operation applies
+// to. It returns an empty type for nil values.
+func typeOf(value interface{}, op map[string]interface{}) parse.SchemaField {
+	if op != nil {
+		switch op["__op"] {
+		case "AddRelation", "RemoveRelation":
+			objects, _ := op["objects"].([]interface{})
+			if len(objects) > 0 {
+				if ptr, ok := objects[0].(map[string]interface{}); ok {
+					className, _ := ptr["className"].(string)
+					return parse.SchemaField{Type: "Relation", TargetClass: className}
+				}
+			}
+			return parse.SchemaField{Type: "Relation"}
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return parse.SchemaField{Type: "String"}
+	case float64:
+		return parse.SchemaField{Type: "Number"}
+	case bool:
+		return parse.SchemaField{Type: "Boolean"}
+	case []interface{}:
+		return parse.SchemaField{Type: "Array"}
+	case map[string]interface{}:
+		switch v["__type"] {
+		case "Pointer":
+			className, _ := v["className"].(string)
+			return parse.SchemaField{Type: "Pointer", TargetClass: className}
+		case "Date", "File", "GeoPoint", "Bytes", "Polygon":
+			return parse.SchemaField{Type: v["__type"].(string)}
+		}
+		return parse.SchemaField{Type: "Object"}
+	}
+	return parse.SchemaField{}
+}
+
+// output returns the representation of obj sent to clients.
+func (s *Server) output(className string, obj map[string]interface{}) map[string]interface{} {
+	result := copyValue(obj).(map[string]interface{})
+	for name, field := range s.schemas[className].Fields {
+		if field.Type == "Relation" {
+			result[name] = map[string]interface{}{"__type": "Relation", "className": field.TargetClass}
+		}
+	}
+	return result
+}
+
+// query evaluates a query on className described by the request parameters.
+func (s *Server) query(r *request, className string) (map[string]interface{}, error) {
+	params := r.URL.Query()
+	var where map[string]interface{}
+	if w := params.Get("where"); w != "" {
+		if err := json.Unmarshal([]byte(w), &where); err != nil {
+			return nil, errorf(parse.ErrInvalidJSON, "invalid where clause: %v", err)
+		}
+	}
+	limit, skip := 100, 0
+	if l := params.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 {
+			return nil, errorf(parse.ErrInvalidQuery, "invalid limit: %s", l)
+		}
+		limit = n
+	}
+	if sk := params.Get("skip"); sk != "" {
+		n, err := strconv.Atoi(sk)
+		if err != nil || n < 0 {
+			return nil, errorf(parse.ErrInvalidQuery, "invalid skip: %s", sk)
+		}
+		skip = n
+	}
+
+	matches, err := s.match(r, className, where)
+	if err != nil {
+		return nil, err
+	}
+	if order := params.Get("order"); order != "" {
+		sortObjects(matches, strings.Split(order, ","))
+	} else {
+		sortByDistance(matches, where)
+	}
+	result := map[string]interface{}{}
+	if params.Get("count") == "1" {
+		result["count"] = len(matches)
+	}
+	results := []interface{}{}
+	for i := skip; i < len(matches) && i < skip+limit; i++ {
+		results = append(results, s.output(className, matches[i]))
+	}
+	result["results"] = results
+	return result, nil
+}
+
+// match returns the objects of className readable by r matching where, in creation order.
+func (s *Server) match(r *request, className string, where map[string]interface{}) ([]map[string]interface{}, error) {
+	var matches []map[string]interface{}
+	for _, obj := range s.sortedObjects(className) {
+		if !s.allowed(r, obj, "read") {
+			continue
+		}
+		ok, err := s.matches(r, obj, where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, obj)
+		}
+	}
+	return matches, nil
+}
+
+// allowed reports whether the caller of r has the perm ('read' or 'write') permission
+// on obj.
+func (s *Server) allowed(r *request, obj map[string]interface{}, perm string) bool {
+	if r.master {
+		return true
+	}
+	acl, ok := obj["ACL"].(map[string]interface{})
+	if !ok {
+		return true
+	}
+	grants := func(key string) bool {
+		entry, _ := acl[key].(map[string]interface{})
+		return entry[perm] == true
+	}
+	if grants("*") {
+		return true
+	}
+	if r.user == nil {
+		return false
+	}
+	userID := r.user["objectId"].(string)
+	if grants(userID) {
+		return true
+	}
+	for _, role := range s.userRoles(userID) {
+		if grants("role:" + role) {
+			return true
+		}
+	}
+	return false
+}
+
+// userRoles returns the names of the roles the user belongs to, directly or through the
+// roles of its roles.
+func (s *Server) userRoles(userID string) []string {
+	member := map[string]bool{}
+	var names []string
+	for changed := true; changed; {
+		changed = false
+		for id, role := range s.objects["_Role"] {
+			if member[id] {
+				continue
+			}
+			users, _ := role["users"].([]interface{})
+			isMember := containsPointer(users, "_User", userID)
+			for inheriting := range member {
+				roles, _ := role["roles"].([]interface{})
+				isMember = isMember || containsPointer(roles, "_Role", inheriting)
+			}
+			if isMember {
+				member[id] = true
+				name, _ := role["name"].(string)
+				names = append(names, name)
+				changed = true
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsPointer(values []interface{}, className, objectID string) bool {
+	for _, v := range values {
+		ptr, ok := v.(map[string]interface{})
+		if ok && ptr["className"] == className && ptr["objectId"] == objectID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package parsetest
+
+import "github.com/tmc/parse"
+
+var installationFields = map[string]parse.SchemaField{
+	"installationId":   {Type: "String"},
+	"deviceToken":      {Type: "String"},
+	"deviceType":       {Type: "String"},
+	"channels":         {Type: "Array"},
+	"badge":            {Type: "Number"},
+	"timeZone":         {Type: "String"},
+	"appName":          {Type: "String"},
+	"appVersion":       {Type: "String"},
+	"appIdentifier":    {Type: "String"},
+	"parseVersion":     {Type: "String"},
+	"localeIdentifier": {Type: "String"},
+}
+
+// Push is a push notification sent through a Server.
+type Push struct {
+	// StatusID is the ID of the _PushStatus object created for the notification.
+	StatusID string
+	// Body is the body of the /1/push request, such as {"channels": [...], "data": {...}}.
+	Body map[string]interface{}
+	// Installations are the IDs of the installations the notification was sent to, in
+	// creation order.
+	Installations []string
+}
+
+// Pushes returns the push notifications sent through the server, in the order they were
+// sent. Notifications are not delivered anywhere.
+func (s *Server) Pushes() []*Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pushes := make([]*Push, len(s.pushes))
+	for i, p := range s.pushes {
+		pushes[i] = &Push{
+			StatusID:      p.StatusID,
+			Body:          copyValue(p.Body).(map[string]interface{}),
+			Installations: append([]string(nil), p.Installations...),
+		}
+	}
+	return pushes
+}
+
+// sendPush serves /1/push: it records the notification and a _PushStatus object for it.
+func (s *Server) sendPush(r *request) (interface{}, error) {
+	if !r.master {
+		return nil, errorf(parse.ErrOperationForbidden, "unauthorized: master key is required")
+	}
+	var body map[string]interface{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	data, ok := body["data"].(map[string]interface{})
+	if !ok {
+		return nil, errorf(parse.ErrPushMisconfigured, "Sending a push requires a data object.")
+	}
+	_, hasChannels := body["channels"]
+	where, hasWhere := body["where"]
+	switch {
+	case hasChannels && hasWhere:
+		return nil, errorf(parse.ErrPushMisconfigured, "Channels and query can not be set at the same time.")
+	case hasChannels:
+		channels, ok := body["channels"].([]interface{})
+		if !ok {
+			return nil, errorf(parse.ErrInvalidChannelName, "channels must be an array")
+		}
+		where = map[string]interface{}{"channels": map[string]interface{}{"$in": channels}}
+	case !hasWhere:
+		return nil, errorf(parse.ErrPushMisconfigured, "Sending a push requires either \"channels\" or a \"where\" query.")
+	}
+	query, ok := where.(map[string]interface{})
+	if !ok {
+		return nil, errorf(parse.ErrInvalidQuery, "where must be an object")
+	}
+	installations, err := s.match(r, "_Installation", query)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "succeeded"
+	if _, ok := body["push_time"]; ok {
+		status = "scheduled"
+	}
+	pushStatus, err := s.create("_PushStatus", map[string]interface{}{
+		"source":  "rest",
+		"payload": data,
+		"query":   query,
+		"status":  status,
+		"numSent": len(installations),
+	})
+	if err != nil {
+		return nil, err
+	}
+	push := &Push{
+		StatusID: pushStatus["objectId"].(string),
+		Body:     copyValue(body).(map[string]interface{}),
+	}
+	for _, installation := range installations {
+		push.Installations = append(push.Installations, installation["objectId"].(string))
+	}
+	s.pushes = append(s.pushes, push)
+	return withHeader{name: "X-Parse-Push-Status-Id", value: push.StatusID, body: map[string]interface{}{"result": true}}, nil
+}
+
+// serveInstallations serves /1/installations, where only the master key can query
+// installations.
+func (s *Server) serveInstallations(r *request, path []string) (int, interface{}, error) {
+	if len(path) == 0 && r.Method == "GET" && !r.master {
+		return 0, nil, errorf(parse.ErrOperationForbidden, "unauthorized: master key is required")
+	}
+	return s.serveClassPath(r, "_Installation", path)
+}
+
+// withHeader is the result of a request whose response carries a header.
+type withHeader struct {
+	name, value string
+	body        interface{}
+}
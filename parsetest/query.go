@@ -0,0 +1,323 @@
+package parsetest
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tmc/parse"
+)
+
+// matches reports whether obj satisfies the where clause.
+func (s *Server) matches(r *request, obj, where map[string]interface{}) (bool, error) {
+	for key, cond := range where {
+		var (
+			ok  bool
+			err error
+		)
+		switch key {
+		case "$or", "$and", "$nor":
+			ok, err = s.matchClauses(r, obj, key, cond)
+		case "$relatedTo":
+			ok, err = s.relatedTo(obj, cond)
+		default:
+			ok, err = s.matchField(r, obj[key], cond)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (s *Server) matchClauses(r *request, obj map[string]interface{}, op string, cond interface{}) (bool, error) {
+	clauses, ok := cond.([]interface{})
+	if !ok {
+		return false, errorf(parse.ErrInvalidQuery, "%s requires an array of clauses", op)
+	}
+	matched := 0
+	for _, c := range clauses {
+		clause, ok := c.(map[string]interface{})
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "%s clauses must be objects", op)
+		}
+		m, err := s.matches(r, obj, clause)
+		if err != nil {
+			return false, err
+		}
+		if m {
+			matched++
+		}
+	}
+	switch op {
+	case "$or":
+		return matched > 0, nil
+	case "$and":
+		return matched == len(clauses), nil
+	}
+	return matched == 0, nil
+}
+
+// relatedTo evaluates a $relatedTo constraint: obj must be in the relation 'key' of
+// 'object'.
+func (s *Server) relatedTo(obj map[string]interface{}, cond interface{}) (bool, error) {
+	c, _ := cond.(map[string]interface{})
+	ptr, _ := c["object"].(map[string]interface{})
+	key, _ := c["key"].(string)
+	className, _ := ptr["className"].(string)
+	objectID, _ := ptr["objectId"].(string)
+	if key == "" || className == "" {
+		return false, errorf(parse.ErrInvalidQuery, "$relatedTo requires an object and a key")
+	}
+	owner, ok := s.objects[className][objectID]
+	if !ok {
+		return false, nil
+	}
+	related, _ := owner[key].([]interface{})
+	for _, v := range related {
+		p, ok := v.(map[string]interface{})
+		if ok && p["objectId"] == obj["objectId"] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchField reports whether a field value satisfies a constraint, which is either a
+// value the field must equal (or, for arrays, contain) or a map of operators.
+func (s *Server) matchField(r *request, value, cond interface{}) (bool, error) {
+	ops, ok := cond.(map[string]interface{})
+	if !ok || !isOperatorMap(ops) {
+		return equalValues(value, cond) || containsValue(asArray(value), cond), nil
+	}
+	for op, arg := range ops {
+		ok, err := s.matchOperator(r, value, op, arg, ops)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func isOperatorMap(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) matchOperator(r *request, value interface{}, op string, arg interface{}, ops map[string]interface{}) (bool, error) {
+	switch op {
+	case "$eq":
+		return equalValues(value, arg) || containsValue(asArray(value), arg), nil
+	case "$ne":
+		return !equalValues(value, arg) && !containsValue(asArray(value), arg), nil
+	case "$lt", "$lte", "$gt", "$gte":
+		c, ok := compareValues(value, arg)
+		if !ok {
+			return false, nil
+		}
+		switch op {
+		case "$lt":
+			return c < 0, nil
+		case "$lte":
+			return c <= 0, nil
+		case "$gt":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "$in", "$nin":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "%s requires an array", op)
+		}
+		in := containsValue(list, value)
+		for _, v := range asArray(value) {
+			in = in || containsValue(list, v)
+		}
+		return in == (op == "$in"), nil
+	case "$all":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "$all requires an array")
+		}
+		for _, v := range list {
+			if !containsValue(asArray(value), v) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$containedBy":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "$containedBy requires an array")
+		}
+		for _, v := range asArray(value) {
+			if !containsValue(list, v) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$exists":
+		exists, ok := arg.(bool)
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "$exists requires a boolean")
+		}
+		return (value != nil) == exists, nil
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return false, errorf(parse.ErrInvalidQuery, "$regex requires a string")
+		}
+		if options, _ := ops["$options"].(string); options != "" {
+			pattern = "(?" + strings.Replace(options, "x", "", -1) + ")" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, errorf(parse.ErrInvalidQuery, "invalid $regex: %v", err)
+		}
+		str, ok := value.(string)
+		return ok && re.MatchString(str), nil
+	case "$options":
+		return true, nil
+	case "$nearSphere", "$maxDistance", "$maxDistanceInRadians", "$maxDistanceInKilometers", "$maxDistanceInMiles",
+		"$within", "$geoWithin":
+		return matchGeo(value, op, arg, ops)
+	case "$inQuery", "$notInQuery":
+		ids, err := s.subquery(r, arg, "objectId")
+		if err != nil {
+			return false, err
+		}
+		ptr, _ := value.(map[string]interface{})
+		in := ptr != nil && containsValue(ids, ptr["objectId"])
+		return in == (op == "$inQuery"), nil
+	case "$select", "$dontSelect":
+		sel, _ := arg.(map[string]interface{})
+		key, _ := sel["key"].(string)
+		if key == "" {
+			return false, errorf(parse.ErrInvalidQuery, "%s requires a key", op)
+		}
+		values, err := s.subquery(r, sel["query"], key)
+		if err != nil {
+			return false, err
+		}
+		return containsValue(values, value) == (op == "$select"), nil
+	}
+	return false, errorf(parse.ErrInvalidQuery, "bad constraint: %s", op)
+}
+
+// subquery evaluates a {className, where} query and returns the key field of every
+// matching object.
+func (s *Server) subquery(r *request, arg interface{}, key string) ([]interface{}, error) {
+	q, _ := arg.(map[string]interface{})
+	className, _ := q["className"].(string)
+	if className == "" {
+		return nil, errorf(parse.ErrInvalidQuery, "subqueries require a className")
+	}
+	where, _ := q["where"].(map[string]interface{})
+	matches, err := s.match(r, className, where)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(matches))
+	for _, obj := range matches {
+		values = append(values, obj[key])
+	}
+	return values, nil
+}
+
+func asArray(v interface{}) []interface{} {
+	a, _ := v.([]interface{})
+	return a
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if equalValues(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize reduces Parse typed values to a comparable form: Dates to their ISO string
+// and Pointers to their class and ID.
+func normalize(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	switch m["__type"] {
+	case "Date":
+		return m["iso"]
+	case "Pointer":
+		return [2]interface{}{m["className"], m["objectId"]}
+	}
+	return v
+}
+
+func equalValues(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// compareValues orders two numbers or two strings (which includes dates).
+func compareValues(a, b interface{}) (int, bool) {
+	switch a := normalize(a).(type) {
+	case float64:
+		b, ok := normalize(b).(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := normalize(b).(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	}
+	return 0, false
+}
+
+// sortObjects sorts objects by a list of keys, each descending if prefixed with '-'.
+// Objects missing a key sort first.
+func sortObjects(objects []map[string]interface{}, keys []string) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		for _, key := range keys {
+			desc := strings.HasPrefix(key, "-")
+			key = strings.TrimPrefix(key, "-")
+			a, b := objects[i][key], objects[j][key]
+			var c int
+			switch {
+			case a == nil && b == nil:
+				continue
+			case a == nil:
+				c = -1
+			case b == nil:
+				c = 1
+			default:
+				c, _ = compareValues(a, b)
+			}
+			if c == 0 {
+				continue
+			}
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
@@ -0,0 +1,285 @@
+// Package parsetest provides an in-memory fake of the Parse REST API for hermetic tests.
+//
+// A Server implements the parts of the API parse_graphql relies on: objects and queries
+// (/1/classes), schemas, users, logins, sessions and account emails, Cloud Code functions,
+// jobs and webhooks, files, installations and push notifications. Where clauses, pointers,
+// ACLs and session tokens behave like they do on Parse, closely enough to exercise
+// ParseClass, ParseSchema and serve offline:
+//
+//	s := parsetest.NewServer()
+//	defer s.Close()
+//	s.AddObject("Post", map[string]interface{}{"title": "hello"})
+//	client := s.Client() // or parse.NewClient(s.AppID, s.RESTAPIKey)
+package parsetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/parse"
+)
+
+// Credentials accepted by servers created with NewServer.
+const (
+	AppID      = "parsetest-app-id"
+	RESTAPIKey = "parsetest-rest-api-key"
+	MasterKey  = "parsetest-master-key"
+)
+
+// Server is a fake Parse API server. While it runs, parse.BaseURL points at it.
+type Server struct {
+	*httptest.Server
+	AppID, RESTAPIKey, MasterKey string
+
+	// Now returns the time used for createdAt and updatedAt values.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	schemas   map[string]*parse.Schema
+	objects   map[string]map[string]map[string]interface{}
+	passwords map[string]string
+	functions map[string]Function
+	jobs      map[string]Function
+	hooks     []*parse.HookFunction
+	files     map[string]*file
+	pushes    []*Push
+	emails    []Email
+	nextID    int
+
+	previousBaseURL string
+}
+
+// NewServer starts a Server and points parse.BaseURL at it until it is closed.
+func NewServer() *Server {
+	s := &Server{
+		AppID:      AppID,
+		RESTAPIKey: RESTAPIKey,
+		MasterKey:  MasterKey,
+		Now:        time.Now,
+		schemas:    map[string]*parse.Schema{},
+		objects:    map[string]map[string]map[string]interface{}{},
+		passwords:  map[string]string{},
+		functions:  map[string]Function{},
+		jobs:       map[string]Function{},
+		files:      map[string]*file{},
+	}
+	s.addClass("_User", userFields)
+	s.addClass("_Role", roleFields)
+	s.addClass("_Session", sessionFields)
+	s.addClass("_Installation", installationFields)
+	s.Server = httptest.NewServer(s)
+	s.previousBaseURL = parse.BaseURL
+	parse.BaseURL = s.URL + "/"
+	return s
+}
+
+// Close shuts the server down and restores parse.BaseURL.
+func (s *Server) Close() {
+	s.Server.Close()
+	parse.BaseURL = s.previousBaseURL
+}
+
+// Client returns a client using the REST API key of the server.
+func (s *Server) Client() *parse.Client {
+	client, err := parse.NewClient(s.AppID, s.RESTAPIKey)
+	if err != nil {
+		panic(fmt.Sprintf("parsetest: %v", err))
+	}
+	return client
+}
+
+// request describes the caller of an API request.
+type request struct {
+	*http.Request
+	master bool
+	// user is the user owning the session token of the request, if any.
+	user map[string]interface{}
+}
+
+// ServeHTTP implements the Parse REST API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) > 0 && parts[0] == "files" {
+		s.serveFileContents(w, r, parts[1:])
+		return
+	}
+	if len(parts) < 2 || parts[0] != "1" {
+		writeError(w, http.StatusNotFound, parse.ErrInvalidJSON, "unknown endpoint")
+		return
+	}
+	if r.Header.Get("X-Parse-Application-ID") != s.AppID {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+	req := &request{Request: r}
+	switch {
+	case s.MasterKey != "" && r.Header.Get("X-Parse-Master-Key") == s.MasterKey:
+		req.master = true
+	case r.Header.Get("X-Parse-REST-API-Key") == s.RESTAPIKey:
+	default:
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+
+	s.mu.Lock()
+	if token := r.Header.Get("X-Parse-Session-Token"); token != "" {
+		if req.user = s.sessionUser(token); req.user == nil {
+			s.mu.Unlock()
+			writeError(w, http.StatusBadRequest, parse.ErrInvalidSessionToken, "invalid session token")
+			return
+		}
+	}
+
+	var (
+		status = http.StatusOK
+		result interface{}
+		err    error
+	)
+	if parts[1] == "functions" || parts[1] == "jobs" {
+		// functions and jobs may call back into the server, so run them unlocked
+		if req.user != nil {
+			req.user = s.output("_User", req.user)
+		}
+		s.mu.Unlock()
+		if parts[1] == "jobs" {
+			result, err = s.runJob(req, parts[2:])
+		} else {
+			result, err = s.callFunction(req, parts[2:])
+		}
+	} else {
+		status, result, err = s.serve(req, parts[1], parts[2:])
+		s.mu.Unlock()
+	}
+	if err != nil {
+		writeParseError(w, err)
+		return
+	}
+	if location, ok := result.(created); ok {
+		w.Header().Set("Location", location.location)
+		result = location.body
+	}
+	if h, ok := result.(withHeader); ok {
+		w.Header().Set(h.name, h.value)
+		result = h.body
+	}
+	writeJSON(w, status, result)
+}
+
+// serve serves the endpoint of a request with the server locked.
+func (s *Server) serve(r *request, endpoint string, path []string) (int, interface{}, error) {
+	switch endpoint {
+	case "classes":
+		return s.serveClasses(r, path)
+	case "users":
+		return s.serveUsers(r, path)
+	case "login":
+		result, err := s.logIn(r)
+		return http.StatusOK, result, err
+	case "logout":
+		result, err := s.logOut(r)
+		return http.StatusOK, result, err
+	case "requestPasswordReset":
+		result, err := s.requestEmail(r, "passwordReset")
+		return http.StatusOK, result, err
+	case "verificationEmailRequest":
+		result, err := s.requestEmail(r, "verification")
+		return http.StatusOK, result, err
+	case "sessions":
+		return s.serveSessions(r, path)
+	case "roles":
+		return s.serveClassPath(r, "_Role", path)
+	case "installations":
+		return s.serveInstallations(r, path)
+	case "push":
+		if len(path) != 0 || r.Method != "POST" {
+			break
+		}
+		result, err := s.sendPush(r)
+		return http.StatusOK, result, err
+	case "schemas":
+		result, err := s.serveSchemas(r, path)
+		return http.StatusOK, result, err
+	case "hooks":
+		return s.serveHooks(r, path)
+	case "files":
+		return s.serveFiles(r, path)
+	}
+	return 0, nil, errorf(parse.ErrInvalidJSON, "unknown endpoint")
+}
+
+// created is the result of a request creating a resource at location.
+type created struct {
+	location string
+	body     interface{}
+}
+
+// errorf returns a Parse error with the given code.
+func errorf(code int, format string, args ...interface{}) error {
+	return &parse.Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func writeParseError(w http.ResponseWriter, err error) {
+	e, ok := err.(*parse.Error)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, parse.ErrInternalServer, err.Error())
+		return
+	}
+	status := http.StatusBadRequest
+	if e.Code == parse.ErrObjectNotFound {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, e.Code, e.Message)
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]interface{}{"code": code, "error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeBody decodes the JSON body of r into v.
+func decodeBody(r *request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errorf(parse.ErrInvalidJSON, "invalid JSON: %v", err)
+	}
+	return nil
+}
+
+// timestamp formats t the way Parse formats createdAt and updatedAt.
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// newID returns a fresh object ID. IDs increase, so objects sort in creation order.
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("o%09d", s.nextID)
+}
+
+// copyValue returns a deep copy of a decoded JSON value.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = copyValue(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = copyValue(e)
+		}
+		return a
+	}
+	return v
+}
@@ -0,0 +1,354 @@
+package parsetest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tmc/parse"
+)
+
+// sessionLifetime is how long session tokens stay valid.
+const sessionLifetime = 365 * 24 * time.Hour
+
+func (s *Server) serveUsers(r *request, path []string) (int, interface{}, error) {
+	switch {
+	case len(path) == 0 && r.Method == "POST":
+		var body map[string]interface{}
+		if err := decodeBody(r, &body); err != nil {
+			return 0, nil, err
+		}
+		if _, ok := body["authData"]; ok && body["password"] == nil {
+			return s.logInWith(body)
+		}
+		user, err := s.signUp(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusCreated, s.created("_User", user, map[string]interface{}{
+			"sessionToken": user["sessionToken"],
+		}), nil
+	case len(path) == 0:
+		return s.serveClassPath(r, "_User", path)
+	case path[0] == "me" && r.Method == "GET":
+		if r.user == nil {
+			return 0, nil, errorf(parse.ErrInvalidSessionToken, "invalid session token")
+		}
+		user := s.output("_User", r.user)
+		user["sessionToken"] = r.Header.Get("X-Parse-Session-Token")
+		return http.StatusOK, user, nil
+	case len(path) == 1 && (r.Method == "PUT" || r.Method == "DELETE"):
+		if !r.master && (r.user == nil || r.user["objectId"] != path[0]) {
+			return 0, nil, errorf(parse.ErrUserCannotBeAlteredWithoutSession, "Cannot modify user %s.", path[0])
+		}
+		if r.Method == "DELETE" {
+			s.deleteSessions(path[0])
+			delete(s.passwords, path[0])
+			return s.serveClassPath(r, "_User", path)
+		}
+		return s.updateUser(r, path[0])
+	}
+	return s.serveClassPath(r, "_User", path)
+}
+
+// signUp creates a user and a session for it. The returned object includes the session
+// token.
+func (s *Server) signUp(body map[string]interface{}) (map[string]interface{}, error) {
+	username, _ := body["username"].(string)
+	password, _ := body["password"].(string)
+	if username == "" {
+		return nil, errorf(parse.ErrUsernameMissing, "bad or missing username")
+	}
+	if password == "" && body["authData"] == nil {
+		return nil, errorf(parse.ErrUserPasswordMissing, "password is required")
+	}
+	if err := s.checkUserFields("", body); err != nil {
+		return nil, err
+	}
+	if err := s.checkAuthData("", body); err != nil {
+		return nil, err
+	}
+	delete(body, "password")
+	user, err := s.create("_User", body)
+	if err != nil {
+		return nil, err
+	}
+	userID := user["objectId"].(string)
+	user["ACL"] = map[string]interface{}{
+		"*":    map[string]interface{}{"read": true},
+		userID: map[string]interface{}{"read": true, "write": true},
+	}
+	if password != "" {
+		s.passwords[userID] = password
+	}
+	result := copyValue(user).(map[string]interface{})
+	result["sessionToken"] = s.createSession(userID, "signup")
+	return result, nil
+}
+
+// checkUserFields validates the username and email in body for the user userID.
+func (s *Server) checkUserFields(userID string, body map[string]interface{}) error {
+	username, hasUsername := body["username"].(string)
+	email, hasEmail := body["email"].(string)
+	if hasEmail && !strings.Contains(email, "@") {
+		return errorf(parse.ErrInvalidEmailAddress, "invalid email address")
+	}
+	for id, user := range s.objects["_User"] {
+		if id == userID {
+			continue
+		}
+		if hasUsername && user["username"] == username {
+			return errorf(parse.ErrUsernameTaken, "Account already exists for this username.")
+		}
+		if hasEmail && user["email"] == email {
+			return errorf(parse.ErrUserEmailTaken, "Account already exists for this email address.")
+		}
+	}
+	return nil
+}
+
+func (s *Server) updateUser(r *request, userID string) (int, interface{}, error) {
+	user, ok := s.objects["_User"][userID]
+	if !ok {
+		return 0, nil, errorf(parse.ErrObjectNotFound, "Object not found.")
+	}
+	var body map[string]interface{}
+	if err := decodeBody(r, &body); err != nil {
+		return 0, nil, err
+	}
+	if err := s.checkUserFields(userID, body); err != nil {
+		return 0, nil, err
+	}
+	if err := s.checkAuthData(userID, body); err != nil {
+		return 0, nil, err
+	}
+	if authData, ok := body["authData"].(map[string]interface{}); ok {
+		// authData updates merge into the existing providers; null unlinks one
+		merged, _ := copyValue(user["authData"]).(map[string]interface{})
+		if merged == nil {
+			merged = map[string]interface{}{}
+		}
+		for provider, data := range authData {
+			if data == nil {
+				delete(merged, provider)
+			} else {
+				merged[provider] = data
+			}
+		}
+		body["authData"] = merged
+	}
+	if password, ok := body["password"].(string); ok {
+		s.passwords[userID] = password
+		delete(body, "password")
+	}
+	if err := s.update("_User", user, body); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, map[string]interface{}{"updatedAt": user["updatedAt"]}, nil
+}
+
+// logInWith serves a sign up request carrying authData: it logs in the user linked to one
+// of the accounts in authData, or signs up a new user with a random username.
+func (s *Server) logInWith(body map[string]interface{}) (int, interface{}, error) {
+	authData, _ := body["authData"].(map[string]interface{})
+	if user := s.linkedUser(authData); user != nil {
+		userID := user["objectId"].(string)
+		result := s.output("_User", user)
+		result["sessionToken"] = s.createSession(userID, "login")
+		return http.StatusOK, result, nil
+	}
+	if _, ok := body["username"]; !ok {
+		b := make([]byte, 12)
+		rand.Read(b)
+		body["username"] = hex.EncodeToString(b)
+	}
+	user, err := s.signUp(body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, s.created("_User", user, map[string]interface{}{
+		"username":     user["username"],
+		"sessionToken": user["sessionToken"],
+	}), nil
+}
+
+// checkAuthData validates the authData in body for the user userID: every provider needs
+// an 'id', and an account cannot be linked to two users.
+func (s *Server) checkAuthData(userID string, body map[string]interface{}) error {
+	value, ok := body["authData"]
+	if !ok {
+		return nil
+	}
+	authData, ok := value.(map[string]interface{})
+	if !ok || (userID == "" && len(authData) == 0) {
+		return errorf(parse.ErrInvalidJSON, "authData must be an object")
+	}
+	for provider, data := range authData {
+		if data == nil {
+			continue
+		}
+		account, _ := data.(map[string]interface{})
+		if id, _ := account["id"].(string); id == "" {
+			return errorf(parse.ErrLinkedIDMissing, "%s authData requires an id", provider)
+		}
+		linked := s.linkedUser(map[string]interface{}{provider: data})
+		if linked != nil && linked["objectId"] != userID {
+			return errorf(parse.ErrAccountAlreadyLinked, "this auth is already used")
+		}
+	}
+	return nil
+}
+
+// linkedUser returns the user linked to one of the accounts in authData, or nil.
+func (s *Server) linkedUser(authData map[string]interface{}) map[string]interface{} {
+	for _, user := range s.sortedObjects("_User") {
+		linked, _ := user["authData"].(map[string]interface{})
+		for provider, data := range authData {
+			account, _ := data.(map[string]interface{})
+			existing, _ := linked[provider].(map[string]interface{})
+			if id, _ := account["id"].(string); id != "" && existing["id"] == id {
+				return user
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) logIn(r *request) (interface{}, error) {
+	params := r.URL.Query()
+	username, password := params.Get("username"), params.Get("password")
+	if r.Method == "POST" {
+		var body struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			return nil, err
+		}
+		username, password = body.Username, body.Password
+	}
+	for id, user := range s.objects["_User"] {
+		if user["username"] == username && s.passwords[id] == password && password != "" {
+			result := s.output("_User", user)
+			result["sessionToken"] = s.createSession(id, "login")
+			return result, nil
+		}
+	}
+	return nil, errorf(parse.ErrObjectNotFound, "Invalid username/password.")
+}
+
+func (s *Server) logOut(r *request) (interface{}, error) {
+	token := r.Header.Get("X-Parse-Session-Token")
+	if r.Method != "POST" || r.user == nil {
+		return nil, errorf(parse.ErrInvalidSessionToken, "invalid session token")
+	}
+	for id, session := range s.objects["_Session"] {
+		if session["sessionToken"] == token {
+			delete(s.objects["_Session"], id)
+		}
+	}
+	return map[string]interface{}{}, nil
+}
+
+// serveSessions serves /1/sessions, where /1/sessions/me is the session of the session
+// token of the request.
+func (s *Server) serveSessions(r *request, path []string) (int, interface{}, error) {
+	if len(path) == 1 && path[0] == "me" && r.Method == "GET" {
+		token := r.Header.Get("X-Parse-Session-Token")
+		for _, session := range s.objects["_Session"] {
+			if token != "" && session["sessionToken"] == token {
+				return http.StatusOK, s.output("_Session", session), nil
+			}
+		}
+		return 0, nil, errorf(parse.ErrInvalidSessionToken, "invalid session token")
+	}
+	return s.serveClassPath(r, "_Session", path)
+}
+
+// createSession creates a session for the user and returns its token.
+func (s *Server) createSession(userID, action string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := "r:" + hex.EncodeToString(b)
+	session, _ := s.create("_Session", map[string]interface{}{
+		"sessionToken": token,
+		"user":         map[string]interface{}{"__type": "Pointer", "className": "_User", "objectId": userID},
+		"createdWith":  map[string]interface{}{"action": action},
+		"restricted":   false,
+		"expiresAt": map[string]interface{}{
+			"__type": "Date",
+			"iso":    timestamp(s.Now().Add(sessionLifetime)),
+		},
+		"installationId": "",
+	})
+	session["ACL"] = map[string]interface{}{
+		userID: map[string]interface{}{"read": true, "write": true},
+	}
+	return token
+}
+
+// sessionUser returns the user owning a valid session token, or nil.
+func (s *Server) sessionUser(token string) map[string]interface{} {
+	now := timestamp(s.Now())
+	for _, session := range s.objects["_Session"] {
+		if session["sessionToken"] != token {
+			continue
+		}
+		if expiresAt, ok := normalize(session["expiresAt"]).(string); ok && expiresAt < now {
+			return nil
+		}
+		ptr, _ := session["user"].(map[string]interface{})
+		userID, _ := ptr["objectId"].(string)
+		return s.objects["_User"][userID]
+	}
+	return nil
+}
+
+// deleteSessions removes the sessions of a user.
+func (s *Server) deleteSessions(userID string) {
+	for id, session := range s.objects["_Session"] {
+		if ptr, _ := session["user"].(map[string]interface{}); ptr["objectId"] == userID {
+			delete(s.objects["_Session"], id)
+		}
+	}
+}
+
+// Email is an email a Server was asked to send.
+type Email struct {
+	// Kind is "passwordReset" or "verification".
+	Kind string
+	To   string
+}
+
+// Emails returns the emails the server was asked to send, in order. Emails are not
+// delivered anywhere.
+func (s *Server) Emails() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Email(nil), s.emails...)
+}
+
+// requestEmail serves /1/requestPasswordReset and /1/verificationEmailRequest.
+func (s *Server) requestEmail(r *request, kind string) (interface{}, error) {
+	if r.Method != "POST" {
+		return nil, errorf(parse.ErrInvalidJSON, "unsupported request %s %s", r.Method, r.URL.Path)
+	}
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	if body.Email == "" {
+		return nil, errorf(parse.ErrUserEmailMissing, "you must provide an email")
+	}
+	for _, user := range s.objects["_User"] {
+		if user["email"] == body.Email {
+			s.emails = append(s.emails, Email{Kind: kind, To: body.Email})
+			return map[string]interface{}{}, nil
+		}
+	}
+	return nil, errorf(parse.ErrUserWithEmailNotFound, "No user found with email %s.", body.Email)
+}
@@ -0,0 +1,124 @@
+package parse_graphql_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse_graphql"
+	"golang.org/x/net/context"
+)
+
+// persisted returns the request body of query sent along with its persisted query hash.
+func persisted(query string) map[string]interface{} {
+	ext := map[string]interface{}{"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": handler.QueryHash(query)}}
+	return map[string]interface{}{"query": query, "extensions": ext}
+}
+
+func TestAutomaticPersistedQueries(t *testing.T) {
+	p := newParse(t)
+	p.AddObject("_Role", map[string]interface{}{"name": "Admin"})
+	store := handler.NewMemoryQueryStore()
+	e := newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) { h.Queries = store })
+
+	query := `{ _Role { name } }`
+	hashOnly := persisted(query)
+	delete(hashOnly, "query")
+	if r := e.postBody(t, nil, hashOnly); r.Error == nil || r.Error.Message != handler.ErrPersistedQueryNotFound.Error() {
+		t.Fatalf("unknown hash: got %v, want PersistedQueryNotFound", r.Error)
+	}
+	if r := e.postBody(t, nil, persisted(query)); len(r.objects(t, 0)) != 1 {
+		t.Fatalf("registering: got %v", r.Data)
+	}
+	if r := e.postBody(t, nil, hashOnly); len(r.objects(t, 0)) != 1 {
+		t.Fatalf("by hash: got %v (error %v)", r.Data, r.Error)
+	}
+
+	junk := `{ not graphql`
+	if r := e.postBody(t, nil, persisted(junk)); r.Error == nil {
+		t.Errorf("junk query: got %v, want an error", r.Data)
+	}
+	if _, ok := store.Get(handler.QueryHash(junk)); ok {
+		t.Error("junk query was registered")
+	}
+}
+
+// forgedAuthenticator rejects requests sending the Forged header.
+type forgedAuthenticator struct{}
+
+func (forgedAuthenticator) Authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	if r.Header.Get("Forged") != "" {
+		return nil, errors.New("forged request")
+	}
+	return ctx, nil
+}
+
+func TestPersistedQueriesRegisteredOnceAllowed(t *testing.T) {
+	store := handler.NewMemoryQueryStore()
+	e := newEndpoint(t, newParse(t), nil, func(h *handler.ExecutorHandler) {
+		h.Queries = store
+		h.Authenticator = forgedAuthenticator{}
+		h.Limiter = &parse_graphql.RateLimiter{Requests: parse_graphql.Rate{N: 1, Per: time.Hour}}
+	})
+
+	unauthenticated := `{ _RoleCount }`
+	if r := e.postBody(t, map[string]string{"Forged": "1"}, persisted(unauthenticated)); r.status != http.StatusUnauthorized {
+		t.Errorf("unauthenticated: got %d (%v), want 401", r.status, r.Error)
+	}
+	e.post(t, nil, `{ _RoleCount }`)
+	limited := `{ _RoleCount, _RoleCount }`
+	if r := e.postBody(t, nil, persisted(limited)); r.status != http.StatusTooManyRequests {
+		t.Errorf("rate limited: got %d, want 429", r.status)
+	}
+	for _, q := range []string{unauthenticated, limited} {
+		if _, ok := store.Get(handler.QueryHash(q)); ok {
+			t.Errorf("%s was registered by a rejected request", q)
+		}
+	}
+}
+
+func TestMemoryQueryStoreBounds(t *testing.T) {
+	s := handler.NewMemoryQueryStore()
+	s.MaxQueries, s.MaxQuerySize = 2, 32
+	for _, q := range []string{"{ a }", "{ b }"} {
+		if err := s.Put(handler.QueryHash(q), q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Get(handler.QueryHash("{ a }"))
+	if err := s.Put(handler.QueryHash("{ c }"), "{ c }"); err != nil {
+		t.Fatal(err)
+	}
+	for q, want := range map[string]bool{"{ a }": true, "{ b }": false, "{ c }": true} {
+		if _, ok := s.Get(handler.QueryHash(q)); ok != want {
+			t.Errorf("%s stored: %v, want %v", q, ok, want)
+		}
+	}
+	large := "{ " + string(make([]byte, 32)) + " }"
+	if err := s.Put(handler.QueryHash(large), large); err != handler.ErrPersistedQueryTooLarge {
+		t.Errorf("large query: got %v, want ErrPersistedQueryTooLarge", err)
+	}
+}
+
+func TestFileQueryStoreReadOnly(t *testing.T) {
+	s, err := handler.NewFileQueryStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := "{ a }"
+	if err := s.Put(handler.QueryHash(q), q); err != handler.ErrReadOnlyQueryStore {
+		t.Fatalf("got %v, want ErrReadOnlyQueryStore", err)
+	}
+	s.Writable, s.MaxQueries = true, 1
+	if err := s.Put(handler.QueryHash(q), q); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.Get(handler.QueryHash(q)); !ok || got != q {
+		t.Errorf("got %q, %v", got, ok)
+	}
+	if err := s.Put(handler.QueryHash("{ b }"), "{ b }"); err != handler.ErrQueryStoreFull {
+		t.Errorf("got %v, want ErrQueryStoreFull", err)
+	}
+}
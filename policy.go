@@ -0,0 +1,482 @@
+package parse_graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// Access rules of a Policy.
+const (
+	// AccessPublic lets anyone Parse lets read an object read the field. It is the default.
+	AccessPublic = "public"
+	// AccessOwner lets the user owning an object read the field: the user itself for
+	// _User objects, the user of the class's OwnerField otherwise.
+	AccessOwner = "owner"
+	// AccessMaster only lets requests with the master key read the field.
+	AccessMaster = "master"
+	// AccessHidden does not expose the class or field at all.
+	AccessHidden = "hidden"
+	// AccessRolePrefix, followed by the name of a role, lets the role's members read the
+	// field.
+	AccessRolePrefix = "role:"
+)
+
+// Access lists the rules of who may read a class or field, any of which grants access.
+// Requests with the master key may read everything that is not hidden. In policy files an
+// Access is a rule or a list of rules.
+type Access []string
+
+func (a *Access) UnmarshalJSON(b []byte) error {
+	var rule string
+	if err := json.Unmarshal(b, &rule); err == nil {
+		*a = Access{rule}
+		return nil
+	}
+	var rules []string
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return fmt.Errorf("access must be a rule or a list of rules, got %s", b)
+	}
+	*a = Access(rules)
+	return nil
+}
+
+func (a Access) public() bool {
+	if len(a) == 0 {
+		return true
+	}
+	for _, rule := range a {
+		if rule == AccessPublic {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Access) hidden() bool {
+	return len(a) == 1 && a[0] == AccessHidden
+}
+
+// ClassPolicy is the policy of a class.
+type ClassPolicy struct {
+	// Access is the access of the fields of the class without one of their own. Hidden
+	// classes are not exposed at all, and master-only classes can only be queried or
+	// changed with the master key.
+	Access Access `json:"access"`
+	// OwnerField is the Pointer to _User field naming the owner of the objects of a
+	// class other than _User, for the "owner" rule.
+	OwnerField string `json:"ownerField"`
+	// Fields are the access of the fields of the class, by name.
+	Fields map[string]Access `json:"fields"`
+}
+
+// Policy controls which classes and fields of a Parse app are exposed, and who may read
+// them, on top of what Parse's ACLs and class-level permissions allow. Fields a request
+// may not read resolve to null; hidden classes and fields, and Pointer fields to hidden
+// classes, are left out of the GraphQL schema. A policy file is the JSON encoding of a
+// Policy:
+//
+//	{"classes": {
+//		"_User": {"fields": {"authData": "master", "email": ["owner", "role:Support"]}},
+//		"Invoice": {"access": "owner", "ownerField": "customer"},
+//		"AuditLog": {"access": "hidden"}
+//	}}
+type Policy struct {
+	Classes map[string]*ClassPolicy `json:"classes"`
+}
+
+// LoadPolicy reads a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal(b, policy); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return policy, nil
+}
+
+// Validate checks p against the classes of an app, such as the Schema of a ParseSchema:
+// every class and field it names must exist, its rules must be known, and classes using
+// the "owner" rule must have a valid OwnerField.
+func (p *Policy) Validate(classes map[string]*parse.Schema) error {
+	var errs []string
+	errorf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	for className, cp := range p.Classes {
+		class, ok := classes[className]
+		if !ok {
+			errorf("class '%s' does not exist", className)
+			continue
+		}
+		if className == "_User" && cp.Access.hidden() {
+			errorf("class '_User' cannot be hidden; hide its fields instead")
+		}
+		owned := false
+		check := func(where string, access Access) {
+			for _, rule := range access {
+				switch {
+				case rule == AccessPublic || rule == AccessMaster:
+				case rule == AccessOwner:
+					owned = true
+				case rule == AccessHidden:
+					if len(access) > 1 {
+						errorf("%s: 'hidden' cannot be combined with other rules", where)
+					}
+				case strings.HasPrefix(rule, AccessRolePrefix) && len(rule) > len(AccessRolePrefix):
+				default:
+					errorf("%s: unknown rule '%s'", where, rule)
+				}
+			}
+		}
+		check(fmt.Sprintf("class '%s'", className), cp.Access)
+		for fieldName, access := range cp.Fields {
+			if _, ok := class.Fields[fieldName]; !ok {
+				errorf("field '%s' of class '%s' does not exist", fieldName, className)
+			}
+			check(fmt.Sprintf("field '%s' of class '%s'", fieldName, className), access)
+		}
+		if className == "_User" {
+			if cp.OwnerField != "" {
+				errorf("class '_User' is owned by its users and cannot have an ownerField")
+			}
+			continue
+		}
+		if owned && cp.OwnerField == "" {
+			errorf("class '%s' uses the 'owner' rule but has no ownerField", className)
+		}
+		if cp.OwnerField != "" {
+			if field := class.Fields[cp.OwnerField]; field.Type != "Pointer" || field.TargetClass != "_User" {
+				errorf("ownerField '%s' of class '%s' is not a Pointer to _User", cp.OwnerField, className)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("invalid policy:\n\t%s", strings.Join(errs, "\n\t"))
+}
+
+// classAccess returns the access of the class className. A nil Policy exposes everything.
+func (p *Policy) classAccess(className string) Access {
+	if p == nil || p.Classes[className] == nil {
+		return nil
+	}
+	return p.Classes[className].Access
+}
+
+// fieldAccess returns the access of the field fieldName of the class className.
+func (p *Policy) fieldAccess(className, fieldName string) Access {
+	if p == nil || p.Classes[className] == nil {
+		return nil
+	}
+	if access, ok := p.Classes[className].Fields[fieldName]; ok {
+		return access
+	}
+	return p.Classes[className].Access
+}
+
+// classHidden reports whether the class className is hidden.
+func (p *Policy) classHidden(className string) bool {
+	return p.classAccess(className).hidden()
+}
+
+// fieldHidden reports whether the field fieldName of the class className is hidden, or
+// points at a hidden class.
+func (p *Policy) fieldHidden(classes map[string]*parse.Schema, className, fieldName string) bool {
+	if p == nil {
+		return false
+	}
+	if p.fieldAccess(className, fieldName).hidden() {
+		return true
+	}
+	class, ok := classes[className]
+	if !ok {
+		return false
+	}
+	field := class.Fields[fieldName]
+	switch field.Type {
+	case "Pointer", "ReversePointer", "Relation":
+		return p.classHidden(field.TargetClass)
+	}
+	return false
+}
+
+// names reports whether p names the field fieldName of the class className, as one of its
+// fields or as its OwnerField.
+func (p *Policy) names(className, fieldName string) bool {
+	if p == nil || p.Classes[className] == nil {
+		return false
+	}
+	_, ok := p.Classes[className].Fields[fieldName]
+	return ok || p.Classes[className].OwnerField == fieldName
+}
+
+// caller is the user, and the roles, of the caller of a request, fetched at most once
+// per request for the policy checks of its fields.
+type caller struct {
+	userOnce  sync.Once
+	userID    string
+	userErr   error
+	rolesOnce sync.Once
+	roles     map[string]bool
+	rolesErr  error
+}
+
+// requestCaller returns the caller of the request in ctx. It is only shared by the fields
+// of requests authenticated by an authenticator.
+func requestCaller(ctx context.Context) *caller {
+	if auth, ok := ctx.Value(requestAuthKey).(*requestAuth); ok {
+		return &auth.caller
+	}
+	return &caller{}
+}
+
+// user returns the objectId of the caller's user, or an empty string for anonymous
+// callers.
+func (c *caller) user(ctx context.Context, client *parse.Client) (string, error) {
+	c.userOnce.Do(func() {
+		identity := requestIdentity(ctx)
+		switch {
+		case identity.UserID != "":
+			c.userID = identity.UserID
+		case identity.SessionToken != "":
+			if t, ok := tracer.FromContext(ctx); ok {
+				t.IncQueries(1)
+			}
+			var user map[string]interface{}
+			c.userErr = tracedClient(ctx, client).CurrentUser(&user)
+			c.userID, _ = user["objectId"].(string)
+		}
+	})
+	return c.userID, c.userErr
+}
+
+// hasRole reports whether the caller's user belongs to the role roleName.
+func (c *caller) hasRole(ctx context.Context, client *parse.Client, roleName string) (bool, error) {
+	userID, err := c.user(ctx, client)
+	if err != nil || userID == "" {
+		return false, err
+	}
+	c.rolesOnce.Do(func() {
+		var roles []map[string]interface{}
+		roles, c.rolesErr = userRoles(ctx, tracedClient(ctx, client), userID)
+		c.roles = make(map[string]bool, len(roles))
+		for _, role := range roles {
+			name, _ := role["name"].(string)
+			c.roles[name] = true
+		}
+	})
+	return c.roles[roleName], c.rolesErr
+}
+
+// readable reports whether the caller of the request in ctx may read the field
+// fieldName of p's object.
+func (p *ParseClass) readable(ctx context.Context, fieldName string) (bool, error) {
+	access := p.policy.fieldAccess(p.class.ClassName, fieldName)
+	if access.public() {
+		return true, nil
+	}
+	if access.hidden() {
+		return false, nil
+	}
+	if isMaster(ctx) {
+		return true, nil
+	}
+	client := requestClient(ctx, p.client)
+	c := requestCaller(ctx)
+	for _, rule := range access {
+		switch {
+		case rule == AccessOwner:
+			if p.self {
+				return true, nil
+			}
+			userID, err := c.user(ctx, client)
+			if err != nil {
+				return false, err
+			}
+			if userID != "" && userID == p.owner() {
+				return true, nil
+			}
+		case strings.HasPrefix(rule, AccessRolePrefix):
+			ok, err := c.hasRole(ctx, client, strings.TrimPrefix(rule, AccessRolePrefix))
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// owner returns the objectId of the user owning p's object, if known.
+func (p *ParseClass) owner() string {
+	if p.class.ClassName == "_User" {
+		id, _ := p.Data["objectId"].(string)
+		return id
+	}
+	if p.policy == nil || p.policy.Classes[p.class.ClassName] == nil {
+		return ""
+	}
+	ptr, _ := p.Data[p.policy.Classes[p.class.ClassName].OwnerField].(map[string]interface{})
+	id, _ := ptr["objectId"].(string)
+	return id
+}
+
+// authorizeQuery checks that the caller of the request in ctx may query p's class with
+// args: master-only classes require the master key, and so does filtering or ordering on
+// fields that are not public, including through geo arguments, as matches and their
+// order would reveal their values.
+func (p *ParseClass) authorizeQuery(ctx context.Context, args graphql.Arguments) error {
+	if err := p.authorizeClass(ctx); err != nil {
+		return err
+	}
+	geo, _, err := p.geoConstraints(args)
+	if err != nil {
+		return err
+	}
+	where, err := whereClause(args, geo)
+	if err != nil {
+		return err
+	}
+	if err := p.authorizeWhere(ctx, where); err != nil {
+		return err
+	}
+	// orders that are not strings are rejected by queryOptions
+	order, _ := args.Get("order")
+	orderStr, _ := order.(string)
+	for _, key := range strings.Split(orderStr, ",") {
+		if key = strings.TrimPrefix(strings.TrimSpace(key), "-"); key == "" {
+			continue
+		}
+		if err := p.authorizeField(ctx, key, "ordering by"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeWhere checks the fields where filters on, including in its $or and $and
+// clauses, and the subqueries of its $inQuery, $notInQuery, $select, $dontSelect and
+// $relatedTo constraints against the policy of the classes they query. $nor is rejected.
+func (p *ParseClass) authorizeWhere(ctx context.Context, where map[string]interface{}) error {
+	for name, value := range where {
+		switch name {
+		case "$or", "$and":
+			clauses, _ := value.([]interface{})
+			for _, clause := range clauses {
+				if clause, ok := clause.(map[string]interface{}); ok {
+					if err := p.authorizeWhere(ctx, clause); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		case "$nor":
+			return argumentErrorf("'$nor' is not supported.")
+		case "$relatedTo":
+			if err := p.authorizeRelatedTo(ctx, value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.authorizeField(ctx, name, "filtering on"); err != nil {
+			return err
+		}
+		ops, _ := value.(map[string]interface{})
+		for op, arg := range ops {
+			var err error
+			switch op {
+			case "$inQuery", "$notInQuery":
+				err = p.authorizeSubquery(ctx, op, arg, "")
+			case "$select", "$dontSelect":
+				m, _ := arg.(map[string]interface{})
+				key, _ := m["key"].(string)
+				err = p.authorizeSubquery(ctx, op, m["query"], key)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// authorizeField checks that the caller of the request in ctx may use the field name of
+// p's class for a query, such as filtering on it.
+func (p *ParseClass) authorizeField(ctx context.Context, name, use string) error {
+	access := p.policy.fieldAccess(p.class.ClassName, name)
+	if access.hidden() || p.policy.fieldHidden(p.schema, p.class.ClassName, name) {
+		return argumentErrorf("'%s' is not a field of class '%s'.", name, p.class.ClassName)
+	}
+	if !access.public() && !isMaster(ctx) {
+		return fmt.Errorf("%w: %s '%s'", parse.ErrRequiresMasterKey, use, name)
+	}
+	return nil
+}
+
+// authorizeSubquery checks the subquery, {className, where}, of the constraint op, and
+// the field key it selects, if any.
+func (p *ParseClass) authorizeSubquery(ctx context.Context, op string, subquery interface{}, key string) error {
+	m, _ := subquery.(map[string]interface{})
+	className, _ := m["className"].(string)
+	sub, err := p.queriedClass(ctx, op, className)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		if err := sub.authorizeField(ctx, key, "selecting"); err != nil {
+			return err
+		}
+	}
+	where, _ := m["where"].(map[string]interface{})
+	return sub.authorizeWhere(ctx, where)
+}
+
+// authorizeRelatedTo checks a $relatedTo constraint, {object, key}, which reveals the
+// objects in the Relation field key of object.
+func (p *ParseClass) authorizeRelatedTo(ctx context.Context, value interface{}) error {
+	m, _ := value.(map[string]interface{})
+	object, _ := m["object"].(map[string]interface{})
+	className, _ := object["className"].(string)
+	key, _ := m["key"].(string)
+	related, err := p.queriedClass(ctx, "$relatedTo", className)
+	if err != nil {
+		return err
+	}
+	return related.authorizeField(ctx, key, "filtering on")
+}
+
+// queriedClass returns the class className queried by the constraint op, which must be
+// exposed, and that the caller of the request in ctx may query.
+func (p *ParseClass) queriedClass(ctx context.Context, op, className string) (*ParseClass, error) {
+	if _, ok := p.schema[className]; !ok || p.policy.classHidden(className) {
+		return nil, argumentErrorf("'%s': class '%s' does not exist.", op, className)
+	}
+	pc, err := p.newClass(className)
+	if err != nil {
+		return nil, err
+	}
+	return pc, pc.authorizeClass(ctx)
+}
+
+// authorizeClass checks that the caller of the request in ctx may query or change
+// objects of p's class.
+func (p *ParseClass) authorizeClass(ctx context.Context) error {
+	access := p.policy.classAccess(p.class.ClassName)
+	if len(access) == 1 && access[0] == AccessMaster && !isMaster(ctx) {
+		return parse.ErrRequiresMasterKey
+	}
+	return nil
+}
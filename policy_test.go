@@ -0,0 +1,211 @@
+package parse_graphql_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+)
+
+func userPointer(id string) map[string]interface{} {
+	return map[string]interface{}{"__type": "Pointer", "className": "_User", "objectId": id}
+}
+
+func TestPolicy(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Invoice", map[string]parse.SchemaField{
+		"customer": {Type: "Pointer", TargetClass: "_User"},
+		"total":    {Type: "Number"},
+		"note":     {Type: "String"},
+	})
+	p.AddClass("AuditLog", map[string]parse.SchemaField{"entry": {Type: "String"}})
+	annID, ann := p.AddUser("ann", "pw", map[string]interface{}{"email": "ann@example.com"})
+	bobID, bob := p.AddUser("bob", "pw", map[string]interface{}{"email": "bob@example.com"})
+	p.AddObject("_Role", map[string]interface{}{"name": "Support", "users": []interface{}{userPointer(bobID)}})
+	p.AddObject("Invoice", map[string]interface{}{"customer": userPointer(annID), "total": 10, "note": "n"})
+
+	policy := &parse_graphql.Policy{}
+	if err := json.Unmarshal([]byte(`{"classes": {
+		"_User": {"fields": {"email": ["owner", "role:Support"], "authData": "master"}},
+		"Invoice": {"access": "owner", "ownerField": "customer", "fields": {"note": "hidden", "customer": "public"}},
+		"AuditLog": {"access": "hidden"}
+	}}`), policy); err != nil {
+		t.Fatal(err)
+	}
+	e := newEndpoint(t, p, policy, nil)
+	as := func(token string) map[string]string { return map[string]string{"X-Parse-Session-Token": token} }
+
+	emails := func(r *response) map[interface{}]interface{} {
+		emails := map[interface{}]interface{}{}
+		for _, u := range r.objects(t, 0) {
+			emails[u["username"]] = u["email"]
+		}
+		return emails
+	}
+	if got := emails(e.post(t, as(ann), `{ _User(order: "username") { username, email } }`)); got["ann"] != "ann@example.com" || got["bob"] != nil {
+		t.Errorf("emails read by ann: got %v, want her own only", got)
+	}
+	if got := emails(e.post(t, as(bob), `{ _User(order: "username") { username, email } }`)); got["ann"] != "ann@example.com" || got["bob"] != "bob@example.com" {
+		t.Errorf("emails read by Support: got %v, want all of them", got)
+	}
+	if r := e.post(t, nil, `{ _User(where: {email: "ann@example.com"}) { username } }`); r.Error == nil {
+		t.Errorf("filtering on a restricted field: got %v, want an error", r.Data)
+	}
+
+	totals := func(token string) []interface{} {
+		var totals []interface{}
+		for _, invoice := range e.post(t, as(token), `{ Invoice { total } }`).objects(t, 0) {
+			totals = append(totals, invoice["total"])
+		}
+		return totals
+	}
+	if got := totals(ann); len(got) != 1 || got[0] != float64(10) {
+		t.Errorf("invoice totals read by its customer: got %v", got)
+	}
+	if got := totals(bob); len(got) != 1 || got[0] != nil {
+		t.Errorf("invoice totals read by someone else: got %v, want null", got)
+	}
+
+	for _, query := range []string{`{ Invoice { note } }`, `{ AuditLog { entry } }`} {
+		if r := e.post(t, as(ann), query); r.Error == nil {
+			t.Errorf("%s: got %v, want an error for hidden fields", query, r.Data)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Invoice", map[string]parse.SchemaField{"total": {Type: "Number"}})
+	classes, err := p.Client().WithMasterKey(p.MasterKey).GetFullSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := &parse_graphql.Policy{}
+	if err := json.Unmarshal([]byte(`{"classes": {
+		"Nope": {},
+		"Invoice": {"access": "owner", "fields": {"totl": "master"}},
+		"_User": {"access": "hidden", "fields": {"email": "role:"}}
+	}}`), policy); err != nil {
+		t.Fatal(err)
+	}
+	err = policy.Validate(classes)
+	if err == nil {
+		t.Fatal("invalid policy accepted")
+	}
+	for _, want := range []string{
+		"class 'Nope' does not exist",
+		"class 'Invoice' uses the 'owner' rule but has no ownerField",
+		"field 'totl' of class 'Invoice' does not exist",
+		"class '_User' cannot be hidden",
+		"unknown rule 'role:'",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestPolicyQueries(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Invoice", map[string]parse.SchemaField{
+		"customer": {Type: "Pointer", TargetClass: "_User"},
+		"note":     {Type: "String"},
+	})
+	p.AddClass("AuditLog", map[string]parse.SchemaField{"entry": {Type: "String"}})
+	p.AddClass("Payout", map[string]parse.SchemaField{"user": {Type: "Pointer", TargetClass: "_User"}})
+	p.AddClass("_User", map[string]parse.SchemaField{"home": {Type: "GeoPoint"}})
+	annID, ann := p.AddUser("ann", "pw", map[string]interface{}{"email": "ann@example.com"})
+	bobID, _ := p.AddUser("bob", "pw", nil)
+	roleID := p.AddObject("_Role", map[string]interface{}{"name": "Support", "users": []interface{}{userPointer(bobID)}})
+	p.AddObject("Invoice", map[string]interface{}{"customer": userPointer(annID), "note": "n"})
+
+	policy := &parse_graphql.Policy{}
+	if err := json.Unmarshal([]byte(`{"classes": {
+		"_User": {"fields": {"email": "owner", "home": "owner"}},
+		"Invoice": {"fields": {"note": "hidden"}},
+		"AuditLog": {"access": "hidden"},
+		"Payout": {"access": "master"}
+	}}`), policy); err != nil {
+		t.Fatal(err)
+	}
+	e := newEndpoint(t, p, policy, nil)
+	// where clauses are sent as variables, as GraphQL object keys cannot start with '$'
+	query := func(root, where, order string) *response {
+		var w interface{}
+		if err := json.Unmarshal([]byte(where), &w); err != nil {
+			t.Fatal(err)
+		}
+		return e.postBody(t, map[string]string{"X-Parse-Session-Token": ann}, map[string]interface{}{
+			"query":     `query q($where: Object) { ` + root + `(where: $where, order: "` + order + `") { objectId } }`,
+			"variables": map[string]interface{}{"where": w},
+		})
+	}
+	subquery := func(className, where string) string {
+		return `{"className": "` + className + `", "where": ` + where + `}`
+	}
+
+	for _, test := range []struct {
+		name, root, where, order, code string
+	}{
+		{"$inQuery on a restricted field", "Invoice", `{"customer": {"$inQuery": ` + subquery("_User", `{"email": "ann@example.com"}`) + `}}`, "", "FORBIDDEN"},
+		{"nested $inQuery", "Invoice", `{"customer": {"$inQuery": ` + subquery("_User", `{"$or": [{"objectId": {"$inQuery": `+subquery("_User", `{"email": "x"}`)+`}}]}`) + `}}`, "", "FORBIDDEN"},
+		{"$notInQuery on a hidden class", "Invoice", `{"customer": {"$notInQuery": ` + subquery("AuditLog", `{}`) + `}}`, "", "BAD_USER_INPUT"},
+		{"$inQuery on a master-only class", "_User", `{"objectId": {"$inQuery": ` + subquery("Payout", `{}`) + `}}`, "", "FORBIDDEN"},
+		{"$select of a restricted field", "_User", `{"username": {"$select": {"query": ` + subquery("_User", `{}`) + `, "key": "email"}}}`, "", "FORBIDDEN"},
+		{"$dontSelect on a hidden field", "_User", `{"objectId": {"$dontSelect": {"query": ` + subquery("Invoice", `{"note": "n"}`) + `, "key": "customer"}}}`, "", "BAD_USER_INPUT"},
+		{"$relatedTo a hidden class", "_User", `{"$relatedTo": {"object": {"__type": "Pointer", "className": "AuditLog", "objectId": "x"}, "key": "entry"}}`, "", "BAD_USER_INPUT"},
+		{"$nor", "_User", `{"$nor": [{"username": "bob"}]}`, "", "BAD_USER_INPUT"},
+		{"order by a restricted field", "_User", `{}`, "username,-email", "FORBIDDEN"},
+	} {
+		if r := query(test.root, test.where, test.order); r.Error == nil || r.Error.Extensions["code"] != test.code {
+			t.Errorf("%s: got %v (error %v), want %s", test.name, r.Data, r.Error, test.code)
+		}
+	}
+	for _, arg := range []string{
+		`near: {home: {latitude: 37.77, longitude: -122.41}}`,
+		`withinBox: {home: [{latitude: 37.7, longitude: -122.5}, {latitude: 37.8, longitude: -122.3}]}`,
+	} {
+		r := e.post(t, map[string]string{"X-Parse-Session-Token": ann}, `{ _User(`+arg+`) { username } }`)
+		if r.Error == nil || r.Error.Extensions["code"] != "FORBIDDEN" {
+			t.Errorf("%s: got %v (error %v), want FORBIDDEN", arg, r.Data, r.Error)
+		}
+	}
+
+	// subqueries on fields the caller may read are allowed
+	if r := query("Invoice", `{"customer": {"$inQuery": `+subquery("_User", `{"username": "ann"}`)+`}}`, "-createdAt"); len(r.objects(t, 0)) != 1 {
+		t.Errorf("$inQuery on a public field: got %v (error %v), want ann's invoice", r.Data, r.Error)
+	}
+	r := query("_User", `{"$relatedTo": {"object": {"__type": "Pointer", "className": "_Role", "objectId": "`+roleID+`"}, "key": "users"}}`, "")
+	if users := r.objects(t, 0); len(users) != 1 || users[0]["objectId"] != bobID {
+		t.Errorf("$relatedTo a public relation: got %v (error %v), want bob", r.Data, r.Error)
+	}
+}
+
+func TestPolicyLoggedInUser(t *testing.T) {
+	p := newParse(t)
+	p.AddUser("ann", "pw", map[string]interface{}{"email": "ann@example.com"})
+	policy := &parse_graphql.Policy{Classes: map[string]*parse_graphql.ClassPolicy{
+		"_User": {Fields: map[string]parse_graphql.Access{"authData": {parse_graphql.AccessMaster}, "email": {parse_graphql.AccessOwner}}},
+	}}
+	e := newEndpoint(t, p, policy, nil)
+	for _, test := range []struct {
+		mutation string
+		email    interface{}
+	}{
+		{`logIn(username: "ann", password: "pw")`, "ann@example.com"},
+		{`signUp(username: "bob", password: "pw", email: "bob@example.com")`, "bob@example.com"},
+		{`logInWith(provider: "anonymous", authData: {id: "5f6f2d6e-8e4b-4c1a-9d2e-3b8f7a6c5d4e"})`, nil},
+	} {
+		r := e.post(t, nil, `mutation m { `+test.mutation+` { username, email, authData, sessionToken } }`)
+		user, _ := r.field(t, 0).(map[string]interface{})
+		if user["sessionToken"] == nil || user["username"] == nil {
+			t.Errorf("%s: got %v (error %v), want the user and its session", test.mutation, user, r.Error)
+		}
+		// the user reads its own email, but not its master-only authData
+		if user["email"] != test.email || user["authData"] != nil {
+			t.Errorf("%s: got %v, want email %v and a null authData", test.mutation, user, test.email)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package parse_graphql
+
+import (
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// sendPush sends a push notification to the installations subscribed to the 'channels'
+// argument, or matching the 'where' argument. It requires the request to carry the
+// master key, and returns the ID of the _PushStatus object tracking the notification.
+func (s *ParseSchema) sendPush(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	if !isMaster(ctx) {
+		return nil, parse.ErrRequiresMasterKey
+	}
+	push := &parse.Push{}
+	data, _ := f.Arguments.Get("data")
+	if push.Data, _ = data.(map[string]interface{}); push.Data == nil {
+		return nil, argumentErrorf("'data' must be an object, such as {alert: \"Hello\"}.")
+	}
+
+	channels, hasChannels := f.Arguments.Get("channels")
+	where, hasWhere := f.Arguments.Get("where")
+	switch {
+	case hasChannels == hasWhere:
+		return nil, argumentErrorf("exactly one of 'channels' and 'where' must be set.")
+	case hasChannels:
+		list, ok := channels.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, argumentErrorf("'channels' must be a non-empty list of strings.")
+		}
+		for _, c := range list {
+			channel, ok := c.(string)
+			if !ok {
+				return nil, argumentErrorf("'channels' must be a non-empty list of strings.")
+			}
+			push.Channels = append(push.Channels, channel)
+		}
+	default:
+		if push.Where, _ = where.(map[string]interface{}); push.Where == nil {
+			return nil, argumentErrorf("'where' must be a map of _Installation fields to constraints.")
+		}
+	}
+
+	var err error
+	if push.PushTime, err = timeArgument(f.Arguments, "pushTime"); err != nil {
+		return nil, err
+	}
+	if push.ExpirationTime, err = timeArgument(f.Arguments, "expirationTime"); err != nil {
+		return nil, err
+	}
+	if value, ok := f.Arguments.Get("expirationInterval"); ok {
+		interval, ok := value.(int)
+		if !ok || interval <= 0 {
+			return nil, argumentErrorf("'expirationInterval' must be a positive number of seconds, got %#v", value)
+		}
+		if !push.ExpirationTime.IsZero() {
+			return nil, argumentErrorf("'expirationTime' and 'expirationInterval' cannot be set together.")
+		}
+		push.ExpirationInterval = interval
+	}
+
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	statusID, err := tracedClient(ctx, s.authedClient(ctx)).SendPush(push)
+	if err != nil || statusID == "" {
+		return nil, err
+	}
+	return statusID, nil
+}
+
+// timeArgument parses the RFC 3339 timestamp given to arg, if any.
+func timeArgument(args graphql.Arguments, arg string) (time.Time, error) {
+	value, ok := args.Get(arg)
+	if !ok {
+		return time.Time{}, nil
+	}
+	s, _ := value.(string)
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, argumentErrorf("'%s' must be an RFC 3339 timestamp such as \"2006-01-02T15:04:05Z\", got %#v", arg, value)
+	}
+	return t, nil
+}
@@ -0,0 +1,52 @@
+package parse_graphql_test
+
+import (
+	"testing"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse_graphql"
+	"github.com/tmc/parse_graphql/parsetest"
+)
+
+func TestSendPush(t *testing.T) {
+	p := newParse(t)
+	p.AddObject("_Installation", map[string]interface{}{"deviceType": "ios", "channels": []interface{}{"news"}})
+	p.AddObject("_Installation", map[string]interface{}{"deviceType": "android", "channels": []interface{}{"sports"}})
+	e := newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) {
+		h.Authenticator = &parse_graphql.ParseAuthenticator{Client: p.Client(), MasterKey: parsetest.MasterKey}
+	})
+	master := map[string]string{"X-Parse-Master-Key": parsetest.MasterKey}
+
+	if r := e.post(t, nil, `mutation p { sendPush(channels: ["news"], data: {alert: "hi"}) }`); r.Error == nil || r.Error.Extensions["code"] != "FORBIDDEN" {
+		t.Errorf("without the master key: got %v (error %v), want FORBIDDEN", r.Data, r.Error)
+	}
+	for _, args := range []string{
+		`channels: ["news"]`,
+		`channels: ["news"], data: "hi"`,
+		`data: {alert: "hi"}`,
+		`channels: ["news"], where: {deviceType: "ios"}, data: {alert: "hi"}`,
+		`channels: [], data: {alert: "hi"}`,
+		`channels: ["news", 1], data: {alert: "hi"}`,
+		`where: "ios", data: {alert: "hi"}`,
+		`channels: ["news"], data: {alert: "hi"}, pushTime: "tomorrow"`,
+		`channels: ["news"], data: {alert: "hi"}, expirationInterval: 0`,
+		`channels: ["news"], data: {alert: "hi"}, expirationTime: "2030-01-02T15:04:05Z", expirationInterval: 60`,
+	} {
+		r := e.post(t, master, `mutation p { sendPush(`+args+`) }`)
+		if r.Error == nil || r.Error.Extensions["code"] != "BAD_USER_INPUT" {
+			t.Errorf("%s: got %v (error %v), want BAD_USER_INPUT", args, r.Data, r.Error)
+		}
+	}
+	if pushes := p.Pushes(); len(pushes) != 0 {
+		t.Fatalf("invalid pushes were sent: %v", pushes)
+	}
+
+	r := e.post(t, master, `mutation p { sendPush(where: {deviceType: "android"}, data: {alert: "hi"}, expirationInterval: 60) }`)
+	pushes := p.Pushes()
+	if len(pushes) != 1 || r.field(t, 0) != pushes[0].StatusID {
+		t.Fatalf("got %v (error %v), want the ID of the _PushStatus of the push", r.Data, r.Error)
+	}
+	if push := pushes[0]; len(push.Installations) != 1 || push.Body["expiration_interval"] != float64(60) {
+		t.Errorf("sent %v to %v, want an expiration interval of 60 to one installation", push.Body, push.Installations)
+	}
+}
@@ -0,0 +1,293 @@
+package parse_graphql
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// Rate is a token bucket allowance: bursts of up to N, refilled at N per Per. The zero
+// Rate is unlimited.
+type Rate struct {
+	N   int
+	Per time.Duration
+}
+
+// ParseRate parses a rate written "<n>/<duration>", such as "60/1m".
+func ParseRate(s string) (Rate, error) {
+	i := strings.Index(s, "/")
+	if i < 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q, want <n>/<duration>", s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil || n < 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q, want <n>/<duration>", s)
+	}
+	per, err := time.ParseDuration(s[i+1:])
+	if err != nil || per <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q, want <n>/<duration>", s)
+	}
+	return Rate{N: n, Per: per}, nil
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%v", r.N, r.Per)
+}
+
+func (r Rate) unlimited() bool {
+	return r.N <= 0 || r.Per <= 0
+}
+
+// OperationRates are the limits of the operations of a name.
+type OperationRates struct {
+	Requests Rate
+	Queries  Rate
+}
+
+// defaultMaxClients is the default bound of the number of clients a RateLimiter tracks.
+const defaultMaxClients = 100000
+
+// RateLimiter is a handler.Limiter giving each client token buckets of operations and of
+// Parse calls. Clients are keyed by their verified user: the user of their JWT or, with
+// Client set, of their session token. Other clients are keyed by the API key of their
+// KeyHeader, else by their address. Operations over the Queries limit stop resolving
+// their fields once they run out of Parse calls, and their clients wait until the calls
+// they made are refilled.
+type RateLimiter struct {
+	// Requests limits the operations each client may execute.
+	Requests Rate
+	// Queries limits the Parse calls the operations of each client may make.
+	Queries Rate
+	// Operations are the limits of the operations named by their keys, which get buckets
+	// of their own instead of counting against Requests and Queries.
+	Operations map[string]OperationRates
+	// AddressRequests limits the operations of each client address, whatever the keys
+	// its requests send. It is checked before session tokens are verified.
+	AddressRequests Rate
+
+	// Client, if set, verifies the session tokens of clients with Parse, once their
+	// address is allowed. Without it, clients sending session tokens are keyed by their
+	// address.
+	Client *parse.Client
+	// SessionCache caches the users of verified session tokens; if nil, they are cached
+	// for a minute. Tokens failing verification are remembered for a minute either way,
+	// so clients repeating them do not cost a Parse call per request.
+	SessionCache *SessionCache
+
+	// KeyHeader, if set, is the request header carrying the API key of clients without a
+	// verified user, such as "X-API-Key". It must be set by a gateway checking the keys,
+	// as clients could otherwise send a new key with every request.
+	KeyHeader string
+	// TrustedProxies is the number of proxies in front of the server appending the
+	// address they received requests from to the X-Forwarded-For header. The address of
+	// clients is then the one appended by the outermost proxy. Zero ignores the header.
+	TrustedProxies int
+	// MaxClients bounds the number of buckets tracked; defaultMaxClients if zero.
+	MaxClients int
+
+	sessionsOnce sync.Once
+	sessions     *SessionCache
+	rejected     *SessionCache // tokens that failed verification
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element // of *tokenBucket
+	lru     *list.List               // most recently used first
+}
+
+type tokenBucket struct {
+	key     string
+	rate    Rate
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds the tokens earned since b was last updated, up to its burst.
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += float64(now.Sub(b.updated)) / float64(b.rate.Per) * float64(b.rate.N)
+	b.tokens = math.Min(b.tokens, float64(b.rate.N))
+	b.updated = now
+}
+
+// wait returns how long until b holds a token.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / float64(b.rate.N) * float64(b.rate.Per))
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, operation *graphql.Operation, t *tracer.Tracer) (bool, time.Duration) {
+	address := l.address(ctx)
+	if !l.AddressRequests.unlimited() {
+		l.mu.Lock()
+		bucket := l.bucket("address:"+address, l.AddressRequests, time.Now())
+		wait := bucket.wait()
+		if wait == 0 {
+			bucket.tokens--
+		}
+		l.mu.Unlock()
+		if wait > 0 {
+			return false, wait
+		}
+	}
+	requests, queries, key := l.rates(ctx, operation)
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var wait time.Duration
+	var taken *tokenBucket
+	if !requests.unlimited() {
+		taken = l.bucket(key+"requests", requests, now)
+		wait = taken.wait()
+	}
+	if !queries.unlimited() && operation.Type != graphql.OperationSubscription {
+		bucket := l.bucket(key+"queries", queries, now)
+		if w := bucket.wait(); w > 0 {
+			wait = maxDuration(wait, w)
+		} else {
+			t.SetQueryLimit(int(bucket.tokens))
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	if taken != nil {
+		taken.tokens--
+	}
+	return true, 0
+}
+
+func (l *RateLimiter) Done(ctx context.Context, operation *graphql.Operation, t *tracer.Tracer) time.Duration {
+	_, queries, key := l.rates(ctx, operation)
+	if queries.unlimited() {
+		return 0
+	}
+	var n int
+	t.WithLock(func(t *tracer.Tracer) { n = t.Queries })
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket := l.bucket(key+"queries", queries, time.Now())
+	// clients of operations exceeding their budget owe the calls they made
+	bucket.tokens -= float64(n)
+	return bucket.wait()
+}
+
+// rates returns the limits of operation, and the prefix of the keys of its client's
+// buckets.
+func (l *RateLimiter) rates(ctx context.Context, operation *graphql.Operation) (requests, queries Rate, key string) {
+	key = l.clientKey(ctx) + "\x00"
+	if rates, ok := l.Operations[operation.Name]; ok && operation.Name != "" {
+		return rates.Requests, rates.Queries, key + "operation:" + operation.Name + "\x00"
+	}
+	return l.Requests, l.Queries, key
+}
+
+// clientKey returns the key identifying the client of the request in ctx.
+func (l *RateLimiter) clientKey(ctx context.Context) string {
+	if userID := l.user(ctx); userID != "" {
+		return "user:" + userID
+	}
+	if r, ok := handler.RequestFromContext(ctx); ok && l.KeyHeader != "" {
+		if key := r.Header.Get(l.KeyHeader); key != "" {
+			return "key:" + key
+		}
+	}
+	return "address:" + l.address(ctx)
+}
+
+// user returns the objectId of the verified user of the request in ctx, if any.
+func (l *RateLimiter) user(ctx context.Context) string {
+	identity := requestIdentity(ctx)
+	if identity.UserID != "" || identity.SessionToken == "" || l.Client == nil {
+		return identity.UserID
+	}
+	l.sessionsOnce.Do(func() {
+		if l.sessions = l.SessionCache; l.sessions == nil {
+			l.sessions = NewSessionCache(time.Minute, l.maxClients())
+		}
+		l.rejected = NewSessionCache(time.Minute, l.maxClients())
+	})
+	if _, ok := l.rejected.get(identity.SessionToken); ok {
+		return ""
+	}
+	user, ok := l.sessions.get(identity.SessionToken)
+	if !ok {
+		// invalid tokens, and tokens that cannot be checked, leave the client anonymous
+		if err := l.Client.WithSessionToken(identity.SessionToken).CurrentUser(&user); err != nil {
+			l.rejected.set(identity.SessionToken, nil)
+			return ""
+		}
+		l.sessions.set(identity.SessionToken, user)
+	}
+	userID, _ := user["objectId"].(string)
+	return userID
+}
+
+// address returns the address of the client of the request in ctx.
+func (l *RateLimiter) address(ctx context.Context) string {
+	r, ok := handler.RequestFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); l.TrustedProxies > 0 && forwarded != "" {
+		// entries left of the ones appended by the proxies are set by clients
+		addresses := strings.Split(forwarded, ",")
+		if i := len(addresses) - l.TrustedProxies; i >= 0 {
+			return strings.TrimSpace(addresses[i])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// bucket returns the refilled bucket of key, creating a full one if needed. The least
+// recently used buckets are forgotten when MaxClients buckets are tracked.
+func (l *RateLimiter) bucket(key string, rate Rate, now time.Time) *tokenBucket {
+	if e, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(e)
+		b := e.Value.(*tokenBucket)
+		b.rate = rate
+		b.refill(now)
+		return b
+	}
+	if l.buckets == nil {
+		l.buckets = map[string]*list.Element{}
+		l.lru = list.New()
+	}
+	for l.lru.Len() >= l.maxClients() {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.buckets, oldest.Value.(*tokenBucket).key)
+	}
+	b := &tokenBucket{key: key, rate: rate, tokens: float64(rate.N), updated: now}
+	l.buckets[key] = l.lru.PushFront(b)
+	return b
+}
+
+func (l *RateLimiter) maxClients() int {
+	if l.MaxClients == 0 {
+		return defaultMaxClients
+	}
+	return l.MaxClients
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
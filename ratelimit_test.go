@@ -0,0 +1,148 @@
+package parse_graphql_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/parse_graphql"
+)
+
+func TestParseRate(t *testing.T) {
+	r, err := parse_graphql.ParseRate("60/1m")
+	if err != nil || r != (parse_graphql.Rate{N: 60, Per: time.Minute}) {
+		t.Errorf("got %v, %v", r, err)
+	}
+	for _, s := range []string{"60", "x/1m", "60/x", "-1/1m", "60/0s"} {
+		if _, err := parse_graphql.ParseRate(s); err == nil {
+			t.Errorf("%s: no error", s)
+		}
+	}
+}
+
+// limited serves the schema of a parsetest server holding a role through l.
+func limited(t *testing.T, l *parse_graphql.RateLimiter) *endpoint {
+	p := newParse(t)
+	p.AddObject("_Role", map[string]interface{}{"name": "Admin"})
+	return newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) { h.Limiter = l })
+}
+
+// wantLimited checks that r was rejected by a rate limit.
+func wantLimited(t *testing.T, r *response, what string) {
+	t.Helper()
+	if r.status != http.StatusTooManyRequests || r.header.Get("Retry-After") == "" {
+		t.Errorf("%s: got %d, Retry-After %q, want a 429 with a Retry-After", what, r.status, r.header.Get("Retry-After"))
+	}
+	if r.Error == nil || r.Error.Extensions["code"] != "RATE_LIMITED" {
+		t.Errorf("%s: got error %v, want RATE_LIMITED", what, r.Error)
+	}
+}
+
+// wantAllowed checks that r was not rejected by a rate limit.
+func wantAllowed(t *testing.T, r *response, what string) {
+	t.Helper()
+	if r.status == http.StatusTooManyRequests {
+		t.Errorf("%s: rate limited: %v", what, r.Error)
+	}
+}
+
+func TestRateLimiterRequests(t *testing.T) {
+	e := limited(t, &parse_graphql.RateLimiter{
+		Requests:   parse_graphql.Rate{N: 2, Per: time.Hour},
+		Operations: map[string]parse_graphql.OperationRates{"roles": {Requests: parse_graphql.Rate{N: 1, Per: time.Hour}}},
+	})
+	wantAllowed(t, e.post(t, nil, `{ _Role { name } }`), "first request")
+	wantAllowed(t, e.post(t, nil, `{ _Role { name } }`), "second request")
+	r := e.post(t, nil, `{ _Role { name } }`)
+	wantLimited(t, r, "third request")
+	if retryAfter := r.header.Get("Retry-After"); retryAfter != "1800" {
+		t.Errorf("Retry-After: got %s, want 1800", retryAfter)
+	}
+	wantAllowed(t, e.post(t, nil, `query roles { _Role { name } }`), "operation with its own limit")
+	wantLimited(t, e.post(t, nil, `query roles { _Role { name } }`), "operation over its own limit")
+}
+
+func TestRateLimiterQueries(t *testing.T) {
+	e := limited(t, &parse_graphql.RateLimiter{Queries: parse_graphql.Rate{N: 1, Per: time.Hour}})
+	// the second Parse call exceeds the budget, stopping the operation before its third
+	wantLimited(t, e.post(t, nil, `{ _RoleCount, _RoleCount, _RoleCount }`), "operation over budget")
+	wantLimited(t, e.post(t, nil, `{ _RoleCount }`), "client in debt")
+}
+
+func TestRateLimiterForwardedFor(t *testing.T) {
+	e := limited(t, &parse_graphql.RateLimiter{
+		AddressRequests: parse_graphql.Rate{N: 1, Per: time.Hour},
+		TrustedProxies:  1,
+	})
+	forwarded := func(addresses string) map[string]string {
+		return map[string]string{"X-Forwarded-For": addresses}
+	}
+	wantAllowed(t, e.post(t, forwarded("10.0.0.1, 192.0.2.1"), `{ _RoleCount }`), "first request")
+	// the client sets the leftmost entries, the proxy appends its address
+	wantLimited(t, e.post(t, forwarded("10.0.0.2, 192.0.2.1"), `{ _RoleCount }`), "rotated client entry")
+	wantAllowed(t, e.post(t, forwarded("192.0.2.2"), `{ _RoleCount }`), "other address")
+}
+
+func TestRateLimiterSessions(t *testing.T) {
+	p := newParse(t)
+	_, ann := p.AddUser("ann", "pw", nil)
+	_, bob := p.AddUser("bob", "pw", nil)
+	e := newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) {
+		h.Limiter = &parse_graphql.RateLimiter{Requests: parse_graphql.Rate{N: 1, Per: time.Hour}, Client: p.Client()}
+	})
+	session := func(token string) map[string]string {
+		return map[string]string{"X-Parse-Session-Token": token}
+	}
+	wantAllowed(t, e.post(t, session(ann), `{ _RoleCount }`), "ann")
+	wantLimited(t, e.post(t, session(ann), `{ _RoleCount }`), "ann again")
+	wantAllowed(t, e.post(t, session(bob), `{ _RoleCount }`), "bob")
+
+	// unverified tokens share the bucket of their address
+	wantAllowed(t, e.post(t, session("r:forged1"), `{ _RoleCount }`), "forged token")
+	wantLimited(t, e.post(t, session("r:forged2"), `{ _RoleCount }`), "other forged token")
+	wantLimited(t, e.post(t, nil, `{ _RoleCount }`), "anonymous")
+}
+
+// countingTransport is an http.RoundTripper counting the requests it sends.
+type countingTransport struct{ requests int32 }
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestRateLimiterRemembersInvalidSessions(t *testing.T) {
+	p := newParse(t)
+	transport := &countingTransport{}
+	client := p.Client()
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	e := newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) {
+		h.Limiter = &parse_graphql.RateLimiter{Requests: parse_graphql.Rate{N: 10, Per: time.Hour}, Client: client}
+	})
+	for i := 0; i < 3; i++ {
+		e.post(t, map[string]string{"X-Parse-Session-Token": "r:forged"}, `{ _RoleCount }`)
+	}
+	if n := atomic.LoadInt32(&transport.requests); n != 1 {
+		t.Errorf("got %d Parse calls verifying the forged token, want 1", n)
+	}
+}
+
+func TestRateLimiterEvictsLeastRecentlyUsed(t *testing.T) {
+	e := limited(t, &parse_graphql.RateLimiter{
+		Requests:       parse_graphql.Rate{N: 1, Per: time.Hour},
+		TrustedProxies: 1,
+		MaxClients:     2,
+	})
+	from := func(address string) map[string]string {
+		return map[string]string{"X-Forwarded-For": address}
+	}
+	wantAllowed(t, e.post(t, from("192.0.2.1"), `{ _RoleCount }`), "a")
+	wantAllowed(t, e.post(t, from("192.0.2.2"), `{ _RoleCount }`), "b")
+	wantLimited(t, e.post(t, from("192.0.2.1"), `{ _RoleCount }`), "a again")
+	// c evicts b, the least recently used
+	wantAllowed(t, e.post(t, from("192.0.2.3"), `{ _RoleCount }`), "c")
+	wantLimited(t, e.post(t, from("192.0.2.1"), `{ _RoleCount }`), "a after c")
+	wantAllowed(t, e.post(t, from("192.0.2.2"), `{ _RoleCount }`), "b after eviction")
+}
@@ -0,0 +1,88 @@
+package parse_graphql_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+func TestParseRetriesCanceled(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	defer func(baseURL string) { parse.BaseURL = baseURL }(parse.BaseURL)
+	parse.BaseURL = s.URL + "/"
+
+	client, _ := parse.NewClient("app", "key")
+	client.SetRetryPolicy(&parse.RetryPolicy{MaxRetries: 3, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var results []map[string]interface{}
+	err := client.WithContext(ctx).QueryClass("Post", nil, &results)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, want the deadline of the context", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second || requests != 1 {
+		t.Errorf("got %d requests in %v, want the retry to be canceled", requests, elapsed)
+	}
+}
+
+func TestParseLoginNotRetried(t *testing.T) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	defer func(baseURL string) { parse.BaseURL = baseURL }(parse.BaseURL)
+	parse.BaseURL = s.URL + "/"
+
+	client, _ := parse.NewClient("app", "key")
+	client.SetRetryPolicy(&parse.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	if err := client.LoginUser("ann", "pw", &parse.ParseUser{}); err == nil || requests != 1 {
+		t.Errorf("got %v after %d requests, want an error after one", err, requests)
+	}
+}
+
+func TestCanceledRequestStopsRetries(t *testing.T) {
+	client := newParse(t).Client()
+	// long enough to tell a canceled request from one retried to the end
+	client.SetRetryPolicy(&parse.RetryPolicy{MaxRetries: 3, BaseDelay: 2 * time.Second, MaxDelay: 2 * time.Second})
+	h := newHandler(t, client, nil, nil)
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		close(done)
+	}))
+	defer s.Close()
+
+	// Parse fails every request once the schema is loaded
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	parse.BaseURL = unavailable.URL + "/"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", s.URL, strings.NewReader(`{"query": "{ _Role { name } }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatalf("got %s, want the request to be canceled", resp.Status)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the request kept retrying after it was canceled")
+	}
+}
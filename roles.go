@@ -0,0 +1,130 @@
+package parse_graphql
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// maxRoles bounds the number of roles fetched by a single role query.
+const maxRoles = 1000
+
+// pointer returns a Parse Pointer to the object className/objectID.
+func pointer(className, objectID string) map[string]interface{} {
+	return map[string]interface{}{"__type": "Pointer", "className": className, "objectId": objectID}
+}
+
+func (p *ParseClass) myRoles(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	userID, _ := p.Data["objectId"].(string)
+	if userID == "" {
+		return nil, nil
+	}
+	roles, err := userRoles(ctx, tracedClient(ctx, requestClient(ctx, p.client)), userID)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := p.newClass("_Role")
+	if err != nil {
+		return nil, err
+	}
+	return pc.wrap(roles, nil)
+}
+
+// userRoles returns the roles the user userID belongs to, sorted by name: the roles
+// listing the user in their 'users' relation, then the roles listing any of those in
+// their 'roles' relation, and so on.
+func userRoles(ctx context.Context, client *parse.Client, userID string) ([]map[string]interface{}, error) {
+	var (
+		roles []map[string]interface{}
+		seen  = map[string]bool{}
+		where = map[string]interface{}{"users": pointer("_User", userID)}
+	)
+	for {
+		found, err := queryRoles(ctx, client, where)
+		if err != nil {
+			return nil, err
+		}
+		var inheriting []interface{}
+		for _, role := range found {
+			id, _ := role["objectId"].(string)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			roles = append(roles, role)
+			inheriting = append(inheriting, map[string]interface{}{"roles": pointer("_Role", id)})
+		}
+		if len(inheriting) == 0 {
+			break
+		}
+		where = map[string]interface{}{"$or": inheriting}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		a, _ := roles[i]["name"].(string)
+		b, _ := roles[j]["name"].(string)
+		return a < b
+	})
+	return roles, nil
+}
+
+func queryRoles(ctx context.Context, client *parse.Client, where map[string]interface{}) ([]map[string]interface{}, error) {
+	whereJSON, err := json.Marshal(where)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	var roles []map[string]interface{}
+	err = client.QueryClass("_Role", &parse.QueryOptions{Where: string(whereJSON), Limit: maxRoles}, &roles)
+	return roles, err
+}
+
+func (s *ParseSchema) grantRole(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	return s.changeRole(ctx, f, "AddRelation")
+}
+
+func (s *ParseSchema) revokeRole(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	return s.changeRole(ctx, f, "RemoveRelation")
+}
+
+// changeRole applies op to the users relation of the role named by the 'role' argument
+// of f, with the user of the 'userId' argument, and returns the role.
+func (s *ParseSchema) changeRole(ctx context.Context, f *graphql.Field, op string) (interface{}, error) {
+	var args [2]string
+	for i, arg := range []string{"role", "userId"} {
+		value, _ := f.Arguments.Get(arg)
+		if args[i], _ = value.(string); args[i] == "" {
+			return nil, argumentErrorf("'%s' must be a non-empty string.", arg)
+		}
+	}
+	roleName, userID := args[0], args[1]
+
+	c := tracedClient(ctx, s.authedClient(ctx))
+	roles, err := queryRoles(ctx, c, map[string]interface{}{"name": roleName})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, argumentErrorf("role '%s' does not exist.", roleName)
+	}
+	roleID, _ := roles[0]["objectId"].(string)
+	t, traced := tracer.FromContext(ctx)
+	if traced {
+		t.IncQueries(2)
+	}
+	users := map[string]interface{}{"__op": op, "objects": []interface{}{pointer("_User", userID)}}
+	if _, err := c.UpdateClass("_Role", roleID, map[string]interface{}{"users": users}); err != nil {
+		return nil, err
+	}
+	pc, err := s.newClass(s.client, "_Role")
+	if err != nil {
+		return nil, err
+	}
+	return pc, c.GetClass("_Role", roleID, &pc.Data)
+}
@@ -0,0 +1,239 @@
+package parse_graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// maxSessions is the number of sessions revokeAllOtherSessions queries at once, the
+// largest limit Parse accepts.
+const maxSessions = 1000
+
+// SessionCache caches the users of session tokens for the fields acting on the current
+// user, such as me, saving a /1/users/me request per GraphQL request. A session revoked
+// outside of logOut, revokeSession, revokeAllOtherSessions and deleteMe keeps working for
+// these fields until its entry expires; other fields always check it with Parse.
+type SessionCache struct {
+	// TTL is how long users are cached.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached users, if not zero.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]sessionCacheEntry
+}
+
+type sessionCacheEntry struct {
+	user    map[string]interface{}
+	expires time.Time
+}
+
+// NewSessionCache returns a SessionCache holding up to maxEntries users for ttl.
+func NewSessionCache(ttl time.Duration, maxEntries int) *SessionCache {
+	return &SessionCache{TTL: ttl, MaxEntries: maxEntries}
+}
+
+// get returns a copy of the cached user of token, if any. A nil cache is empty.
+func (c *SessionCache) get(token string) (map[string]interface{}, bool) {
+	if c == nil || token == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[token]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return copyMap(entry.user), true
+}
+
+// set caches user as the user of token, evicting expired entries, or an arbitrary one,
+// when the cache is full.
+func (c *SessionCache) set(token string, user map[string]interface{}) {
+	if c == nil || token == "" || c.TTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]sessionCacheEntry{}
+	}
+	now := time.Now()
+	if c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		for t, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, t)
+			}
+		}
+		for t := range c.entries {
+			if len(c.entries) < c.MaxEntries {
+				break
+			}
+			delete(c.entries, t)
+		}
+	}
+	c.entries[token] = sessionCacheEntry{user: copyMap(user), expires: now.Add(c.TTL)}
+}
+
+// invalidate forgets the user of token.
+func (c *SessionCache) invalidate(token string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, token)
+}
+
+// invalidateUser forgets every session of the user userID.
+func (c *SessionCache) invalidateUser(userID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for token, entry := range c.entries {
+		if entry.user["objectId"] == userID {
+			delete(c.entries, token)
+		}
+	}
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// currentUser fetches the user c is authed as, using s.SessionCache if set.
+func (s *ParseSchema) currentUser(ctx context.Context, c *parse.Client) (map[string]interface{}, error) {
+	token := sessionToken(ctx)
+	if user, ok := s.SessionCache.get(token); ok {
+		return user, nil
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	var user map[string]interface{}
+	if err := c.CurrentUser(&user); err != nil {
+		return nil, err
+	}
+	s.SessionCache.set(token, user)
+	return user, nil
+}
+
+func (s *ParseSchema) mySessions(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query, err := queryOptions(f.Arguments, nil)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	var sessions []map[string]interface{}
+	if err := c.QuerySessions(query, &sessions); err != nil {
+		return nil, err
+	}
+	pc, err := s.newClass(s.authedClient(ctx), "_Session")
+	if err != nil {
+		return nil, err
+	}
+	return pc.wrap(sessions, nil)
+}
+
+func (s *ParseSchema) revokeSession(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	value, _ := f.Arguments.Get("id")
+	id, ok := value.(string)
+	if !ok || id == "" {
+		return nil, argumentErrorf("'id' must be the objectId of a session.")
+	}
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.currentUser(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	if err := c.DeleteSession(id); err != nil {
+		return nil, err
+	}
+	userID, _ := user["objectId"].(string)
+	s.SessionCache.invalidateUser(userID)
+	return true, nil
+}
+
+// revokeAllOtherSessions revokes the sessions of the current user but the one of the
+// request, and returns the number of sessions revoked. Sessions are queried maxSessions
+// at a time until none is left; if revoking one fails, the error reports how many were
+// revoked before.
+func (s *ParseSchema) revokeAllOtherSessions(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, traced := tracer.FromContext(ctx)
+	if traced {
+		t.IncQueries(1)
+	}
+	var current map[string]interface{}
+	if err := c.CurrentSession(&current); err != nil {
+		return nil, err
+	}
+	if user, _ := current["user"].(map[string]interface{}); user != nil {
+		userID, _ := user["objectId"].(string)
+		defer s.SessionCache.invalidateUser(userID)
+	}
+	where, err := json.Marshal(map[string]interface{}{"objectId": map[string]interface{}{"$ne": current["objectId"]}})
+	if err != nil {
+		return nil, err
+	}
+	revoked := 0
+	for {
+		if traced {
+			t.IncQueries(1)
+		}
+		var sessions []map[string]interface{}
+		if err := c.QuerySessions(&parse.QueryOptions{Where: string(where), Limit: maxSessions}, &sessions); err != nil {
+			return nil, revokedErr(revoked, err)
+		}
+		for _, session := range sessions {
+			id, _ := session["objectId"].(string)
+			if traced {
+				t.IncQueries(1)
+			}
+			if err := c.DeleteSession(id); err != nil {
+				return nil, revokedErr(revoked, err)
+			}
+			revoked++
+		}
+		if len(sessions) < maxSessions {
+			return revoked, nil
+		}
+	}
+}
+
+// revokedErr wraps err, met after revoking revoked sessions, to report how many were.
+func revokedErr(revoked int, err error) error {
+	if revoked == 0 {
+		return err
+	}
+	return fmt.Errorf("%d other sessions revoked before failing: %w", revoked, err)
+}
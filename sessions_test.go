@@ -0,0 +1,164 @@
+package parse_graphql_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/parse_graphql"
+)
+
+// logIn logs in as username through e and returns the session token.
+func (e *endpoint) logIn(t *testing.T, username, password string) string {
+	t.Helper()
+	r := e.post(t, nil, `mutation l { logIn(username: "`+username+`", password: "`+password+`") { sessionToken } }`)
+	user, _ := r.field(t, 0).(map[string]interface{})
+	token, _ := user["sessionToken"].(string)
+	if token == "" {
+		t.Fatalf("logIn %s: got %v (error %v)", username, r.Data, r.Error)
+	}
+	return token
+}
+
+// me returns the username of the user of token, or the code of the error.
+func (e *endpoint) me(t *testing.T, token string) interface{} {
+	t.Helper()
+	r := e.post(t, map[string]string{"X-Parse-Session-Token": token}, `{ me { username } }`)
+	if r.Error != nil {
+		return r.Error.Extensions["code"]
+	}
+	me, _ := r.field(t, 0).(map[string]interface{})
+	return me["username"]
+}
+
+func TestSessions(t *testing.T) {
+	p := newParse(t)
+	_, token := p.AddUser("ann", "pw", nil)
+	p.AddUser("bob", "pw", nil)
+	cache := parse_graphql.NewSessionCache(time.Hour, 0)
+	e := newSchemaEndpoint(t, p, func(ps *parse_graphql.ParseSchema) { ps.SessionCache = cache })
+	phone, laptop := e.logIn(t, "ann", "pw"), e.logIn(t, "ann", "pw")
+	session := map[string]string{"X-Parse-Session-Token": token}
+
+	r := e.post(t, session, `{ mySessions(order: "createdAt") { objectId, sessionToken } }`)
+	sessions := r.objects(t, 0)
+	if len(sessions) != 3 || sessions[0]["sessionToken"] != token || sessions[2]["sessionToken"] != laptop {
+		t.Fatalf("mySessions: got %v (error %v), want the 3 sessions of ann", sessions, r.Error)
+	}
+	for _, token := range []string{token, phone, laptop} {
+		if got := e.me(t, token); got != "ann" {
+			t.Fatalf("me: got %v, want ann", got)
+		}
+	}
+
+	r = e.post(t, session, `mutation r { revokeSession(id: "`+sessions[1]["objectId"].(string)+`") }`)
+	if r.field(t, 0) != true {
+		t.Fatalf("revokeSession: got %v (error %v)", r.Data, r.Error)
+	}
+	// revoking a session invalidates the cached users of its user
+	if got := e.me(t, phone); got != "UNAUTHENTICATED" {
+		t.Errorf("me with the revoked session: got %v, want UNAUTHENTICATED", got)
+	}
+	if r := e.post(t, session, `mutation r { revokeSession(id: "") }`); r.Error == nil || r.Error.Extensions["code"] != "BAD_USER_INPUT" {
+		t.Errorf("revokeSession without an id: got %v, want BAD_USER_INPUT", r.Error)
+	}
+
+	e.me(t, laptop)
+	r = e.post(t, session, `mutation r { revokeAllOtherSessions }`)
+	if r.field(t, 0) != float64(1) {
+		t.Fatalf("revokeAllOtherSessions: got %v (error %v), want 1", r.Data, r.Error)
+	}
+	if got := e.me(t, laptop); got != "UNAUTHENTICATED" {
+		t.Errorf("me with a revoked session: got %v, want UNAUTHENTICATED", got)
+	}
+	if got := e.me(t, token); got != "ann" {
+		t.Errorf("me with the session of the request: got %v, want ann", got)
+	}
+	if r := e.post(t, nil, `{ mySessions { objectId } }`); r.Error == nil || r.Error.Extensions["code"] != "UNAUTHENTICATED" {
+		t.Errorf("mySessions without a session: got %v, want UNAUTHENTICATED", r.Error)
+	}
+}
+
+func TestRevokeAllOtherSessionsPages(t *testing.T) {
+	p := newParse(t)
+	userID, token := p.AddUser("ann", "pw", nil)
+	// more sessions than a single query returns
+	const n = 1005
+	for i := 0; i < n; i++ {
+		p.AddObject("_Session", map[string]interface{}{
+			"sessionToken": fmt.Sprintf("r:other%d", i),
+			"user":         map[string]interface{}{"__type": "Pointer", "className": "_User", "objectId": userID},
+			"ACL":          map[string]interface{}{userID: map[string]interface{}{"read": true, "write": true}},
+		})
+	}
+	e := newEndpoint(t, p, nil, nil)
+	r := e.post(t, map[string]string{"X-Parse-Session-Token": token}, `mutation r { revokeAllOtherSessions }`)
+	if r.field(t, 0) != float64(n) {
+		t.Fatalf("got %v (error %v), want %d", r.Data, r.Error, n)
+	}
+	if sessions := p.Objects("_Session"); len(sessions) != 1 || sessions[0]["sessionToken"] != token {
+		t.Errorf("%d sessions left, want the one of the request", len(sessions))
+	}
+}
+
+func TestRevokeAllOtherSessionsReportsRevoked(t *testing.T) {
+	p := newParse(t)
+	userID, token := p.AddUser("ann", "pw", nil)
+	e := newEndpoint(t, p, nil, nil)
+	e.logIn(t, "ann", "pw")
+	// a session ann can read but not delete
+	p.AddObject("_Session", map[string]interface{}{
+		"sessionToken": "r:readonly",
+		"user":         map[string]interface{}{"__type": "Pointer", "className": "_User", "objectId": userID},
+		"ACL":          map[string]interface{}{userID: map[string]interface{}{"read": true}},
+	})
+	r := e.post(t, map[string]string{"X-Parse-Session-Token": token}, `mutation r { revokeAllOtherSessions }`)
+	if r.Error == nil || !strings.Contains(r.Error.Message, "1 other sessions revoked") {
+		t.Errorf("got %v (error %v), want an error reporting 1 revoked session", r.Data, r.Error)
+	}
+}
+
+func TestSessionCache(t *testing.T) {
+	p := newParse(t)
+	_, ann := p.AddUser("ann", "pw", nil)
+	_, bob := p.AddUser("bob", "pw", nil)
+	cache := parse_graphql.NewSessionCache(time.Hour, 1)
+	e := newSchemaEndpoint(t, p, func(ps *parse_graphql.ParseSchema) { ps.SessionCache = cache })
+	logOut := func(token string) {
+		if err := p.Client().WithSessionToken(token).LogoutUser(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// sessions revoked outside of the schema keep working while cached
+	e.me(t, ann)
+	logOut(ann)
+	if got := e.me(t, ann); got != "ann" {
+		t.Errorf("cached session: got %v, want ann", got)
+	}
+	// caching bob evicts ann
+	e.me(t, bob)
+	if got := e.me(t, ann); got != "UNAUTHENTICATED" {
+		t.Errorf("evicted session: got %v, want UNAUTHENTICATED", got)
+	}
+	// logOut invalidates the session
+	if r := e.post(t, map[string]string{"X-Parse-Session-Token": bob}, `mutation l { logOut }`); r.field(t, 0) != true {
+		t.Fatalf("logOut: got %v (error %v)", r.Data, r.Error)
+	}
+	if got := e.me(t, bob); got != "UNAUTHENTICATED" {
+		t.Errorf("logged out session: got %v, want UNAUTHENTICATED", got)
+	}
+
+	// entries expire after the TTL
+	_, carl := p.AddUser("carl", "pw", nil)
+	e = newSchemaEndpoint(t, p, func(ps *parse_graphql.ParseSchema) {
+		ps.SessionCache = parse_graphql.NewSessionCache(10*time.Millisecond, 0)
+	})
+	e.me(t, carl)
+	logOut(carl)
+	time.Sleep(20 * time.Millisecond)
+	if got := e.me(t, carl); got != "UNAUTHENTICATED" {
+		t.Errorf("expired entry: got %v, want UNAUTHENTICATED", got)
+	}
+}
@@ -0,0 +1,165 @@
+package parse_graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// ChangeSource feeds subscriptions with objects as they are created or updated.
+type ChangeSource interface {
+	// Changes delivers objects of className matching where as they change. Values sent
+	// on the returned channel are either a map[string]interface{} holding an object or an
+	// error. The channel is closed once ctx is done.
+	Changes(ctx context.Context, className string, where map[string]interface{}) (<-chan interface{}, error)
+}
+
+func (p *ParseClass) changed(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	if err := p.authorizeQuery(ctx, f.Arguments); err != nil {
+		return nil, err
+	}
+	where, err := whereClause(f.Arguments, nil)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := p.Changes.Changes(ctx, p.class.ClassName, where)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for change := range changes {
+			if object, ok := change.(map[string]interface{}); ok {
+				pc, err := p.newClass(p.class.ClassName)
+				if err != nil {
+					change = err
+				} else {
+					pc.Data = object
+					change = pc
+				}
+			}
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var result <-chan interface{} = out
+	return result, nil
+}
+
+// pollBatchSize bounds the number of changed objects fetched per query; polls fetch
+// further batches until one comes back short.
+const pollBatchSize = 100
+
+// PollingChangeSource is a ChangeSource that periodically queries for objects updated
+// since the last one it has seen. Objects are paged through in updatedAt and objectId
+// order so that objects sharing an updatedAt are neither skipped nor repeated. It works
+// against any Parse app but adds at least one query per subscription every Interval.
+type PollingChangeSource struct {
+	Client   *parse.Client
+	Interval time.Duration
+}
+
+// pollCursor is the position of the last object seen by a poll.
+type pollCursor struct {
+	updatedAt string
+	objectID  string
+}
+
+func (s *PollingChangeSource) Changes(ctx context.Context, className string, where map[string]interface{}) (<-chan interface{}, error) {
+	client := s.Client
+	if token := sessionToken(ctx); token != "" {
+		client = client.WithSessionToken(token)
+	}
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		cursor := pollCursor{updatedAt: time.Now().UTC().Format(parseTimeFormat)}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		send := func(v interface{}) bool {
+			select {
+			case out <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for {
+				objects, err := s.poll(ctx, client, className, where, cursor)
+				if err != nil {
+					if !send(err) {
+						return
+					}
+					break
+				}
+				for _, object := range objects {
+					updatedAt, _ := object["updatedAt"].(string)
+					objectID, _ := object["objectId"].(string)
+					cursor = pollCursor{updatedAt: updatedAt, objectID: objectID}
+					if !send(object) {
+						return
+					}
+				}
+				if len(objects) < pollBatchSize {
+					break
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// poll fetches the objects of className matching where that come after cursor in
+// updatedAt and objectId order.
+func (s *PollingChangeSource) poll(ctx context.Context, client *parse.Client, className string, where map[string]interface{}, cursor pollCursor) ([]map[string]interface{}, error) {
+	since := map[string]interface{}{"__type": "Date", "iso": cursor.updatedAt}
+	after := map[string]interface{}{"updatedAt": map[string]interface{}{"$gte": since}}
+	if cursor.objectID != "" {
+		// objects updated at the same time as the cursor's come after it by objectId
+		after["$or"] = []interface{}{
+			map[string]interface{}{"updatedAt": map[string]interface{}{"$gt": since}},
+			map[string]interface{}{"objectId": map[string]interface{}{"$gt": cursor.objectID}},
+		}
+	}
+	clause := after
+	if len(where) > 0 {
+		clause = map[string]interface{}{"$and": []interface{}{where, after}}
+	}
+	whereJSON, err := json.Marshal(clause)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	var results []map[string]interface{}
+	err = tracedClient(ctx, client).QueryClass(className, &parse.QueryOptions{
+		Where: string(whereJSON),
+		Order: "updatedAt,objectId",
+		Limit: pollBatchSize,
+	}, &results)
+	if err != nil {
+		return nil, fmt.Errorf("polling %s for changes: %w", className, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,186 @@
+package parse_graphql_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/graphql/handler"
+	"github.com/tmc/graphql/websocket"
+	"github.com/tmc/parse"
+	"github.com/tmc/parse_graphql"
+	"golang.org/x/net/context"
+)
+
+// idleChanges is a ChangeSource that never delivers a change.
+type idleChanges struct{}
+
+func (idleChanges) Changes(ctx context.Context, className string, where map[string]interface{}) (<-chan interface{}, error) {
+	changes := make(chan interface{})
+	go func() {
+		<-ctx.Done()
+		close(changes)
+	}()
+	return changes, nil
+}
+
+// wsMessage is a graphql-ws protocol message.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// dialGraphQLWS opens a graphql-ws connection to e initialized with payload.
+func dialGraphQLWS(t *testing.T, e *endpoint, payload map[string]interface{}) *websocket.Conn {
+	t.Helper()
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(e.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	init, _ := json.Marshal(payload)
+	if err := conn.WriteJSON(wsMessage{Type: "connection_init", Payload: init}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readWS(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("got %s %s, want connection_ack", msg.Type, msg.Payload)
+	}
+	return conn
+}
+
+// startWS starts the operation id running query with variables on conn.
+func startWS(t *testing.T, conn *websocket.Conn, id, query string, variables map[string]interface{}) {
+	t.Helper()
+	payload, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err := conn.WriteJSON(wsMessage{ID: id, Type: "start", Payload: payload}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestWebSocketVariables(t *testing.T) {
+	p, _ := blog(t)
+	conn := dialGraphQLWS(t, newEndpoint(t, p, nil, nil), nil)
+	startWS(t, conn, "1", `query q($title: String) { Post(where: {title: $title}) { title } }`, map[string]interface{}{"title": "b"})
+	msg := readWS(t, conn)
+	var result struct {
+		Data [][]map[string]interface{} `json:"data"`
+	}
+	json.Unmarshal(msg.Payload, &result)
+	if msg.ID != "1" || msg.Type != "data" || len(result.Data) != 1 || len(result.Data[0]) != 1 || result.Data[0][0]["title"] != "b" {
+		t.Errorf("got %s %s %s, want post b", msg.ID, msg.Type, msg.Payload)
+	}
+}
+
+func TestWebSocketMaxOperations(t *testing.T) {
+	p, _ := blog(t)
+	e := newSubscriptionEndpoint(t, p, nil, idleChanges{}, func(h *handler.ExecutorHandler) {
+		h.MaxWebSocketOperations = 1
+	})
+	conn := dialGraphQLWS(t, e, nil)
+	startWS(t, conn, "1", `subscription s { PostChanged { title } }`, nil)
+	startWS(t, conn, "2", `subscription s { PostChanged { title } }`, nil)
+	if msg := readWS(t, conn); msg.ID != "2" || msg.Type != "error" {
+		t.Errorf("got %s %s %s, want an error for the second subscription", msg.ID, msg.Type, msg.Payload)
+	}
+
+	// stopped subscriptions make room for others
+	conn.WriteJSON(wsMessage{ID: "1", Type: "stop"})
+	readWS(t, conn) // the completion of the first subscription
+	startWS(t, conn, "3", `{ PostCount }`, nil)
+	if msg := readWS(t, conn); msg.ID != "3" || msg.Type != "data" {
+		t.Errorf("got %s %s %s, want the result of the third operation", msg.ID, msg.Type, msg.Payload)
+	}
+}
+
+func TestPollingChangeSourceSharedTimestamps(t *testing.T) {
+	p := newParse(t)
+	p.AddClass("Post", map[string]parse.SchemaField{"published": {Type: "Boolean"}})
+	// objects are updated after the subscription starts, all in the same millisecond
+	now := time.Now().Add(time.Second)
+	p.Now = func() time.Time { return now }
+	source := &parse_graphql.PollingChangeSource{Client: p.Client(), Interval: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := source.Changes(ctx, "Post", map[string]interface{}{"published": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		// the poll in progress ends before the server does
+		cancel()
+		for range changes {
+		}
+	}()
+
+	// more objects than a poll fetches at once
+	want := map[string]bool{}
+	for i := 0; i < 250; i++ {
+		want[p.AddObject("Post", map[string]interface{}{"published": true})] = true
+	}
+	p.AddObject("Post", map[string]interface{}{"published": false})
+
+	seen := map[string]bool{}
+	timeout := time.After(5 * time.Second)
+	for len(seen) < len(want) {
+		select {
+		case change := <-changes:
+			object, ok := change.(map[string]interface{})
+			if !ok {
+				t.Fatalf("got %v, want an object", change)
+			}
+			id, _ := object["objectId"].(string)
+			if !want[id] || seen[id] {
+				t.Fatalf("got %s, which is unexpected or repeated", id)
+			}
+			seen[id] = true
+		case <-timeout:
+			t.Fatalf("got %d of %d objects", len(seen), len(want))
+		}
+	}
+	select {
+	case change := <-changes:
+		t.Errorf("got %v after every object was seen", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// headerRecorder is an Authenticator recording the headers of the requests it authenticates.
+type headerRecorder struct {
+	client  *parse.Client
+	headers chan http.Header
+}
+
+func (a headerRecorder) Authenticate(ctx context.Context, r *http.Request) (context.Context, error) {
+	a.headers <- r.Header
+	identity := &parse_graphql.Identity{SessionToken: r.Header.Get("X-Parse-Session-Token")}
+	return parse_graphql.NewAuthContext(ctx, identity, a.client), nil
+}
+
+func TestWebSocketInitHeaders(t *testing.T) {
+	p, _ := blog(t)
+	recorder := headerRecorder{p.Client(), make(chan http.Header, 2)}
+	e := newEndpoint(t, p, nil, func(h *handler.ExecutorHandler) { h.Authenticator = recorder })
+	dialGraphQLWS(t, e, map[string]interface{}{
+		"X-Parse-Session-Token": "r:token",
+		"X-Forwarded-For":       "10.0.0.1",
+	})
+	<-recorder.headers // the upgrade request
+	header := <-recorder.headers
+	if header.Get("X-Parse-Session-Token") != "r:token" {
+		t.Errorf("got session token %q, want r:token", header.Get("X-Parse-Session-Token"))
+	}
+	if header.Get("X-Forwarded-For") != "" {
+		t.Errorf("got X-Forwarded-For %q from connection_init, want none", header.Get("X-Forwarded-For"))
+	}
+}
@@ -0,0 +1,150 @@
+package parse_graphql
+
+import (
+	"github.com/tmc/graphql"
+	"github.com/tmc/graphql/executor/resolver"
+	"github.com/tmc/graphql/executor/tracer"
+	"github.com/tmc/parse"
+	"golang.org/x/net/context"
+)
+
+// userArguments describes the arguments of the mutations setting the fields of users:
+// one per _User field that can be set.
+func (s *ParseSchema) userArguments() []graphql.Argument {
+	users, err := s.newClass(s.client, "_User")
+	if err != nil {
+		return []graphql.Argument{{Name: "username"}, {Name: "password"}, {Name: "email"}}
+	}
+	return users.inputArguments()
+}
+
+// userFields converts the arguments of a mutation into the fields of a _User object.
+func (s *ParseSchema) userFields(args graphql.Arguments, update bool) (map[string]interface{}, error) {
+	users, err := s.newClass(s.client, "_User")
+	if err != nil {
+		return nil, err
+	}
+	return users.inputFields(args, update)
+}
+
+// sessionClient returns the client of s authed as the user of the session token of the
+// http request in ctx, or parse.ErrUnauthorized if the request has none.
+func (s *ParseSchema) sessionClient(ctx context.Context) (*parse.Client, error) {
+	if sessionToken(ctx) == "" {
+		return nil, parse.ErrUnauthorized
+	}
+	return tracedClient(ctx, s.authedClient(ctx)), nil
+}
+
+func (s *ParseSchema) logOut(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	s.SessionCache.invalidate(sessionToken(ctx))
+	return true, c.LogoutUser()
+}
+
+func (s *ParseSchema) updateMe(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	fields, err := s.userFields(f.Arguments, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateCurrentUser(ctx, fields)
+}
+
+// updateCurrentUser applies fields to the current user, and returns the user.
+func (s *ParseSchema) updateCurrentUser(ctx context.Context, fields map[string]interface{}) (*ParseClass, error) {
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.currentUser(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := user["objectId"].(string)
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	if _, err := c.UpdateClass("_User", userID, fields); err != nil {
+		return nil, err
+	}
+	s.SessionCache.invalidateUser(userID)
+	pc, err := s.newClass(s.authedClient(ctx), "_User")
+	if err != nil {
+		return nil, err
+	}
+	pc.Data, err = s.currentUser(ctx, c)
+	return pc, err
+}
+
+func (s *ParseSchema) deleteMe(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.currentUser(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := user["objectId"].(string)
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	s.SessionCache.invalidateUser(userID)
+	return true, c.DeleteUser(parse.ParseUser{ParseObject: parse.ParseObject{ID: userID}})
+}
+
+func (s *ParseSchema) requestPasswordReset(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	email, err := s.emailArgument(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return true, tracedClient(ctx, s.client).PasswordResetRequest(email)
+}
+
+func (s *ParseSchema) requestEmailVerification(ctx context.Context, r resolver.Resolver, f *graphql.Field) (interface{}, error) {
+	email, err := s.emailArgument(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tracer.FromContext(ctx); ok {
+		t.IncQueries(1)
+	}
+	return true, tracedClient(ctx, s.client).VerificationEmailRequest(email)
+}
+
+// emailArgument returns the 'email' argument of f or, without one, the email address of
+// the current user.
+func (s *ParseSchema) emailArgument(ctx context.Context, f *graphql.Field) (string, error) {
+	if value, ok := f.Arguments.Get("email"); ok {
+		email, ok := value.(string)
+		if !ok || email == "" {
+			return "", argumentErrorf("'email' must be a non-empty string.")
+		}
+		return email, nil
+	}
+	if sessionToken(ctx) == "" {
+		return "", argumentErrorf("'email' is required when not logged in.")
+	}
+	c, err := s.sessionClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	user, err := s.currentUser(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	email, _ := user["email"].(string)
+	if email == "" {
+		return "", argumentErrorf("the current user has no email address.")
+	}
+	return email, nil
+}